@@ -2,48 +2,80 @@ package main
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"log/slog"
 	"os"
 	"os/signal"
+	"strconv"
+	"strings"
 	"syscall"
+	"time"
 
+	"replication-service/internal/adapters/audit"
 	"replication-service/internal/adapters/bucardo"
 	"replication-service/internal/adapters/config"
+	"replication-service/internal/adapters/grpcserver"
 	logadapter "replication-service/internal/adapters/logger"
+	"replication-service/internal/adapters/notify"
 	"replication-service/internal/adapters/postgres"
 	"replication-service/internal/adapters/server"
+	"replication-service/internal/adapters/tracing"
 	"replication-service/internal/core/services/orchestrator"
 )
 
 const (
 	bucardoLogPath    = "/var/log/bucardo/log.bucardo"
+	bucardoLogOffset  = "/var/log/bucardo/log.bucardo.offset"
+	bucardoPidPath    = "/var/run/bucardo/bucardo.mcp.pid"
+	auditLogPath      = "/var/log/bucardo/audit.jsonl"
 	bucardoConfigPath = "/media/bucardo/bucardo.json"
 	pgpassPath        = "/var/lib/postgresql/.pgpass"
 	bucardoUser       = "postgres"
 	bucardoCmd        = "bucardo"
+	psqlCmd           = "psql"
 	httpPort          = 8080
+	grpcPort          = 9090
+	logHistorySize    = 5000
+	serviceName       = "bucardo-replication"
 )
 
 func main() {
 	// 1. Setup Log Broadcaster and Multi-Writer
-	logBroadcaster := server.NewLogBroadcaster()
+	logBroadcaster := server.NewLogBroadcaster(logHistorySize, os.Getenv("BUCARDO_LOG_SPOOL"), os.Getenv("LOG_SLOW_CLIENT_POLICY"))
 	go logBroadcaster.Start()
 
-	// Logs go to stdout AND the websocket broadcaster
-	multiWriter := logadapter.NewMultiWriter(os.Stdout, logBroadcaster)
-
-	// 2. Setup global logger using the multi-writer
-	slogger := slog.New(slog.NewJSONHandler(multiWriter, &slog.HandlerOptions{
-		Level: slog.LevelInfo,
-	}))
+	// 2. Setup global logger. Logs go to stdout AND the websocket broadcaster.
+	// Secrets are scrubbed from every line; the registry learns passwords as they are resolved.
+	redactor := logadapter.NewRedactor()
+	redactor.AddSecret(envSecrets()...)
+	slogger, logLevels, err := newLogger(logBroadcaster, redactor)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Invalid logging settings:", err)
+		os.Exit(orchestrator.ExitCodeError)
+	}
 	slog.SetDefault(slogger)
 	logger := logadapter.NewSlogAdapter(slogger)
 
+	// Spans are exported when an OTLP endpoint is configured (OTEL_EXPORTER_OTLP_ENDPOINT).
+	shutdownTracing, tracingEnabled, err := tracing.Setup(context.Background(), serviceName)
+	if err != nil {
+		slogger.Error("Failed to set up tracing", "error", err)
+		os.Exit(orchestrator.ExitCodeError)
+	}
+	if tracingEnabled {
+		slogger.Info("Exporting traces over OTLP")
+	}
+
 	// 3. Instantiate adapters (the concrete implementations)
-	configProvider := config.NewJSONProvider(bucardoConfigPath)
+	auditLog := audit.NewJSONLLog(logger, getEnv("BUCARDO_AUDIT_LOG", auditLogPath))
+	configProvider := config.NewJSONProvider(bucardoConfigPath, os.Getenv("BUCARDO_CONFIG_HISTORY_DIR"))
 	credentialManager := postgres.NewPgpassManager(logger, pgpassPath, bucardoUser)
-	bucardoExecutor := bucardo.NewCLIExecutor(logger, bucardoUser, bucardoCmd)
-	monitor := bucardo.NewMonitorAdapter(logger, bucardoLogPath, bucardoUser, bucardoCmd)
+	bucardoExecutor := bucardo.NewCLIExecutor(logger, auditLog, redactor, bucardoUser, bucardoCmd, bucardoPidPath)
+	dispatcher := notify.NewDispatcher(logger, configProvider)
+	notifier := notify.NewFanout(dispatcher, logBroadcaster)
+	monitor := bucardo.NewMonitorAdapter(logger, notifier, auditLog, redactor, bucardoLogPath, bucardoLogOffset, bucardoUser, bucardoCmd)
+	inspector := postgres.NewPsqlInspector(logger, psqlCmd)
 
 	// 4. Instantiate the core service
 	appService := orchestrator.NewService(
@@ -52,6 +84,11 @@ func main() {
 		credentialManager,
 		bucardoExecutor,
 		monitor,
+		inspector,
+		notifier,
+		tracing.NewOTelTracer(),
+		logLevels,
+		redactor,
 		bucardoConfigPath,
 		pgpassPath,
 		bucardoUser,
@@ -60,9 +97,22 @@ func main() {
 	)
 
 	// 5. Instantiate and start HTTP server
-	httpServer := server.NewHTTPServer(logger, appService, logBroadcaster, httpPort)
+	apiToken := os.Getenv("API_TOKEN")
+	httpServer := server.NewHTTPServer(logger, appService, logBroadcaster, auditLog, apiToken, httpPort)
 	go httpServer.Start()
 
+	// The gRPC server runs on its own port; GRPC_PORT=0 disables it.
+	var grpcServer *grpcserver.GRPCServer
+	port, err := strconv.Atoi(getEnv("GRPC_PORT", strconv.Itoa(grpcPort)))
+	if err != nil {
+		slogger.Error("Invalid GRPC_PORT", "value", os.Getenv("GRPC_PORT"), "error", err)
+		os.Exit(orchestrator.ExitCodeError)
+	}
+	if port > 0 {
+		grpcServer = grpcserver.NewGRPCServer(logger, appService, logBroadcaster, auditLog, apiToken, port)
+		go grpcServer.Start()
+	}
+
 	// 6. Setup graceful shutdown
 	ctx, cancel := context.WithCancel(context.Background())
 	sigChan := make(chan os.Signal, 1)
@@ -74,14 +124,103 @@ func main() {
 		if err := httpServer.Stop(context.Background()); err != nil {
 			slogger.Error("Failed to shutdown HTTP server gracefully", "error", err)
 		}
+		if grpcServer != nil {
+			stopCtx, stopCancel := context.WithTimeout(context.Background(), 10*time.Second)
+			if err := grpcServer.Stop(stopCtx); err != nil {
+				slogger.Error("Failed to shutdown gRPC server gracefully", "error", err)
+			}
+			stopCancel()
+		}
 		cancel()
 	}()
 
 	// 7. Run the application
-	if err := appService.Run(ctx); err != nil {
-		slogger.Error("Application exited with an error", "error", err)
-		os.Exit(1)
+	runErr := appService.Run(ctx)
+
+	// Give pending notifications a chance to go out before the process exits.
+	flushCtx, flushCancel := context.WithTimeout(context.Background(), 30*time.Second)
+	if err := dispatcher.Close(flushCtx); err != nil {
+		slogger.Warn("Some notifications were not delivered before shutdown", "error", err)
+	}
+	flushCancel()
+
+	flushCtx, flushCancel = context.WithTimeout(context.Background(), 10*time.Second)
+	if err := shutdownTracing(flushCtx); err != nil {
+		slogger.Warn("Some spans were not exported before shutdown", "error", err)
+	}
+	flushCancel()
+
+	if runErr != nil {
+		slogger.Error("Application exited with an error", "error", runErr)
+		var exitErr *orchestrator.ExitError
+		if errors.As(runErr, &exitErr) {
+			os.Exit(exitErr.Code)
+		}
+		os.Exit(orchestrator.ExitCodeError)
 	}
 
 	slogger.Info("Application finished successfully.")
 }
+
+// newLogger builds the service logger. LOG_LEVEL and LOG_COMPONENT_LEVELS
+// ("db_reconciler=debug,bucardo_log=warn") set the initial levels, which can be changed at
+// runtime. LOG_FORMAT selects "json" (default) or "text" lines on stdout; the broadcaster
+// always receives JSON, which its filters rely on.
+func newLogger(broadcaster *server.LogBroadcaster, redactor *logadapter.Redactor) (*slog.Logger, *logadapter.LevelController, error) {
+	level, err := logadapter.ParseLevel(getEnv("LOG_LEVEL", "info"))
+	if err != nil {
+		return nil, nil, fmt.Errorf("LOG_LEVEL: %w", err)
+	}
+	componentLevels, err := logadapter.ParseComponentLevels(os.Getenv("LOG_COMPONENT_LEVELS"))
+	if err != nil {
+		return nil, nil, fmt.Errorf("LOG_COMPONENT_LEVELS: %w", err)
+	}
+	levels := logadapter.NewLevelController(level)
+	if err := levels.SetComponentLevels(componentLevels); err != nil {
+		return nil, nil, fmt.Errorf("LOG_COMPONENT_LEVELS: %w", err)
+	}
+
+	// The level controller filters; the handlers below accept everything it lets through.
+	options := &slog.HandlerOptions{Level: slog.LevelDebug}
+	var handler slog.Handler
+	switch format := getEnv("LOG_FORMAT", "json"); format {
+	case "json":
+		handler = slog.NewJSONHandler(logadapter.NewMultiWriter(os.Stdout, broadcaster), options)
+	case "text":
+		handler = logadapter.NewTeeHandler(
+			slog.NewTextHandler(os.Stdout, options),
+			slog.NewJSONHandler(broadcaster, options),
+		)
+	default:
+		return nil, nil, fmt.Errorf("LOG_FORMAT: invalid format %q, expected json or text", format)
+	}
+	return slog.New(levels.Handler(redactor.Handler(logadapter.NewTraceHandler(handler)))), levels, nil
+}
+
+// envSecrets returns the secrets passed in the environment: the API token, the Bucardo
+// database password, database passwords (BUCARDO_DB<ID> and BUCARDO_DB_<NAME>_PASS) and
+// notification secrets (BUCARDO_NOTIFY_<NAME>).
+func envSecrets() []string {
+	var secrets []string
+	for _, entry := range os.Environ() {
+		key, value, _ := strings.Cut(entry, "=")
+		switch {
+		case key == "API_TOKEN", key == "BUCARDO_DB_PASS", strings.HasPrefix(key, "BUCARDO_NOTIFY_"):
+			secrets = append(secrets, value)
+		case strings.HasPrefix(key, "BUCARDO_DB_") && strings.HasSuffix(key, "_PASS"):
+			secrets = append(secrets, value)
+		case strings.HasPrefix(key, "BUCARDO_DB"):
+			if _, err := strconv.Atoi(strings.TrimPrefix(key, "BUCARDO_DB")); err == nil {
+				secrets = append(secrets, value)
+			}
+		}
+	}
+	return secrets
+}
+
+func getEnv(key, fallback string) string {
+	if value, ok := os.LookupEnv(key); ok {
+		return value
+	}
+	return fallback
+}
@@ -0,0 +1,150 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"replication-service/internal/core/domain"
+)
+
+// apiError is an error response of the management API.
+type apiError struct {
+	Status  int
+	Message string
+}
+
+func (e *apiError) Error() string {
+	return fmt.Sprintf("%s (HTTP %d)", e.Message, e.Status)
+}
+
+// isStatus reports whether err is an API error with the given status.
+func isStatus(err error, status int) bool {
+	var apiErr *apiError
+	return errors.As(err, &apiErr) && apiErr.Status == status
+}
+
+// requestTimeout bounds API calls other than streams.
+const requestTimeout = 60 * time.Second
+
+// client calls the management API.
+type client struct {
+	server  string
+	token   string
+	actor   string
+	message string
+}
+
+func newClient(opts *options) *client {
+	return &client{
+		server:  strings.TrimRight(opts.server, "/"),
+		token:   opts.token,
+		actor:   opts.actor,
+		message: opts.message,
+	}
+}
+
+// request describes an API call. A non-empty ifMatch is sent as the configuration
+// revision the change is based on.
+type request struct {
+	method  string
+	path    string
+	query   url.Values
+	body    any
+	ifMatch string
+}
+
+// do sends the request and decodes a JSON response into out, if out is not nil. It returns
+// the response headers. Error responses are returned as *apiError.
+func (c *client) do(req request, out any) (http.Header, error) {
+	resp, err := c.send(req, &http.Client{Timeout: requestTimeout})
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		return resp.Header, readAPIError(resp)
+	}
+	if out != nil {
+		if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+			return resp.Header, fmt.Errorf("failed to decode response of %s %s: %w", req.method, req.path, err)
+		}
+	}
+	return resp.Header, nil
+}
+
+// stream sends the request and returns the open response for the caller to read. The
+// caller must close the body.
+func (c *client) stream(req request) (*http.Response, error) {
+	resp, err := c.send(req, &http.Client{})
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode >= 300 {
+		defer resp.Body.Close()
+		return nil, readAPIError(resp)
+	}
+	return resp, nil
+}
+
+func (c *client) send(req request, httpClient *http.Client) (*http.Response, error) {
+	target := c.server + req.path
+	if len(req.query) > 0 {
+		target += "?" + req.query.Encode()
+	}
+
+	var body io.Reader
+	if req.body != nil {
+		data, err := json.Marshal(req.body)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal request body: %w", err)
+		}
+		body = bytes.NewReader(data)
+	}
+
+	httpReq, err := http.NewRequest(req.method, target, body)
+	if err != nil {
+		return nil, fmt.Errorf("invalid request %s %s: %w", req.method, target, err)
+	}
+	if req.body != nil {
+		httpReq.Header.Set("Content-Type", "application/json")
+	}
+	if c.token != "" {
+		httpReq.Header.Set("Authorization", "Bearer "+c.token)
+	}
+	if c.actor != "" {
+		httpReq.Header.Set("X-Actor", c.actor)
+	}
+	if c.message != "" && req.method != http.MethodGet {
+		httpReq.Header.Set("X-Change-Message", c.message)
+	}
+	if req.ifMatch != "" {
+		httpReq.Header.Set("If-Match", req.ifMatch)
+	}
+
+	resp, err := httpClient.Do(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("%s %s failed: %w", req.method, target, err)
+	}
+	return resp, nil
+}
+
+func readAPIError(resp *http.Response) error {
+	data, _ := io.ReadAll(io.LimitReader(resp.Body, 64<<10))
+	var body domain.ErrorResponse
+	if err := json.Unmarshal(data, &body); err == nil && body.Error != "" {
+		return &apiError{Status: resp.StatusCode, Message: body.Error}
+	}
+	message := strings.TrimSpace(string(data))
+	if message == "" {
+		message = http.StatusText(resp.StatusCode)
+	}
+	return &apiError{Status: resp.StatusCode, Message: message}
+}
@@ -0,0 +1,359 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+
+	"replication-service/internal/core/domain"
+)
+
+func runSyncs(c *ctl, args []string) error {
+	if len(args) == 0 {
+		return errors.New("missing syncs command: list, get, create, edit or delete")
+	}
+	switch args[0] {
+	case "list":
+		return syncsList(c, args[1:])
+	case "get":
+		return syncsGet(c, args[1:])
+	case "create":
+		return syncsCreate(c, args[1:])
+	case "edit":
+		return syncsEdit(c, args[1:])
+	case "delete":
+		return syncsDelete(c, args[1:])
+	}
+	return fmt.Errorf("unknown syncs command %q", args[0])
+}
+
+func syncsList(c *ctl, args []string) error {
+	if _, err := c.parse(c.flags("syncs list"), args); err != nil {
+		return err
+	}
+	var syncs []domain.Sync
+	if _, err := c.client().do(request{method: http.MethodGet, path: "/syncs"}, &syncs); err != nil {
+		return err
+	}
+	return c.render(syncs, func(w io.Writer) { syncTable(w, syncs) })
+}
+
+func syncsGet(c *ctl, args []string) error {
+	name, err := c.parseName(c.flags("syncs get"), args)
+	if err != nil {
+		return err
+	}
+	var sync domain.Sync
+	if _, err := c.client().do(request{method: http.MethodGet, path: syncPath(name)}, &sync); err != nil {
+		return err
+	}
+	return c.render(sync, func(w io.Writer) { syncTable(w, []domain.Sync{sync}) })
+}
+
+func syncsCreate(c *ctl, args []string) error {
+	fs := c.flags("syncs create")
+	file := fs.String("f", "", `JSON file with the sync ("-" reads stdin)`)
+	if _, err := c.parse(fs, args); err != nil {
+		return err
+	}
+	var sync domain.Sync
+	if err := readJSONFile(*file, &sync); err != nil {
+		return err
+	}
+	var resp domain.MessageResponse
+	if _, err := c.client().do(request{method: http.MethodPost, path: "/syncs", body: sync}, &resp); err != nil {
+		return err
+	}
+	return c.printMessage(resp)
+}
+
+// syncsEdit opens a sync in $EDITOR and submits the result with the configuration
+// revision it was read at, so a change someone else made meanwhile is not overwritten.
+func syncsEdit(c *ctl, args []string) error {
+	name, err := c.parseName(c.flags("syncs edit"), args)
+	if err != nil {
+		return err
+	}
+	api := c.client()
+	var sync domain.Sync
+	header, err := api.do(request{method: http.MethodGet, path: syncPath(name)}, &sync)
+	if err != nil {
+		return err
+	}
+	original, err := json.MarshalIndent(sync, "", "  ")
+	if err != nil {
+		return err
+	}
+	original = append(original, '\n')
+
+	file, err := os.CreateTemp("", "bucardoctl-"+name+"-*.json")
+	if err != nil {
+		return fmt.Errorf("failed to create temporary file: %w", err)
+	}
+	path := file.Name()
+	_, err = file.Write(original)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(path)
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+
+	if err := runEditor(path); err != nil {
+		os.Remove(path)
+		return err
+	}
+	edited, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	if bytes.Equal(edited, original) {
+		os.Remove(path)
+		fmt.Fprintln(c.stderr, "No changes made")
+		return nil
+	}
+	var updated domain.Sync
+	if err := json.Unmarshal(edited, &updated); err != nil {
+		return fmt.Errorf("invalid sync, your version is kept in %s: %w", path, err)
+	}
+
+	var resp domain.MessageResponse
+	_, err = api.do(request{method: http.MethodPut, path: syncPath(name), body: updated, ifMatch: header.Get("ETag")}, &resp)
+	if isStatus(err, http.StatusPreconditionFailed) {
+		return fmt.Errorf("the configuration was changed by someone else while you were editing sync %q; "+
+			"your version is kept in %s, run edit again to start from the current configuration", name, path)
+	}
+	if err != nil {
+		return fmt.Errorf("%w; your version is kept in %s", err, path)
+	}
+	os.Remove(path)
+	return c.printMessage(resp)
+}
+
+// runEditor opens path in $VISUAL or $EDITOR, which may include arguments, or vi.
+func runEditor(path string) error {
+	editor := os.Getenv("VISUAL")
+	if editor == "" {
+		editor = os.Getenv("EDITOR")
+	}
+	if editor == "" {
+		editor = "vi"
+	}
+	fields := strings.Fields(editor)
+	cmd := exec.Command(fields[0], append(fields[1:], path)...)
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = os.Stdin, os.Stdout, os.Stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("editor %s failed: %w", editor, err)
+	}
+	return nil
+}
+
+func syncsDelete(c *ctl, args []string) error {
+	name, err := c.parseName(c.flags("syncs delete"), args)
+	if err != nil {
+		return err
+	}
+	var resp domain.MessageResponse
+	if _, err := c.client().do(request{method: http.MethodDelete, path: syncPath(name)}, &resp); err != nil {
+		return err
+	}
+	return c.printMessage(resp)
+}
+
+func runConfig(c *ctl, args []string) error {
+	if len(args) == 0 {
+		return errors.New("missing config command: get or apply")
+	}
+	switch args[0] {
+	case "get":
+		if _, err := c.parse(c.flags("config get"), args[1:]); err != nil {
+			return err
+		}
+		var config json.RawMessage
+		if _, err := c.client().do(request{method: http.MethodGet, path: "/config"}, &config); err != nil {
+			return err
+		}
+		// The configuration has no useful table form.
+		return printJSON(c.stdout, config)
+	case "apply":
+		fs := c.flags("config apply")
+		file := fs.String("f", "", `JSON file with the configuration ("-" reads stdin)`)
+		revision := fs.Int("revision", 0, "fail if the configuration changed since this revision")
+		if _, err := c.parse(fs, args[1:]); err != nil {
+			return err
+		}
+		var config domain.BucardoConfig
+		if err := readJSONFile(*file, &config); err != nil {
+			return err
+		}
+		req := request{method: http.MethodPost, path: "/config", body: config}
+		if *revision > 0 {
+			req.ifMatch = fmt.Sprintf(`"%d"`, *revision)
+		}
+		var resp domain.MessageResponse
+		if _, err := c.client().do(req, &resp); err != nil {
+			return err
+		}
+		return c.printMessage(resp)
+	}
+	return fmt.Errorf("unknown config command %q", args[0])
+}
+
+func runStart(c *ctl, args []string) error {
+	return c.action("start", "/start", args)
+}
+
+func runStop(c *ctl, args []string) error {
+	return c.action("stop", "/stop", args)
+}
+
+// action posts to an endpoint that answers with a message.
+func (c *ctl) action(name, path string, args []string) error {
+	if _, err := c.parse(c.flags(name), args); err != nil {
+		return err
+	}
+	var resp domain.MessageResponse
+	if _, err := c.client().do(request{method: http.MethodPost, path: path}, &resp); err != nil {
+		return err
+	}
+	return c.printMessage(resp)
+}
+
+func runRestart(c *ctl, args []string) error {
+	fs := c.flags("restart")
+	wait := fs.Bool("wait", false, "wait for the reconcile job to finish")
+	if _, err := c.parse(fs, args); err != nil {
+		return err
+	}
+	api := c.client()
+	var job domain.Job
+	if _, err := api.do(request{method: http.MethodPost, path: "/restart"}, &job); err != nil {
+		return err
+	}
+	if *wait {
+		for !jobFinished(job) {
+			time.Sleep(time.Second)
+			if _, err := api.do(request{method: http.MethodGet, path: "/jobs/" + url.PathEscape(job.ID)}, &job); err != nil {
+				return err
+			}
+		}
+	}
+	if err := c.render(job, func(w io.Writer) { jobTable(w, job) }); err != nil {
+		return err
+	}
+	if job.State == domain.JobStateFailed || job.State == domain.JobStateCancelled {
+		return fmt.Errorf("job %s %s", job.ID, job.State)
+	}
+	return nil
+}
+
+func jobFinished(job domain.Job) bool {
+	return job.State == domain.JobStateSucceeded || job.State == domain.JobStateFailed || job.State == domain.JobStateCancelled
+}
+
+// status is the json form of the status command.
+type status struct {
+	Syncs         []domain.Sync         `json:"syncs"`
+	LastReconcile *domain.Job           `json:"last_reconcile"`
+	Deltas        []domain.DeltaBacklog `json:"deltas"`
+}
+
+func runStatus(c *ctl, args []string) error {
+	if _, err := c.parse(c.flags("status"), args); err != nil {
+		return err
+	}
+	api := c.client()
+	var st status
+	if _, err := api.do(request{method: http.MethodGet, path: "/syncs"}, &st.Syncs); err != nil {
+		return err
+	}
+	var jobs []domain.Job
+	query := url.Values{"type": {domain.JobTypeReconcile}}
+	if _, err := api.do(request{method: http.MethodGet, path: "/jobs", query: query}, &jobs); err != nil {
+		return err
+	}
+	if len(jobs) > 0 {
+		st.LastReconcile = &jobs[0]
+	}
+	if _, err := api.do(request{method: http.MethodGet, path: "/deltas"}, &st.Deltas); err != nil {
+		return err
+	}
+
+	return c.render(st, func(w io.Writer) {
+		syncTable(w, st.Syncs)
+		fmt.Fprintln(w)
+		if st.LastReconcile == nil {
+			fmt.Fprintln(w, "LAST RECONCILE\tnone")
+		} else {
+			job := st.LastReconcile
+			line := job.State + ", started " + job.CreatedAt.Local().Format(time.DateTime)
+			if job.Error != "" {
+				line += ": " + job.Error
+			}
+			fmt.Fprintf(w, "LAST RECONCILE\t%s\n", line)
+		}
+		fmt.Fprintln(w)
+		fmt.Fprintln(w, "DATABASE\tTABLES\tPENDING ROWS\tOLDEST PENDING\tMEASURED")
+		for _, backlog := range st.Deltas {
+			var pending int64
+			var oldest float64
+			for _, table := range backlog.Tables {
+				pending += table.PendingRows
+				oldest = max(oldest, table.OldestPendingAgeSeconds)
+			}
+			measured := backlog.CollectedAt.Local().Format(time.DateTime)
+			if backlog.Error != "" {
+				measured = "error: " + backlog.Error
+			}
+			age := (time.Duration(oldest) * time.Second).String()
+			fmt.Fprintf(w, "%s\t%d\t%d\t%s\t%s\n", backlog.Database, len(backlog.Tables), pending, age, measured)
+		}
+	})
+}
+
+// parseName parses a command that takes exactly one name argument.
+func (c *ctl) parseName(fs *flag.FlagSet, args []string) (string, error) {
+	positional, err := c.parse(fs, args)
+	if err != nil {
+		return "", err
+	}
+	if len(positional) != 1 {
+		return "", fmt.Errorf("%s takes exactly one sync name", strings.TrimPrefix(fs.Name(), "bucardoctl "))
+	}
+	return positional[0], nil
+}
+
+func syncPath(name string) string {
+	return "/syncs/" + url.PathEscape(name)
+}
+
+// readJSONFile decodes a JSON file, or stdin when path is "-".
+func readJSONFile(path string, v any) error {
+	var data []byte
+	var err error
+	switch path {
+	case "":
+		return errors.New("missing -f file")
+	case "-":
+		data, err = io.ReadAll(os.Stdin)
+	default:
+		data, err = os.ReadFile(path)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		return fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	return nil
+}
@@ -0,0 +1,235 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"replication-service/internal/core/domain"
+)
+
+// apiStub is a management API that serves one sync at a configuration revision and
+// records the updates it receives.
+type apiStub struct {
+	mu       sync.Mutex
+	sync     domain.Sync
+	revision string // The ETag of the configuration, e.g. `"7"`.
+	conflict bool   // Answer every update with 412 Precondition Failed.
+	ifMatch  []string
+	updates  []domain.Sync
+	headers  http.Header // Of the last request.
+}
+
+func (a *apiStub) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.headers = r.Header.Clone()
+	reply := func(status int, v any) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		json.NewEncoder(w).Encode(v)
+	}
+	switch {
+	case r.Method == http.MethodGet && r.URL.Path == "/syncs":
+		reply(http.StatusOK, []domain.Sync{a.sync})
+	case r.Method == http.MethodGet && r.URL.Path == "/syncs/"+a.sync.Name:
+		w.Header().Set("ETag", a.revision)
+		reply(http.StatusOK, a.sync)
+	case r.Method == http.MethodPut && r.URL.Path == "/syncs/"+a.sync.Name:
+		a.ifMatch = append(a.ifMatch, r.Header.Get("If-Match"))
+		if a.conflict || r.Header.Get("If-Match") != a.revision {
+			reply(http.StatusPreconditionFailed, domain.ErrorResponse{Error: "configuration has changed since revision 7", Status: http.StatusPreconditionFailed})
+			return
+		}
+		var updated domain.Sync
+		if err := json.NewDecoder(r.Body).Decode(&updated); err != nil {
+			reply(http.StatusBadRequest, domain.ErrorResponse{Error: err.Error(), Status: http.StatusBadRequest})
+			return
+		}
+		a.updates = append(a.updates, updated)
+		reply(http.StatusOK, domain.MessageResponse{Message: "Sync " + a.sync.Name + " updated"})
+	default:
+		reply(http.StatusNotFound, domain.ErrorResponse{Error: "not found", Status: http.StatusNotFound})
+	}
+}
+
+// newTestCtl returns a ctl for the API at server that writes to the returned buffers.
+func newTestCtl(server string) (*ctl, *bytes.Buffer, *bytes.Buffer) {
+	var stdout, stderr bytes.Buffer
+	opts := &options{server: server, token: "t0ken", output: "table", actor: "alice@host", message: "Add items"}
+	return &ctl{opts: opts, stdout: &stdout, stderr: &stderr}, &stdout, &stderr
+}
+
+// useEditor makes $EDITOR a script that runs sed with expr on the file, and creates
+// temporary files in a directory of the test. It returns that directory.
+func useEditor(t *testing.T, expr string) string {
+	t.Helper()
+	dir := t.TempDir()
+	script := filepath.Join(dir, "editor.sh")
+	content := "#!/bin/sh\nsed '" + expr + "' \"$1\" > \"$1.new\" && mv \"$1.new\" \"$1\"\n"
+	if err := os.WriteFile(script, []byte(content), 0755); err != nil {
+		t.Fatal(err)
+	}
+	tmp := filepath.Join(dir, "tmp")
+	if err := os.Mkdir(tmp, 0755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("VISUAL", "")
+	t.Setenv("EDITOR", "sh "+script)
+	t.Setenv("TMPDIR", tmp)
+	return tmp
+}
+
+// kept returns the temporary files left in dir.
+func kept(t *testing.T, dir string) []string {
+	t.Helper()
+	files, err := filepath.Glob(filepath.Join(dir, "bucardoctl-orders-*.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return files
+}
+
+func newAPIStub() *apiStub {
+	return &apiStub{
+		sync:     domain.Sync{Name: "orders", Sources: []domain.DBRef{"1"}, Targets: []domain.DBRef{"2"}, Tables: "public.orders"},
+		revision: `"7"`,
+	}
+}
+
+func TestSyncsEdit(t *testing.T) {
+	api := newAPIStub()
+	srv := httptest.NewServer(api)
+	defer srv.Close()
+	tmp := useEditor(t, `s/"public.orders"/"public.orders, public.items"/`)
+
+	c, stdout, _ := newTestCtl(srv.URL)
+	if err := runSyncs(c, []string{"edit", "orders"}); err != nil {
+		t.Fatal(err)
+	}
+	if got := stdout.String(); got != "Sync orders updated\n" {
+		t.Errorf("output = %q", got)
+	}
+	// The update is based on the revision the sync was read at.
+	if len(api.updates) != 1 || api.updates[0].Tables != "public.orders, public.items" || api.ifMatch[0] != `"7"` {
+		t.Errorf("updates = %+v with If-Match %q, want the edited sync at revision 7", api.updates, api.ifMatch)
+	}
+	for header, want := range map[string]string{"Authorization": "Bearer t0ken", "X-Actor": "alice@host", "X-Change-Message": "Add items"} {
+		if got := api.headers.Get(header); got != want {
+			t.Errorf("%s = %q, want %q", header, got, want)
+		}
+	}
+	if files := kept(t, tmp); len(files) != 0 {
+		t.Errorf("temporary files %q left after a successful edit", files)
+	}
+}
+
+func TestSyncsEditConflict(t *testing.T) {
+	api := newAPIStub()
+	api.conflict = true
+	srv := httptest.NewServer(api)
+	defer srv.Close()
+	tmp := useEditor(t, `s/"public.orders"/"public.orders, public.items"/`)
+
+	c, stdout, _ := newTestCtl(srv.URL)
+	err := runSyncs(c, []string{"edit", "orders"})
+	if err == nil || !strings.Contains(err.Error(), "changed by someone else") {
+		t.Fatalf("edit error = %v, want a revision conflict", err)
+	}
+	if stdout.Len() != 0 {
+		t.Errorf("output = %q, want none", stdout.String())
+	}
+
+	// The edited version is kept for the operator to apply again.
+	files := kept(t, tmp)
+	if len(files) != 1 || !strings.Contains(err.Error(), files[0]) {
+		t.Fatalf("kept files = %q, want the one named in %v", files, err)
+	}
+	data, readErr := os.ReadFile(files[0])
+	if readErr != nil {
+		t.Fatal(readErr)
+	}
+	if !strings.Contains(string(data), `"public.orders, public.items"`) {
+		t.Errorf("kept file = %s, want the edited sync", data)
+	}
+}
+
+func TestSyncsEditWithoutChanges(t *testing.T) {
+	api := newAPIStub()
+	srv := httptest.NewServer(api)
+	defer srv.Close()
+	tmp := useEditor(t, "")
+
+	c, _, stderr := newTestCtl(srv.URL)
+	if err := runSyncs(c, []string{"edit", "orders"}); err != nil {
+		t.Fatal(err)
+	}
+	if got := stderr.String(); got != "No changes made\n" {
+		t.Errorf("stderr = %q", got)
+	}
+	if len(api.ifMatch) != 0 {
+		t.Error("an unchanged sync was submitted")
+	}
+	if files := kept(t, tmp); len(files) != 0 {
+		t.Errorf("temporary files %q left", files)
+	}
+}
+
+func TestSyncsListOutput(t *testing.T) {
+	srv := httptest.NewServer(newAPIStub())
+	defer srv.Close()
+
+	c, stdout, _ := newTestCtl(srv.URL)
+	if err := runSyncs(c, []string{"list"}); err != nil {
+		t.Fatal(err)
+	}
+	want := "NAME    STATUS  SOURCES  TARGETS  BIDIRECTIONAL  TABLES\n" +
+		"orders  active  1        2        -              public.orders\n"
+	if got := stdout.String(); got != want {
+		t.Errorf("table output =\n%s\nwant\n%s", got, want)
+	}
+
+	// Flags may follow the command.
+	c, stdout, _ = newTestCtl(srv.URL)
+	if err := runSyncs(c, []string{"list", "-o", "json"}); err != nil {
+		t.Fatal(err)
+	}
+	var syncs []domain.Sync
+	if err := json.Unmarshal(stdout.Bytes(), &syncs); err != nil || len(syncs) != 1 || syncs[0].Name != "orders" {
+		t.Errorf("json output = %s (%v)", stdout, err)
+	}
+
+	c, _, _ = newTestCtl(srv.URL)
+	if err := runSyncs(c, []string{"list", "-o", "yaml"}); err == nil {
+		t.Error("an unknown output format was accepted")
+	}
+}
+
+func TestAPIErrors(t *testing.T) {
+	srv := httptest.NewServer(newAPIStub())
+	defer srv.Close()
+
+	c, _, _ := newTestCtl(srv.URL)
+	err := runSyncs(c, []string{"get", "missing"})
+	if !isStatus(err, http.StatusNotFound) || err.Error() != "not found (HTTP 404)" {
+		t.Errorf("error = %v, want the API's 404 message", err)
+	}
+
+	// Errors without a JSON body fall back to their text.
+	plain := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+		io.WriteString(w, "upstream down\n")
+	}))
+	defer plain.Close()
+	c, _, _ = newTestCtl(plain.URL)
+	if err := runSyncs(c, []string{"list"}); err == nil || err.Error() != "upstream down (HTTP 502)" {
+		t.Errorf("error = %v", err)
+	}
+}
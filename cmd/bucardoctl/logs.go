@@ -0,0 +1,133 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"replication-service/internal/core/domain"
+)
+
+// liveMarker is the comment the server sends on /events once the replay is complete.
+const liveMarker = ": live"
+
+// runLogs prints log lines and replication events from the /events stream. Without
+// --follow it stops once the replayed history has been printed.
+func runLogs(c *ctl, args []string) error {
+	fs := c.flags("logs")
+	follow := fs.Bool("follow", false, "keep printing new lines")
+	fs.BoolVar(follow, "f", false, "shorthand for --follow")
+	tail := fs.Int("tail", 100, "number of past lines to print")
+	since := fs.String("since", "", "print past lines since this RFC 3339 timestamp")
+	level := fs.String("level", "", "lowest level to print: DEBUG, INFO, WARN or ERROR")
+	syncName := fs.String("sync", "", "only lines about this sync")
+	component := fs.String("component", "", "only lines of this component")
+	if _, err := c.parse(fs, args); err != nil {
+		return err
+	}
+
+	query := url.Values{"tail": {strconv.Itoa(*tail)}}
+	for name, value := range map[string]string{"since": *since, "level": *level, "sync_name": *syncName, "component": *component} {
+		if value != "" {
+			query.Set(name, value)
+		}
+	}
+	resp, err := c.client().stream(request{method: http.MethodGet, path: "/events", query: query})
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	scanner := bufio.NewScanner(resp.Body)
+	scanner.Buffer(make([]byte, 64<<10), 1<<20)
+	var event string
+	var data []string
+	for scanner.Scan() {
+		line := scanner.Text()
+		switch {
+		case line == "":
+			if len(data) > 0 {
+				c.printEvent(event, strings.Join(data, "\n"))
+			}
+			event, data = "", nil
+		case line == liveMarker:
+			if !*follow {
+				return nil
+			}
+		case strings.HasPrefix(line, "event:"):
+			event = strings.TrimSpace(strings.TrimPrefix(line, "event:"))
+		case strings.HasPrefix(line, "data:"):
+			data = append(data, strings.TrimPrefix(strings.TrimPrefix(line, "data:"), " "))
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("log stream failed: %w", err)
+	}
+	return fmt.Errorf("log stream closed by the server")
+}
+
+// printEvent prints one message of the stream. In json mode the message is printed as
+// sent, one per line.
+func (c *ctl) printEvent(event, data string) {
+	if c.opts.output == "json" {
+		fmt.Fprintln(c.stdout, data)
+		return
+	}
+	if event != "" && event != "log" {
+		var e domain.Event
+		if err := json.Unmarshal([]byte(data), &e); err == nil {
+			sync := ""
+			if e.SyncName != "" {
+				sync = " [" + e.SyncName + "]"
+			}
+			fmt.Fprintf(c.stdout, "%s %-5s %s%s %s\n", e.Time.Local().Format(time.DateTime), strings.ToUpper(e.Severity), e.Type, sync, e.Message)
+			return
+		}
+	}
+	fmt.Fprintln(c.stdout, formatLogLine(data))
+}
+
+// formatLogLine turns a JSON log line into "time LEVEL [component] message key=value...".
+// Lines that are not JSON are returned unchanged.
+func formatLogLine(data string) string {
+	var fields map[string]any
+	if err := json.Unmarshal([]byte(data), &fields); err != nil {
+		return data
+	}
+	var b strings.Builder
+	if t, err := time.Parse(time.RFC3339Nano, fmt.Sprint(fields["time"])); err == nil {
+		b.WriteString(t.Local().Format(time.DateTime) + " ")
+	}
+	fmt.Fprintf(&b, "%-5v", fields["level"])
+	if component, ok := fields["component"]; ok {
+		fmt.Fprintf(&b, " [%v]", component)
+	}
+	fmt.Fprintf(&b, " %v", fields["msg"])
+
+	keys := make([]string, 0, len(fields))
+	for key := range fields {
+		switch key {
+		case "time", "level", "component", "msg":
+		default:
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		value := fields[key]
+		if s, ok := value.(string); ok && strings.ContainsAny(s, " \"=") {
+			value = strconv.Quote(s)
+		} else if _, ok := value.(string); !ok {
+			encoded, _ := json.Marshal(value)
+			value = string(encoded)
+		}
+		fmt.Fprintf(&b, " %s=%v", key, value)
+	}
+	return b.String()
+}
@@ -0,0 +1,75 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// stream is what the server sends on /events: two replayed messages, the live marker
+// and a live message.
+const stream = "id: 1\nevent: log\ndata: {\"time\":\"2026-10-18T10:00:00Z\",\"level\":\"INFO\",\"component\":\"reconciler\",\"msg\":\"Reconcile started\",\"sync_name\":\"orders\"}\n\n" +
+	"id: 2\nevent: kid_died\ndata: {\"type\":\"kid_died\",\"severity\":\"error\",\"time\":\"2026-10-18T10:00:01Z\",\"sync_name\":\"orders\",\"message\":\"KID died\"}\n\n" +
+	": live\n\n" +
+	": keepalive\n\n" +
+	"id: 3\nevent: log\ndata: not json\n\n"
+
+func TestLogs(t *testing.T) {
+	var query string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query = r.URL.RawQuery
+		w.Header().Set("Content-Type", "text/event-stream")
+		fmt.Fprint(w, stream)
+	}))
+	defer srv.Close()
+	at := func(s string) string {
+		t, _ := time.Parse(time.RFC3339, s)
+		return t.Local().Format(time.DateTime)
+	}
+
+	tests := []struct {
+		name string
+		args []string
+		want string
+	}{
+		{
+			name: "replay only",
+			args: []string{"--tail", "10", "--sync", "orders"},
+			want: at("2026-10-18T10:00:00Z") + " INFO  [reconciler] Reconcile started sync_name=orders\n" +
+				at("2026-10-18T10:00:01Z") + " ERROR kid_died [orders] KID died\n",
+		},
+		{
+			name: "json",
+			args: []string{"--tail", "10", "--sync", "orders", "-o", "json"},
+			want: `{"time":"2026-10-18T10:00:00Z","level":"INFO","component":"reconciler","msg":"Reconcile started","sync_name":"orders"}` + "\n" +
+				`{"type":"kid_died","severity":"error","time":"2026-10-18T10:00:01Z","sync_name":"orders","message":"KID died"}` + "\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, stdout, _ := newTestCtl(srv.URL)
+			if err := runLogs(c, tt.args); err != nil {
+				t.Fatal(err)
+			}
+			if got := stdout.String(); got != tt.want {
+				t.Errorf("output =\n%s\nwant\n%s", got, tt.want)
+			}
+			if query != "sync_name=orders&tail=10" {
+				t.Errorf("query = %q", query)
+			}
+		})
+	}
+
+	// Following prints the live messages too, until the server ends the stream.
+	c, stdout, _ := newTestCtl(srv.URL)
+	err := runLogs(c, []string{"-f"})
+	if err == nil || err.Error() != "log stream closed by the server" {
+		t.Errorf("error = %v, want the stream closed", err)
+	}
+	if !strings.HasSuffix(stdout.String(), "KID died\nnot json\n") {
+		t.Errorf("output = %q, want it to end with the live line", stdout.String())
+	}
+}
@@ -0,0 +1,182 @@
+// Command bucardoctl drives the replication service's management API from the command
+// line.
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/user"
+	"strings"
+)
+
+const defaultServer = "http://localhost:8080"
+
+const usage = `Usage: bucardoctl [flags] <command> [arguments]
+
+Commands:
+  syncs list                    List syncs
+  syncs get <name>              Show a sync
+  syncs create -f <file>        Add a sync from a JSON file ("-" reads stdin)
+  syncs edit <name>             Edit a sync in $EDITOR
+  syncs delete <name>           Remove a sync
+  config get                    Print the full configuration
+  config apply -f <file>        Replace the full configuration
+  start                         Start Bucardo
+  stop                          Stop Bucardo
+  restart [--wait]              Reconcile Bucardo with the configuration and restart it
+  logs [--follow] [--tail n]    Print log lines and replication events
+  status                        Summarise syncs, the last reconcile and delta backlogs
+
+Flags (accepted before or after the command):
+  --server url     API address (BUCARDOCTL_SERVER, default ` + defaultServer + `)
+  --token token    API token (BUCARDOCTL_TOKEN)
+  -o format        Output format: table or json (default table)
+  -m message       Change message recorded in the config history
+  --actor name     Actor recorded in the config history and audit trail (default user@host)
+`
+
+// options are the flags shared by every command.
+type options struct {
+	server  string
+	token   string
+	output  string
+	message string
+	actor   string
+}
+
+// register adds the shared flags to a flag set, with the current values as defaults, so
+// they can be given both before and after the command.
+func (o *options) register(fs *flag.FlagSet) {
+	fs.StringVar(&o.server, "server", o.server, "API address")
+	fs.StringVar(&o.token, "token", o.token, "API token")
+	fs.StringVar(&o.output, "o", o.output, "output format: table or json")
+	fs.StringVar(&o.message, "m", o.message, "change message")
+	fs.StringVar(&o.actor, "actor", o.actor, "actor recorded in the audit trail")
+}
+
+// command is a subcommand. run receives the arguments after the command name.
+type command struct {
+	name string
+	run  func(ctl *ctl, args []string) error
+}
+
+var commands = []command{
+	{"syncs", runSyncs},
+	{"config", runConfig},
+	{"start", runStart},
+	{"stop", runStop},
+	{"restart", runRestart},
+	{"logs", runLogs},
+	{"status", runStatus},
+}
+
+// ctl holds the state shared by the commands.
+type ctl struct {
+	opts   *options
+	stdout io.Writer
+	stderr io.Writer
+}
+
+// flags returns a flag set for a command with the shared flags registered.
+func (c *ctl) flags(name string) *flag.FlagSet {
+	fs := flag.NewFlagSet("bucardoctl "+name, flag.ContinueOnError)
+	fs.SetOutput(c.stderr)
+	c.opts.register(fs)
+	return fs
+}
+
+// parse parses a command's flags and checks the shared ones. Flags may follow positional
+// arguments, as in "syncs get orders -o json".
+func (c *ctl) parse(fs *flag.FlagSet, args []string) ([]string, error) {
+	var positional []string
+	for {
+		if err := fs.Parse(args); err != nil {
+			return nil, err
+		}
+		if fs.NArg() == 0 {
+			break
+		}
+		positional = append(positional, fs.Arg(0))
+		args = fs.Args()[1:]
+	}
+	if c.opts.output != "table" && c.opts.output != "json" {
+		return nil, fmt.Errorf("invalid output format %q, expected table or json", c.opts.output)
+	}
+	return positional, nil
+}
+
+func (c *ctl) client() *client {
+	return newClient(c.opts)
+}
+
+func main() {
+	opts := &options{
+		server: getEnv("BUCARDOCTL_SERVER", defaultServer),
+		token:  os.Getenv("BUCARDOCTL_TOKEN"),
+		output: "table",
+		actor:  defaultActor(),
+	}
+	c := &ctl{opts: opts, stdout: os.Stdout, stderr: os.Stderr}
+
+	fs := flag.NewFlagSet("bucardoctl", flag.ContinueOnError)
+	fs.SetOutput(os.Stderr)
+	fs.Usage = func() { fmt.Fprint(os.Stderr, usage) }
+	opts.register(fs)
+	if err := fs.Parse(os.Args[1:]); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			os.Exit(0)
+		}
+		os.Exit(2)
+	}
+	if fs.NArg() == 0 {
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+
+	name := fs.Arg(0)
+	if name == "help" {
+		fmt.Fprint(os.Stdout, usage)
+		return
+	}
+	for _, cmd := range commands {
+		if cmd.name != name {
+			continue
+		}
+		if err := cmd.run(c, fs.Args()[1:]); err != nil {
+			if errors.Is(err, flag.ErrHelp) {
+				os.Exit(0)
+			}
+			fmt.Fprintf(os.Stderr, "bucardoctl: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+	fmt.Fprintf(os.Stderr, "bucardoctl: unknown command %q\n\n%s", name, usage)
+	os.Exit(2)
+}
+
+// defaultActor identifies the operator as user@host.
+func defaultActor() string {
+	name := os.Getenv("USER")
+	if u, err := user.Current(); err == nil {
+		name = u.Username
+	}
+	host, _ := os.Hostname()
+	switch {
+	case name == "":
+		return host
+	case host == "":
+		return name
+	}
+	return name + "@" + strings.TrimSuffix(host, ".")
+}
+
+func getEnv(key, fallback string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return fallback
+}
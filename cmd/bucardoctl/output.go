@@ -0,0 +1,92 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"replication-service/internal/core/domain"
+)
+
+// render prints v as indented JSON in json mode, or calls table with a tab-separated
+// writer otherwise.
+func (c *ctl) render(v any, table func(w io.Writer)) error {
+	if c.opts.output == "json" {
+		return printJSON(c.stdout, v)
+	}
+	tw := tabwriter.NewWriter(c.stdout, 0, 0, 2, ' ', 0)
+	table(tw)
+	return tw.Flush()
+}
+
+// printMessage prints the message of an action.
+func (c *ctl) printMessage(resp domain.MessageResponse) error {
+	return c.render(resp, func(w io.Writer) {
+		fmt.Fprintln(w, resp.Message)
+	})
+}
+
+func printJSON(w io.Writer, v any) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "%s\n", data)
+	return err
+}
+
+func syncTable(w io.Writer, syncs []domain.Sync) {
+	fmt.Fprintln(w, "NAME\tSTATUS\tSOURCES\tTARGETS\tBIDIRECTIONAL\tTABLES")
+	for _, s := range syncs {
+		status := s.Status
+		if status == "" {
+			status = domain.SyncStatusActive
+		}
+		tables := s.Tables
+		if s.Herd != "" {
+			tables = "herd " + s.Herd
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n", s.Name, status, refs(s.Sources), refs(s.Targets), refs(s.Bidirectional), dash(tables))
+	}
+}
+
+func jobTable(w io.Writer, job domain.Job) {
+	fmt.Fprintf(w, "JOB\t%s\n", job.ID)
+	fmt.Fprintf(w, "TYPE\t%s\n", job.Type)
+	fmt.Fprintf(w, "STATE\t%s\n", job.State)
+	fmt.Fprintf(w, "CREATED\t%s\n", job.CreatedAt.Local().Format(time.DateTime))
+	if job.FinishedAt != nil {
+		fmt.Fprintf(w, "FINISHED\t%s\n", job.FinishedAt.Local().Format(time.DateTime))
+	}
+	if job.Error != "" {
+		fmt.Fprintf(w, "ERROR\t%s\n", job.Error)
+	}
+	for _, step := range job.Steps {
+		line := step.Name + ": " + step.State
+		if step.Error != "" {
+			line += " (" + step.Error + ")"
+		}
+		fmt.Fprintf(w, "STEP\t%s\n", line)
+	}
+}
+
+func refs(values []domain.DBRef) string {
+	if len(values) == 0 {
+		return "-"
+	}
+	parts := make([]string, len(values))
+	for i, v := range values {
+		parts[i] = string(v)
+	}
+	return strings.Join(parts, ",")
+}
+
+func dash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
@@ -0,0 +1,132 @@
+package audit
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"replication-service/internal/core/domain"
+	"replication-service/internal/core/ports"
+	"replication-service/internal/core/reqctx"
+)
+
+const defaultQueryLimit = 500
+
+// JSONLLog implements the ports.AuditLog interface with an append-only file holding one
+// JSON record per line.
+type JSONLLog struct {
+	logger ports.Logger
+	path   string
+	mu     sync.Mutex // Keeps concurrent records from interleaving.
+}
+
+// NewJSONLLog creates a new JSONLLog that appends to path.
+func NewJSONLLog(logger ports.Logger, path string) *JSONLLog {
+	return &JSONLLog{logger: logger, path: path}
+}
+
+// Record appends a record to the file. Failures are logged but never fail the operation
+// being audited.
+func (l *JSONLLog) Record(ctx context.Context, record domain.AuditRecord) {
+	if record.Time.IsZero() {
+		record.Time = time.Now()
+	}
+	if record.Actor == "" {
+		record.Actor = reqctx.Actor(ctx)
+	}
+	if record.CorrelationID == "" {
+		record.CorrelationID = reqctx.CorrelationID(ctx)
+	}
+	line, err := json.Marshal(record)
+	if err != nil {
+		l.logger.Error("Failed to encode audit record", "component", "audit", "error", err)
+		return
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if err := l.append(append(line, '\n')); err != nil {
+		l.logger.Error("Failed to write audit record", "component", "audit", "path", l.path, "error", err)
+	}
+}
+
+func (l *JSONLLog) append(line []byte) error {
+	if err := os.MkdirAll(filepath.Dir(l.path), 0755); err != nil {
+		return err
+	}
+	// Opened per record so the file can be rotated or shipped away at any time.
+	file, err := os.OpenFile(l.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		return err
+	}
+	if _, err := file.Write(line); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+// Query returns the records matching q, newest first.
+func (l *JSONLLog) Query(_ context.Context, q domain.AuditQuery) ([]domain.AuditRecord, error) {
+	limit := q.Limit
+	if limit <= 0 {
+		limit = defaultQueryLimit
+	}
+
+	file, err := os.Open(l.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return []domain.AuditRecord{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to open audit log %s: %w", l.path, err)
+	}
+	defer file.Close()
+
+	var matched []domain.AuditRecord
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		var record domain.AuditRecord
+		if err := json.Unmarshal(scanner.Bytes(), &record); err != nil {
+			continue // A torn last line after a crash.
+		}
+		if !matches(q, record) {
+			continue
+		}
+		matched = append(matched, record)
+		if len(matched) > limit {
+			matched = matched[1:]
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read audit log %s: %w", l.path, err)
+	}
+
+	records := make([]domain.AuditRecord, 0, len(matched))
+	for i := len(matched) - 1; i >= 0; i-- {
+		records = append(records, matched[i])
+	}
+	return records, nil
+}
+
+func matches(q domain.AuditQuery, r domain.AuditRecord) bool {
+	switch {
+	case !q.Since.IsZero() && r.Time.Before(q.Since):
+		return false
+	case !q.Until.IsZero() && r.Time.After(q.Until):
+		return false
+	case q.Actor != "" && r.Actor != q.Actor:
+		return false
+	case q.Kind != "" && r.Kind != q.Kind:
+		return false
+	case q.CorrelationID != "" && r.CorrelationID != q.CorrelationID:
+		return false
+	}
+	return true
+}
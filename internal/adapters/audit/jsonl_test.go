@@ -0,0 +1,67 @@
+package audit
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"replication-service/internal/adapters/logger"
+	"replication-service/internal/core/domain"
+	"replication-service/internal/core/reqctx"
+)
+
+func TestQuery(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit", "audit.jsonl")
+	log := NewJSONLLog(logger.NewSlogAdapter(slog.New(slog.NewTextHandler(io.Discard, nil))), path)
+
+	if records, err := log.Query(context.Background(), domain.AuditQuery{}); err != nil || len(records) != 0 {
+		t.Fatalf("Query() without a file = %v, %v, want no records", records, err)
+	}
+
+	start := time.Date(2026, 10, 18, 10, 0, 0, 0, time.UTC)
+	ctx := reqctx.WithCorrelationID(reqctx.WithActor(context.Background(), "alice"), "req-1")
+	for i, endpoint := range []string{"/config", "/syncs", "/syncs/orders", "/restart"} {
+		log.Record(ctx, domain.AuditRecord{Kind: domain.AuditKindAPI, Endpoint: endpoint, Time: start.Add(time.Duration(i) * time.Minute)})
+	}
+	log.Record(context.Background(), domain.AuditRecord{Kind: domain.AuditKindCommand, Endpoint: "bucardo status", Time: start.Add(4 * time.Minute)})
+
+	// A crash while appending leaves a torn last line.
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.WriteString(`{"time":"2026-10-18T10:05:00Z","kind":"api","endp`)
+	f.Close()
+
+	tests := []struct {
+		name string
+		q    domain.AuditQuery
+		want []string
+	}{
+		{"all, newest first", domain.AuditQuery{}, []string{"bucardo status", "/restart", "/syncs/orders", "/syncs", "/config"}},
+		{"limit keeps the newest", domain.AuditQuery{Limit: 2}, []string{"bucardo status", "/restart"}},
+		{"limit applies after filters", domain.AuditQuery{Kind: domain.AuditKindAPI, Limit: 3}, []string{"/restart", "/syncs/orders", "/syncs"}},
+		{"time range", domain.AuditQuery{Since: start.Add(time.Minute), Until: start.Add(2 * time.Minute)}, []string{"/syncs/orders", "/syncs"}},
+		{"actor from the context", domain.AuditQuery{Actor: "alice"}, []string{"/restart", "/syncs/orders", "/syncs", "/config"}},
+		{"system actor", domain.AuditQuery{Actor: reqctx.SystemActor}, []string{"bucardo status"}},
+		{"correlation ID", domain.AuditQuery{CorrelationID: "req-1", Limit: 1}, []string{"/restart"}},
+	}
+	for _, tt := range tests {
+		records, err := log.Query(context.Background(), tt.q)
+		if err != nil {
+			t.Fatalf("%s: Query() = %v", tt.name, err)
+		}
+		var got []string
+		for _, r := range records {
+			got = append(got, r.Endpoint)
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: Query() = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
@@ -3,37 +3,86 @@ package bucardo
 import (
 	"bufio"
 	"context"
+	"errors"
 	"fmt"
+	"io"
 	"os"
 	"os/exec"
 	"regexp"
 	"sort"
+	"strconv"
 	"strings"
+	"sync"
 	"time"
 
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+
+	logadapter "replication-service/internal/adapters/logger"
+	"replication-service/internal/adapters/tracing"
+	"replication-service/internal/core/domain"
 	"replication-service/internal/core/ports"
 )
 
-// redactPassword replaces the password in a command string with asterisks.
-func redactPassword(cmd string) string {
-	re := regexp.MustCompile(`pass=[^ ]+`)
-	return re.ReplaceAllString(cmd, "pass=*****")
-}
-
 // CLIExecutor implements the BucardoExecutor port using os/exec.
 type CLIExecutor struct {
 	logger      ports.Logger
+	audit       ports.AuditLog
+	redactor    *logadapter.Redactor
 	bucardoUser string
 	bucardoCmd  string
+	pidPath     string
 }
 
-// NewCLIExecutor creates a new CLIExecutor.
-func NewCLIExecutor(logger ports.Logger, bucardoUser, bucardoCmd string) *CLIExecutor {
+// NewCLIExecutor creates a new CLIExecutor. Every command it runs is recorded in audit,
+// with the secrets redactor knows of removed. pidPath is the pid file of Bucardo's main
+// process, whose removal StopBucardo waits for; when it is empty StopBucardo does not wait.
+func NewCLIExecutor(logger ports.Logger, audit ports.AuditLog, redactor *logadapter.Redactor, bucardoUser, bucardoCmd, pidPath string) *CLIExecutor {
 	return &CLIExecutor{
 		logger:      logger,
+		audit:       audit,
+		redactor:    redactor,
 		bucardoUser: bucardoUser,
 		bucardoCmd:  bucardoCmd,
+		pidPath:     pidPath,
+	}
+}
+
+// recordCommand adds a finished command to the audit trail with its exit code and duration,
+// and traces it as a span that started at started.
+func (e *CLIExecutor) recordCommand(ctx context.Context, command string, started time.Time, err error) {
+	finished := time.Now()
+	record := domain.AuditRecord{
+		Kind:       domain.AuditKindCommand,
+		Command:    e.redactor.Redact(command),
+		DurationMs: finished.Sub(started).Milliseconds(),
+	}
+	defer func() {
+		_, span := tracing.Tracer().Start(ctx, "bucardo command", trace.WithTimestamp(started),
+			trace.WithSpanKind(trace.SpanKindClient), trace.WithAttributes(attribute.String("command", record.Command)))
+		if record.ExitCode != nil {
+			span.SetAttributes(attribute.Int("exit_code", *record.ExitCode))
+		}
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+		span.End(trace.WithTimestamp(finished))
+	}()
+	var exitErr *exec.ExitError
+	switch {
+	case err == nil:
+		exitCode := 0
+		record.ExitCode = &exitCode
+	case errors.As(err, &exitErr):
+		exitCode := exitErr.ExitCode()
+		record.ExitCode = &exitCode
+		record.Error = err.Error()
+	default:
+		record.Error = err.Error() // The command could not be started.
 	}
+	e.audit.Record(ctx, record)
 }
 
 func (e *CLIExecutor) runCommand(ctx context.Context, logCmd, name string, arg ...string) error {
@@ -41,32 +90,56 @@ func (e *CLIExecutor) runCommand(ctx context.Context, logCmd, name string, arg .
 	if logCmd == "" {
 		logCmd = cmd.String()
 	}
-	// Redact password before logging
-	redactedLogCmd := redactPassword(logCmd)
-	e.logger.Info("Running command", "component", "command_runner", "command", redactedLogCmd)
+	logger := e.logger.WithContext(ctx)
+	logger.Info("Running command", "component", "command_runner", "command", e.redactor.Redact(logCmd))
+	started := time.Now()
 
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return fmt.Errorf("failed to get stdout pipe: %w", err)
+	}
 	stderr, err := cmd.StderrPipe()
 	if err != nil {
 		return fmt.Errorf("failed to get stderr pipe: %w", err)
 	}
-	// Note: Streaming stdout directly might not be desirable for all commands in a library.
-	// We'll keep it for commands that are expected to produce user-facing output.
-	cmd.Stdout = os.Stdout
 
 	if err := cmd.Start(); err != nil {
+		e.recordCommand(ctx, logCmd, started, err)
 		return fmt.Errorf("failed to start command: %w", err)
 	}
 
-	scanner := bufio.NewScanner(stderr)
+	// The output goes through the logger, whose handler removes secrets before anything
+	// reaches stdout.
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		logOutput(stdout, func(line string) { logger.Info(line, "component", "command_runner", "stream", "stdout") })
+	}()
+	go func() {
+		defer wg.Done()
+		logOutput(stderr, func(line string) {
+			// Filter known harmless messages
+			if !strings.HasPrefix(line, "No such dbgroup:") && !strings.HasPrefix(line, "No such sync:") {
+				logger.Warn(line, "component", "command_runner", "stream", "stderr")
+			}
+		})
+	}()
+	wg.Wait()
+
+	err = cmd.Wait()
+	e.recordCommand(ctx, logCmd, started, err)
+	return err
+}
+
+// logOutput passes each line read from r to log until r is exhausted.
+func logOutput(r io.Reader, log func(line string)) {
+	scanner := bufio.NewScanner(r)
 	for scanner.Scan() {
-		line := scanner.Text()
-		// Filter known harmless messages
-		if !strings.HasPrefix(line, "No such dbgroup:") && !strings.HasPrefix(line, "No such sync:") {
-			fmt.Fprintln(os.Stderr, line)
-		}
+		log(scanner.Text())
 	}
-
-	return cmd.Wait()
+	// Drain what the scanner gave up on, so the command is not blocked writing it.
+	io.Copy(io.Discard, r)
 }
 
 func (e *CLIExecutor) runBucardoCommand(ctx context.Context, args ...string) error {
@@ -77,8 +150,11 @@ func (e *CLIExecutor) runBucardoCommand(ctx context.Context, args ...string) err
 func (e *CLIExecutor) runBucardoCommandWithOutput(ctx context.Context, args ...string) ([]byte, error) {
 	cmdStr := fmt.Sprintf("%s %s", e.bucardoCmd, strings.Join(args, " "))
 	cmd := exec.CommandContext(ctx, "su", "-", e.bucardoUser, "-c", cmdStr)
-	e.logger.Debug("Running command for output", "command", cmdStr)
-	return cmd.CombinedOutput()
+	e.logger.WithContext(ctx).Debug("Running command for output", "command", cmdStr)
+	started := time.Now()
+	output, err := cmd.CombinedOutput()
+	e.recordCommand(ctx, cmdStr, started, err)
+	return output, err
 }
 
 // EnsureBucardoUserPassword forces the password for the 'bucardo' user to match the configuration.
@@ -97,18 +173,20 @@ func (e *CLIExecutor) EnsureBucardoUserPassword(ctx context.Context, dbhost, dbu
 	// The safest is to run as the OS user 'postgres' to match other commands.
 	cmd := exec.CommandContext(ctx, "su", "-", e.bucardoUser, "-c", cmdStr)
 
-	e.logger.Info("Ensuring 'bucardo' user password is correct", "component", "auth_fixer", "host", dbhost, "user", bucardoUser)
+	e.logger.WithContext(ctx).Info("Ensuring 'bucardo' user password is correct", "component", "auth_fixer", "host", dbhost, "user", bucardoUser)
 	
+	started := time.Now()
 	output, err := cmd.CombinedOutput()
+	e.recordCommand(ctx, cmdStr, started, err)
 	if err != nil {
 		// If the user doesn't exist, ALTER USER will fail. We can ignore that because InstallBucardo will create it.
 		if strings.Contains(string(output), "does not exist") {
-			e.logger.Info("User 'bucardo' does not exist yet, skipping password reset.", "component", "auth_fixer")
+			e.logger.WithContext(ctx).Info("User 'bucardo' does not exist yet, skipping password reset.", "component", "auth_fixer")
 			return nil
 		}
 		return fmt.Errorf("failed to reset bucardo user password: %w. Output: %s", err, string(output))
 	}
-	e.logger.Info("Successfully updated 'bucardo' user password.", "component", "auth_fixer")
+	e.logger.WithContext(ctx).Info("Successfully updated 'bucardo' user password.", "component", "auth_fixer")
 	return nil
 }
 
@@ -116,7 +194,7 @@ func (e *CLIExecutor) EnsureBucardoUserPassword(ctx context.Context, dbhost, dbu
 func (e *CLIExecutor) InstallBucardo(ctx context.Context, dbname, host, user, pass string) error {
 	// 1. Pre-check: See if Bucardo is already operational.
 	if _, err := e.runBucardoCommandWithOutput(ctx, "list", "dbs"); err == nil {
-		e.logger.Info("Bucardo appears to be already installed and operational.", "component", "bucardo_installer")
+		e.logger.WithContext(ctx).Info("Bucardo appears to be already installed and operational.", "component", "bucardo_installer")
 		return nil
 	}
 
@@ -127,21 +205,23 @@ func (e *CLIExecutor) InstallBucardo(ctx context.Context, dbname, host, user, pa
 
 	cmd := exec.CommandContext(ctx, "su", "-", e.bucardoUser, "-c", cmdStr)
 
-	e.logger.Info("Running Bucardo installation", "component", "bucardo_installer", "command", logCmd)
+	e.logger.WithContext(ctx).Info("Running Bucardo installation", "component", "bucardo_installer", "command", logCmd)
+	started := time.Now()
 	output, err := cmd.CombinedOutput()
+	e.recordCommand(ctx, logCmd, started, err)
 	if err != nil {
 		// 'bucardo install' can exit with a non-zero status if it's already installed (e.g. "role already exists").
 		// If that happens, we check if the installation is actually working now.
 		if strings.Contains(string(output), "already exists") {
-			e.logger.Info("Installation reported 'already exists'. Verifying installation state...", "component", "bucardo_installer")
+			e.logger.WithContext(ctx).Info("Installation reported 'already exists'. Verifying installation state...", "component", "bucardo_installer")
 			if _, checkErr := e.runBucardoCommandWithOutput(ctx, "list", "dbs"); checkErr == nil {
-				e.logger.Info("Bucardo is operational despite install error. Assuming success.", "component", "bucardo_installer")
+				e.logger.WithContext(ctx).Info("Bucardo is operational despite install error. Assuming success.", "component", "bucardo_installer")
 				return nil
 			}
 		}
 		return fmt.Errorf("bucardo install failed: %w. Output: %s", err, string(output))
 	}
-	e.logger.Info("Bucardo installation command finished.", "output", string(output))
+	e.logger.WithContext(ctx).Info("Bucardo installation command finished.", "output", string(output))
 	return nil
 }
 
@@ -179,7 +259,7 @@ func (e *CLIExecutor) ListDatabases(ctx context.Context) ([]string, error) {
 func (e *CLIExecutor) DatabaseExists(ctx context.Context, dbName string) (bool, error) {
 	allDbs, err := e.ListDatabases(ctx)
 	if err != nil {
-		e.logger.Warn("Could not list Bucardo databases to check for existence", "error", err)
+		e.logger.WithContext(ctx).Warn("Could not list Bucardo databases to check for existence", "error", err)
 		return false, err
 	}
 	for _, bdb := range allDbs {
@@ -195,11 +275,132 @@ func (e *CLIExecutor) ExecuteBucardoCommand(ctx context.Context, args ...string)
 	return e.runBucardoCommand(ctx, args...)
 }
 
+// KickSync asks Bucardo to run a sync immediately. If timeout is greater than zero the
+// command blocks until the run finishes or the timeout (in seconds) expires, and the
+// resulting state and row counts are read back from 'bucardo status'.
+func (e *CLIExecutor) KickSync(ctx context.Context, syncName string, timeout int) (*domain.SyncRunResult, error) {
+	args := []string{"kick", syncName}
+	if timeout > 0 {
+		args = append(args, strconv.Itoa(timeout))
+	}
+
+	e.logger.WithContext(ctx).Info("Kicking sync", "component", "sync_kicker", "sync_name", syncName, "timeout", timeout)
+	start := time.Now()
+	output, err := e.runBucardoCommandWithOutput(ctx, args...)
+	result := &domain.SyncRunResult{
+		SyncName:   syncName,
+		DurationMs: time.Since(start).Milliseconds(),
+		Output:     strings.TrimSpace(string(output)),
+	}
+
+	outputStr := string(output)
+	if strings.Contains(outputStr, "No such sync") {
+		return nil, fmt.Errorf("bucardo does not know sync %s. Output: %s", syncName, result.Output)
+	}
+
+	switch {
+	case timeout <= 0:
+		if err != nil {
+			return nil, fmt.Errorf("failed to kick sync %s: %w. Output: %s", syncName, err, result.Output)
+		}
+		result.Outcome = domain.SyncRunKicked
+	case strings.Contains(outputStr, "DONE"):
+		result.Outcome = domain.SyncRunDone
+	case strings.Contains(outputStr, "Timed out"), strings.Contains(outputStr, "TIMEOUT"):
+		result.Outcome = domain.SyncRunTimeout
+	case strings.Contains(outputStr, "FAILED"), strings.Contains(outputStr, "KILLED"), err != nil:
+		result.Outcome = domain.SyncRunFailed
+	default:
+		result.Outcome = domain.SyncRunUnknown
+	}
+
+	if result.Outcome != domain.SyncRunKicked {
+		if err := e.fillSyncStatus(ctx, result); err != nil {
+			e.logger.WithContext(ctx).Warn("Could not read sync status after kick", "component", "sync_kicker", "sync_name", syncName, "error", err)
+		}
+	}
+	return result, nil
+}
+
+// fillSyncStatus parses the output of `bucardo status <sync>` into the given result.
+func (e *CLIExecutor) fillSyncStatus(ctx context.Context, result *domain.SyncRunResult) error {
+	output, err := e.runBucardoCommandWithOutput(ctx, "status", result.SyncName)
+	if err != nil {
+		return fmt.Errorf("failed to execute 'bucardo status %s': %w. Output: %s", result.SyncName, err, string(output))
+	}
+
+	stateRe := regexp.MustCompile(`(?m)^Current state[ \t]*:[ \t]*(.+?)[ \t]*$`)
+	if m := stateRe.FindStringSubmatch(string(output)); m != nil {
+		result.State = m[1]
+	}
+
+	rowsRe := regexp.MustCompile(`(?m)^Rows deleted/inserted[ \t]*:[ \t]*(\d+)[ \t]*/[ \t]*(\d+)`)
+	if m := rowsRe.FindStringSubmatch(string(output)); m != nil {
+		deleted, _ := strconv.Atoi(m[1])
+		inserted, _ := strconv.Atoi(m[2])
+		result.RowsDeleted = &deleted
+		result.RowsInserted = &inserted
+	}
+	return nil
+}
+
+// GetSyncStatus returns the current state and row counts of a sync's last run as reported
+// by `bucardo status`. Outcome and DurationMs are left empty.
+func (e *CLIExecutor) GetSyncStatus(ctx context.Context, syncName string) (*domain.SyncRunResult, error) {
+	result := &domain.SyncRunResult{SyncName: syncName}
+	if err := e.fillSyncStatus(ctx, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// ActivateSync marks a sync as active and tells a running Bucardo to start it.
+func (e *CLIExecutor) ActivateSync(ctx context.Context, syncName string) error {
+	return e.runBucardoCommand(ctx, "activate", syncName)
+}
+
+// DeactivateSync marks a sync as inactive and tells a running Bucardo to stop it.
+func (e *CLIExecutor) DeactivateSync(ctx context.Context, syncName string) error {
+	return e.runBucardoCommand(ctx, "deactivate", syncName)
+}
+
+// SetSyncOnetimecopy changes the onetimecopy mode of an existing sync and asks a running
+// Bucardo to reload it so the new mode is used on the next run.
+func (e *CLIExecutor) SetSyncOnetimecopy(ctx context.Context, syncName string, mode int) error {
+	if err := e.runBucardoCommand(ctx, "update", "sync", syncName, fmt.Sprintf("onetimecopy=%d", mode)); err != nil {
+		return fmt.Errorf("failed to set onetimecopy=%d on sync %s: %w", mode, syncName, err)
+	}
+	if err := e.runBucardoCommand(ctx, "reload", "sync", syncName); err != nil {
+		e.logger.WithContext(ctx).Warn("Could not reload sync after changing onetimecopy; it applies once Bucardo restarts", "sync_name", syncName, "error", err)
+	}
+	return nil
+}
+
 // RemoveDatabase removes a database from bucardo.
 func (e *CLIExecutor) RemoveDatabase(ctx context.Context, dbName string) error {
 	return e.runBucardoCommand(ctx, "del", "dbs", dbName)
 }
 
+// RenameDatabase renames a database in Bucardo's own tables. The CLI cannot rename a
+// database, and removing and adding it again would remove the syncs that use it. Bucardo's
+// foreign keys carry the new name into its dbgroups and relgroups.
+func (e *CLIExecutor) RenameDatabase(ctx context.Context, oldName, newName, dbHost, dbUser, dbPass string, dbPort int) error {
+	sql := fmt.Sprintf("UPDATE bucardo.db SET name = '%s' WHERE name = '%s';", newName, oldName)
+	cmdStr := fmt.Sprintf("PGPASSWORD=%s psql -h %s -p %d -U %s -d bucardo -c \"%s\"", dbPass, dbHost, dbPort, dbUser, sql)
+	cmd := exec.CommandContext(ctx, "su", "-", e.bucardoUser, "-c", cmdStr)
+
+	started := time.Now()
+	output, err := cmd.CombinedOutput()
+	e.recordCommand(ctx, cmdStr, started, err)
+	if err != nil {
+		return fmt.Errorf("failed to rename database %s to %s: %w. Output: %s", oldName, newName, err, string(output))
+	}
+	if !strings.Contains(string(output), "UPDATE 1") {
+		return fmt.Errorf("failed to rename database %s to %s: no such database. Output: %s", oldName, newName, string(output))
+	}
+	return nil
+}
+
 // ListSyncs returns a slice of all sync names currently configured in Bucardo.
 func (e *CLIExecutor) ListSyncs(ctx context.Context) ([]string, error) {
 	re := regexp.MustCompile(`Sync "([^"]+)"`)
@@ -230,7 +431,9 @@ func (e *CLIExecutor) SyncExists(ctx context.Context, syncName string) (bool, []
 	var outb, errb strings.Builder
 	cmd.Stdout = &outb
 	cmd.Stderr = &errb
+	started := time.Now()
 	err := cmd.Run()
+	e.recordCommand(ctx, cmd.String(), started, err)
 
 	stdoutString := outb.String()
 	// Bucardo can return exit 0 even if the sync is not found, usually printing "No such sync" or similar.
@@ -238,9 +441,10 @@ func (e *CLIExecutor) SyncExists(ctx context.Context, syncName string) (bool, []
 	return exists, []byte(stdoutString), nil
 }
 
-// GetSyncRelgroup parses the output of `bucardo list sync` to find the relgroup name.
+// GetSyncRelgroup parses the output of `bucardo list sync` to find the relgroup name. Both
+// the summary line (Relgroup "name") and the detail form (Relgroup: name) are understood.
 func (e *CLIExecutor) GetSyncRelgroup(_ context.Context, syncDetailsOutput []byte) (string, error) {
-	re := regexp.MustCompile(`Relgroup: (\S+)`)
+	re := regexp.MustCompile(`Relgroup(?::\s*|\s+")([^"\s]+)`)
 	matches := re.FindStringSubmatch(string(syncDetailsOutput))
 	if len(matches) < 2 {
 		return "", fmt.Errorf("could not find relgroup in sync details")
@@ -281,7 +485,7 @@ func (e *CLIExecutor) RemoveSyncAndRelgroup(ctx context.Context, syncName, relgr
 	// 1. Try standard CLI removal
 	cliErr := e.runBucardoCommand(ctx, "del", "sync", syncName, "--force")
 	if cliErr != nil {
-		e.logger.Warn("Standard 'del sync' failed, attempting direct SQL cleanup as fallback", "error", cliErr)
+		e.logger.WithContext(ctx).Warn("Standard 'del sync' failed, attempting direct SQL cleanup as fallback", "error", cliErr)
 		
 		// 2. Fallback: Direct SQL deletion
 		// We delete from bucardo.sync (which cascades to dependent objects usually, but we be specific)
@@ -291,14 +495,16 @@ func (e *CLIExecutor) RemoveSyncAndRelgroup(ctx context.Context, syncName, relgr
 		cmdStr := fmt.Sprintf("PGPASSWORD=%s psql -h %s -p %d -U %s -d bucardo -c \"%s\"", dbPass, dbHost, dbPort, dbUser, sql)
 		cmd := exec.CommandContext(ctx, "su", "-", e.bucardoUser, "-c", cmdStr)
 		
+		started := time.Now()
 		output, sqlErr := cmd.CombinedOutput()
+		e.recordCommand(ctx, cmdStr, started, sqlErr)
 		if sqlErr != nil {
-			e.logger.Error("Fallback SQL cleanup also failed", "error", sqlErr, "output", string(output))
+			e.logger.WithContext(ctx).Error("Fallback SQL cleanup also failed", "error", sqlErr, "output", string(output))
 			// Return the original CLI error as it's likely the root cause investigation point, 
 			// but logged the SQL error too.
 			return cliErr 
 		}
-		e.logger.Info("Fallback SQL cleanup succeeded")
+		e.logger.WithContext(ctx).Info("Fallback SQL cleanup succeeded")
 	}
 
 	// 3. Cleanup Relgroup (Best effort via CLI, might have been deleted by SQL above)
@@ -310,21 +516,24 @@ func (e *CLIExecutor) RemoveSyncAndRelgroup(ctx context.Context, syncName, relgr
 
 // StartBucardo starts the main Bucardo process.
 func (e *CLIExecutor) StartBucardo(ctx context.Context) error {
-	e.logger.Info("Checking for and stopping any stale Bucardo processes...")
+	e.logger.WithContext(ctx).Info("Checking for and stopping any stale Bucardo processes...")
 	if err := e.StopBucardo(ctx); err != nil {
-		e.logger.Warn("Pre-start stop command failed, continuing anyway...", "error", err)
+		e.logger.WithContext(ctx).Warn("Pre-start stop command failed, continuing anyway...", "error", err)
 	}
-	e.logger.Info("Starting main Bucardo service", "component", "bucardo_service")
+	e.logger.WithContext(ctx).Info("Starting main Bucardo service", "component", "bucardo_service")
 	return e.runBucardoCommand(ctx, "start")
 }
 
 // StopBucardo gracefully stops the Bucardo service.
 func (e *CLIExecutor) StopBucardo(ctx context.Context) error {
-	e.logger.Info("Stopping main Bucardo service", "component", "bucardo_service")
+	e.logger.WithContext(ctx).Info("Stopping main Bucardo service", "component", "bucardo_service")
 	if err := e.runBucardoCommand(ctx, "stop"); err != nil {
-		e.logger.Warn("'bucardo stop' command failed", "error", err)
+		e.logger.WithContext(ctx).Warn("'bucardo stop' command failed", "error", err)
 	}
 
+	if e.pidPath == "" {
+		return nil
+	}
 	const shutdownTimeout = 30 * time.Second
 	deadline := time.Now().Add(shutdownTimeout)
 
@@ -333,8 +542,8 @@ func (e *CLIExecutor) StopBucardo(ctx context.Context) error {
 		case <-ctx.Done():
 			return ctx.Err()
 		default:
-			if _, err := os.Stat("/var/run/bucardo/bucardo.mcp.pid"); os.IsNotExist(err) {
-				e.logger.Info("Bucardo has stopped.")
+			if _, err := os.Stat(e.pidPath); os.IsNotExist(err) {
+				e.logger.WithContext(ctx).Info("Bucardo has stopped.")
 				return nil
 			}
 			time.Sleep(1 * time.Second)
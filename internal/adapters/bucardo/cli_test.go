@@ -0,0 +1,465 @@
+package bucardo
+
+import (
+	"bytes"
+	"context"
+	"flag"
+	"io"
+	"log/slog"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"replication-service/internal/adapters/audit"
+	"replication-service/internal/adapters/logger"
+	"replication-service/internal/core/domain"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden command transcripts")
+
+// harness runs the adapters against the stand-in bucardo, psql and su executables in
+// testdata/standin, which replay the recordings of a scenario in testdata/golden.
+type harness struct {
+	dir     string
+	golden  string
+	pidPath string
+	audit   *audit.JSONLLog
+}
+
+func newHarness(t *testing.T, scenario string) *harness {
+	t.Helper()
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("the stand-in executables need a POSIX shell")
+	}
+	standin, err := filepath.Abs("testdata/standin")
+	if err != nil {
+		t.Fatal(err)
+	}
+	golden, err := filepath.Abs(filepath.Join("testdata/golden", scenario))
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir := t.TempDir()
+	h := &harness{
+		dir:     dir,
+		golden:  golden,
+		pidPath: filepath.Join(dir, "bucardo.mcp.pid"),
+		audit:   audit.NewJSONLLog(testLogger(), filepath.Join(dir, "audit.jsonl")),
+	}
+	t.Setenv("PATH", standin+string(os.PathListSeparator)+os.Getenv("PATH"))
+	t.Setenv("STANDIN_GOLDEN", golden)
+	t.Setenv("STANDIN_LOG", filepath.Join(dir, "commands.log"))
+	t.Setenv("STANDIN_PID_FILE", h.pidPath)
+	return h
+}
+
+func testLogger() *logger.SlogAdapter {
+	return logger.NewSlogAdapter(slog.New(slog.NewTextHandler(io.Discard, nil)))
+}
+
+func (h *harness) executor() *CLIExecutor {
+	return NewCLIExecutor(testLogger(), h.audit, logger.NewRedactor(), "postgres", "bucardo", h.pidPath)
+}
+
+// commands returns the command lines the stand-ins were run with.
+func (h *harness) commands(t *testing.T) []string {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join(h.dir, "commands.log"))
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	return strings.Split(strings.TrimSuffix(string(data), "\n"), "\n")
+}
+
+// exitCodes returns the exit codes of the audited commands, oldest first; -1 stands for a
+// command that could not be started.
+func (h *harness) exitCodes(t *testing.T) []int {
+	t.Helper()
+	records, err := h.audit.Query(context.Background(), domain.AuditQuery{Kind: domain.AuditKindCommand})
+	if err != nil {
+		t.Fatal(err)
+	}
+	codes := make([]int, len(records))
+	for i, r := range records {
+		codes[len(records)-1-i] = -1 // Query returns the newest first.
+		if r.ExitCode != nil {
+			codes[len(records)-1-i] = *r.ExitCode
+		}
+	}
+	return codes
+}
+
+// checkTranscript compares the commands run with the golden transcript name in the
+// scenario directory; go test -update rewrites it.
+func (h *harness) checkTranscript(t *testing.T, name string) {
+	t.Helper()
+	got := strings.Join(h.commands(t), "\n") + "\n"
+	path := filepath.Join(h.golden, name)
+	if *update {
+		if err := os.WriteFile(path, []byte(got), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("%v (run go test -update to create it)", err)
+	}
+	if got != string(want) {
+		t.Errorf("commands differ from %s:\ngot:\n%swant:\n%s", name, got, want)
+	}
+}
+
+func TestListDatabases(t *testing.T) {
+	tests := []struct {
+		scenario string
+		want     []string
+		wantErr  string
+	}{
+		{scenario: "bucardo-5.6", want: []string{"db1", "db2", "db3"}},
+		{scenario: "empty", want: []string{}},
+		{scenario: "malformed", want: []string{}},
+		{scenario: "unreachable", wantErr: "Connection refused"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.scenario, func(t *testing.T) {
+			h := newHarness(t, tt.scenario)
+			got, err := h.executor().ListDatabases(context.Background())
+			checkResult(t, got, err, tt.want, tt.wantErr)
+		})
+	}
+}
+
+func TestListSyncs(t *testing.T) {
+	tests := []struct {
+		scenario string
+		want     []string
+		wantErr  string
+	}{
+		{scenario: "bucardo-5.6", want: []string{"orders", "mesh", "reporting"}},
+		{scenario: "empty", want: []string{}},
+		{scenario: "malformed", want: []string{}},
+		{scenario: "unreachable", wantErr: "exit status 255"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.scenario, func(t *testing.T) {
+			h := newHarness(t, tt.scenario)
+			got, err := h.executor().ListSyncs(context.Background())
+			checkResult(t, got, err, tt.want, tt.wantErr)
+			if tt.wantErr != "" {
+				if codes := h.exitCodes(t); !reflect.DeepEqual(codes, []int{255}) {
+					t.Errorf("audited exit codes = %v, want [255]", codes)
+				}
+			}
+		})
+	}
+}
+
+func TestSyncDetails(t *testing.T) {
+	tests := []struct {
+		scenario    string
+		sync        string
+		wantExists  bool
+		wantGroup   string // Empty when the relgroup cannot be parsed.
+		wantTables  []string
+		wantListErr bool
+	}{
+		{scenario: "bucardo-5.6", sync: "orders", wantExists: true, wantGroup: "orders", wantTables: []string{"public.customers", "public.orders", "sales.invoices"}},
+		{scenario: "bucardo-5.6", sync: "missing"},
+		{scenario: "empty", sync: "orders"},
+		{scenario: "malformed", sync: "orders", wantExists: true, wantTables: []string{}},
+		{scenario: "unreachable", sync: "orders", wantListErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.scenario+"/"+tt.sync, func(t *testing.T) {
+			ctx := context.Background()
+			e := newHarness(t, tt.scenario).executor()
+
+			exists, details, err := e.SyncExists(ctx, tt.sync)
+			if err != nil || exists != tt.wantExists {
+				t.Fatalf("SyncExists() = %t, %v, want %t", exists, err, tt.wantExists)
+			}
+			group, err := e.GetSyncRelgroup(ctx, details)
+			if group != tt.wantGroup || (err == nil) != (tt.wantGroup != "") {
+				t.Errorf("GetSyncRelgroup() = %q, %v, want %q", group, err, tt.wantGroup)
+			}
+			// The orchestrator falls back to the sync name when the relgroup is unknown.
+			tables, err := e.GetSyncTables(ctx, tt.sync)
+			if tt.wantListErr {
+				if err == nil {
+					t.Errorf("GetSyncTables() succeeded, want an error")
+				}
+				return
+			}
+			if tt.wantTables != nil && (err != nil || !reflect.DeepEqual(tables, tt.wantTables)) {
+				t.Errorf("GetSyncTables() = %v, %v, want %v", tables, err, tt.wantTables)
+			}
+		})
+	}
+}
+
+func TestGetSyncRelgroupFormats(t *testing.T) {
+	e := NewCLIExecutor(testLogger(), nil, logger.NewRedactor(), "postgres", "bucardo", "")
+	for _, details := range []string{
+		"Sync \"orders\"  Relgroup \"orders_rg\"  [Active]\n",
+		"Sync: orders\nRelgroup: orders_rg\n",
+	} {
+		if got, err := e.GetSyncRelgroup(context.Background(), []byte(details)); err != nil || got != "orders_rg" {
+			t.Errorf("GetSyncRelgroup(%q) = %q, %v, want orders_rg", details, got, err)
+		}
+	}
+}
+
+func TestKickSync(t *testing.T) {
+	tests := []struct {
+		scenario     string
+		sync         string
+		timeout      int
+		wantOutcome  string
+		wantState    string
+		wantInserted int // -1 when no row counts are reported.
+		wantErr      string
+	}{
+		{scenario: "bucardo-5.6", sync: "orders", timeout: 10, wantOutcome: domain.SyncRunDone, wantState: "Good", wantInserted: 42},
+		{scenario: "bucardo-5.6", sync: "orders", wantOutcome: domain.SyncRunKicked, wantInserted: -1},
+		{scenario: "bucardo-5.6", sync: "slow", timeout: 5, wantOutcome: domain.SyncRunTimeout, wantState: "Stalled", wantInserted: 0},
+		{scenario: "bucardo-5.6", sync: "missing", timeout: 5, wantErr: "bucardo does not know sync missing"},
+		{scenario: "malformed", sync: "orders", timeout: 10, wantOutcome: domain.SyncRunUnknown, wantInserted: -1},
+		{scenario: "malformed", sync: "garbled", timeout: 10, wantOutcome: domain.SyncRunUnknown, wantInserted: -1},
+		{scenario: "unreachable", sync: "orders", wantErr: "failed to kick sync orders: exit status 255"},
+		{scenario: "unreachable", sync: "orders", timeout: 10, wantOutcome: domain.SyncRunFailed, wantInserted: -1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.scenario+"/"+tt.sync, func(t *testing.T) {
+			result, err := newHarness(t, tt.scenario).executor().KickSync(context.Background(), tt.sync, tt.timeout)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("KickSync() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("KickSync() error = %v", err)
+			}
+			if result.Outcome != tt.wantOutcome || result.State != tt.wantState {
+				t.Errorf("outcome, state = %q, %q, want %q, %q", result.Outcome, result.State, tt.wantOutcome, tt.wantState)
+			}
+			inserted := -1
+			if result.RowsInserted != nil {
+				inserted = *result.RowsInserted
+			}
+			if inserted != tt.wantInserted {
+				t.Errorf("rows inserted = %d, want %d", inserted, tt.wantInserted)
+			}
+		})
+	}
+}
+
+func TestGetSyncStatus(t *testing.T) {
+	e := newHarness(t, "bucardo-5.6").executor()
+	result, err := e.GetSyncStatus(context.Background(), "orders")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.State != "Good" || result.RowsDeleted == nil || *result.RowsDeleted != 3 || *result.RowsInserted != 42 {
+		t.Errorf("GetSyncStatus() = %+v, want state Good and 3/42 rows", result)
+	}
+
+	e = newHarness(t, "unreachable").executor()
+	if _, err := e.GetSyncStatus(context.Background(), "orders"); err == nil || !strings.Contains(err.Error(), "Connection refused") {
+		t.Errorf("GetSyncStatus() error = %v, want the connection error", err)
+	}
+}
+
+func TestInstallBucardo(t *testing.T) {
+	ctx := context.Background()
+
+	// An operational Bucardo is left alone.
+	h := newHarness(t, "bucardo-5.6")
+	if err := h.executor().InstallBucardo(ctx, "bucardo", "postgres", "postgres", "pw"); err != nil {
+		t.Fatal(err)
+	}
+	if got := h.commands(t); !reflect.DeepEqual(got, []string{"bucardo list dbs"}) {
+		t.Errorf("commands = %q, want only the check", got)
+	}
+
+	h = newHarness(t, "fresh-install")
+	if err := h.executor().InstallBucardo(ctx, "bucardo", "postgres", "postgres", "pw"); err != nil {
+		t.Fatal(err)
+	}
+	h.checkTranscript(t, "install.commands")
+	if codes := h.exitCodes(t); !reflect.DeepEqual(codes, []int{255, 0}) {
+		t.Errorf("audited exit codes = %v, want [255 0]", codes)
+	}
+}
+
+func TestEnsureBucardoUserPassword(t *testing.T) {
+	tests := []struct {
+		scenario string
+		wantErr  string
+	}{
+		{scenario: "bucardo-5.6"},
+		{scenario: "empty"}, // The role does not exist before the install.
+		{scenario: "unreachable", wantErr: "password authentication failed"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.scenario, func(t *testing.T) {
+			h := newHarness(t, tt.scenario)
+			err := h.executor().EnsureBucardoUserPassword(context.Background(), "postgres", "postgres", "superpw", "bucardo", "bucardopw", 5432)
+			if (err != nil || tt.wantErr != "") && (err == nil || !strings.Contains(err.Error(), tt.wantErr)) {
+				t.Errorf("EnsureBucardoUserPassword() error = %v, want %q", err, tt.wantErr)
+			}
+			want := []string{`psql -h postgres -p 5432 -U postgres -d postgres -c ALTER USER bucardo WITH PASSWORD 'bucardopw';`}
+			if got := h.commands(t); !reflect.DeepEqual(got, want) {
+				t.Errorf("commands = %q, want %q", got, want)
+			}
+		})
+	}
+}
+
+func TestRemoveSyncAndRelgroup(t *testing.T) {
+	tests := []struct {
+		scenario string
+		wantErr  bool
+	}{
+		{scenario: "bucardo-5.6"},
+		{scenario: "empty"}, // 'del sync' fails; the SQL fallback cleans up.
+		{scenario: "unreachable", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.scenario, func(t *testing.T) {
+			h := newHarness(t, tt.scenario)
+			err := h.executor().RemoveSyncAndRelgroup(context.Background(), "orders", "orders", "postgres", "postgres", "superpw", 5432)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("RemoveSyncAndRelgroup() error = %v, want error %t", err, tt.wantErr)
+			}
+			h.checkTranscript(t, "remove-sync.commands")
+		})
+	}
+}
+
+func TestRenameDatabase(t *testing.T) {
+	tests := []struct {
+		scenario string
+		wantErr  string
+	}{
+		{scenario: "bucardo-5.6"},
+		{scenario: "empty", wantErr: "no such database"}, // UPDATE 0
+		{scenario: "unreachable", wantErr: "password authentication failed"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.scenario, func(t *testing.T) {
+			h := newHarness(t, tt.scenario)
+			err := h.executor().RenameDatabase(context.Background(), "db1", "orders_primary", "postgres", "postgres", "superpw", 5432)
+			if tt.wantErr == "" && err != nil || tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)) {
+				t.Errorf("RenameDatabase() error = %v, want %q", err, tt.wantErr)
+			}
+			h.checkTranscript(t, "rename-db.commands")
+		})
+	}
+}
+
+func TestRunCommandRedactsOutput(t *testing.T) {
+	h := newHarness(t, "empty")
+	golden := t.TempDir()
+	t.Setenv("STANDIN_GOLDEN", golden)
+	for name, content := range map[string]string{
+		"add_db.out": "Added database db1 with PGPASSWORD=hunter2\n",
+		"add_db.err": "connecting as app with s3cret-pass failed\nNo such dbgroup: db1\n",
+	} {
+		if err := os.WriteFile(filepath.Join(golden, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	redactor := logger.NewRedactor()
+	redactor.AddSecret("s3cret-pass")
+	var logs bytes.Buffer
+	e := NewCLIExecutor(logger.NewSlogAdapter(slog.New(redactor.Handler(slog.NewTextHandler(&logs, nil)))), h.audit, redactor, "postgres", "bucardo", h.pidPath)
+
+	// Nothing may reach the process's own stdout and stderr around the logger.
+	stdout, stderr := os.Stdout, os.Stderr
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	os.Stdout, os.Stderr = w, w
+	err = e.ExecuteBucardoCommand(context.Background(), "add", "db", "db1", "dbpass=s3cret-pass")
+	os.Stdout, os.Stderr = stdout, stderr
+	w.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if direct, _ := io.ReadAll(r); len(direct) != 0 {
+		t.Errorf("command output bypassed the logger: %q", direct)
+	}
+
+	for _, want := range []string{
+		`msg="Added database db1 with PGPASSWORD=*****" component=command_runner stream=stdout`,
+		`level=WARN msg="connecting as app with ***** failed" component=command_runner stream=stderr`,
+	} {
+		if !strings.Contains(logs.String(), want) {
+			t.Errorf("logged:\n%s\nwant a line containing %s", logs.String(), want)
+		}
+	}
+	for _, unwanted := range []string{"hunter2", "s3cret-pass", "No such dbgroup"} {
+		if strings.Contains(logs.String(), unwanted) {
+			t.Errorf("logged:\n%s\nwhich contains %q", logs.String(), unwanted)
+		}
+	}
+	records, err := h.audit.Query(context.Background(), domain.AuditQuery{Kind: domain.AuditKindCommand})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 1 || records[0].Command != "bucardo add db db1 dbpass=*****" {
+		t.Errorf("audit records = %+v, want the redacted command", records)
+	}
+}
+
+func TestStartStopBucardo(t *testing.T) {
+	ctx := context.Background()
+	h := newHarness(t, "bucardo-5.6")
+	e := h.executor()
+
+	if err := e.StartBucardo(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(h.pidPath); err != nil {
+		t.Fatalf("pid file after start: %v", err)
+	}
+	if err := e.StopBucardo(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(h.pidPath); !os.IsNotExist(err) {
+		t.Errorf("pid file still exists after stop: %v", err)
+	}
+	want := []string{"bucardo stop", "bucardo start", "bucardo stop"}
+	if got := h.commands(t); !reflect.DeepEqual(got, want) {
+		t.Errorf("commands = %q, want %q", got, want)
+	}
+
+	h = newHarness(t, "unreachable")
+	if err := h.executor().StartBucardo(ctx); err == nil {
+		t.Error("StartBucardo() succeeded against an unreachable database")
+	}
+}
+
+func checkResult(t *testing.T, got []string, err error, want []string, wantErr string) {
+	t.Helper()
+	if wantErr != "" {
+		if err == nil || !strings.Contains(err.Error(), wantErr) {
+			t.Fatalf("error = %v, want %q", err, wantErr)
+		}
+		return
+	}
+	if err != nil {
+		t.Fatalf("error = %v", err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
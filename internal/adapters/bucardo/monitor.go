@@ -1,16 +1,19 @@
 package bucardo
 
 import (
-	"bufio"
 	"context"
 	"fmt"
 	"os"
-	"os/exec"
 	"os/signal"
+	"regexp"
+	"sort"
 	"strings"
+	"sync"
 	"syscall"
 	"time"
 
+	logadapter "replication-service/internal/adapters/logger"
+	"replication-service/internal/adapters/logtail"
 	"replication-service/internal/core/domain"
 	"replication-service/internal/core/ports"
 )
@@ -18,30 +21,54 @@ import (
 // MonitorAdapter implements the Monitor port for observing Bucardo.
 type MonitorAdapter struct {
 	logger         ports.Logger
+	notifier       ports.Notifier
+	audit          ports.AuditLog
+	redactor       *logadapter.Redactor
 	bucardoLogPath string
 	bucardoUser    string
 	bucardoCmd     string
+
+	follower   *logtail.Follower
+	followOnce sync.Once
+	lines      chan string
 }
 
-// NewMonitorAdapter creates a new MonitorAdapter.
-func NewMonitorAdapter(logger ports.Logger, logPath, user, cmd string) *MonitorAdapter {
+// NewMonitorAdapter creates a new MonitorAdapter. The position in the Bucardo log that
+// monitoring starts from is taken here, so it should be created before Bucardo starts.
+// logOffsetPath stores the read offset across restarts; it may be empty.
+func NewMonitorAdapter(logger ports.Logger, notifier ports.Notifier, audit ports.AuditLog, redactor *logadapter.Redactor, logPath, logOffsetPath, user, cmd string) *MonitorAdapter {
 	return &MonitorAdapter{
 		logger:         logger,
+		notifier:       notifier,
+		audit:          audit,
+		redactor:       redactor,
 		bucardoLogPath: logPath,
 		bucardoUser:    user,
 		bucardoCmd:     cmd,
+		follower:       logtail.NewFollower(logger, logPath, logOffsetPath),
+		lines:          make(chan string, 1024),
 	}
 }
 
 // MonitorBucardo handles the default long-running mode.
 func (m *MonitorAdapter) MonitorBucardo(ctx context.Context, stopFunc func()) {
-	tailCmd := m.streamBucardoLog(ctx)
-	if tailCmd != nil && tailCmd.Process != nil {
-		defer func() {
-			m.logger.Info("Stopping log streamer", "component", "log_streamer")
-			syscall.Kill(-tailCmd.Process.Pid, syscall.SIGKILL)
-		}()
-	}
+	done := make(chan struct{})
+	defer close(done)
+	lines := m.logLines(ctx)
+	go func() {
+		for {
+			select {
+			case line, ok := <-lines:
+				if !ok {
+					return
+				}
+				m.handleLogLine(ctx, line)
+			case <-done:
+				m.logger.Info("Stopping log streamer", "component", "log_streamer")
+				return
+			}
+		}
+	}()
 
 	sigChan := make(chan os.Signal, 1)
 	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
@@ -57,135 +84,181 @@ func (m *MonitorAdapter) MonitorBucardo(ctx context.Context, stopFunc func()) {
 	}
 }
 
-// MonitorSyncs handles the "run-once" mode by tailing the Bucardo log for completion.
-func (m *MonitorAdapter) MonitorSyncs(ctx context.Context, config *domain.BucardoConfig, runOnceSyncs map[string]bool, maxTimeout *int, stopBucardoFunc func()) {
+// MonitorSyncs handles the "run-once" mode by following the Bucardo log for completion.
+// Each sync has its own deadline; the method returns once every sync has completed,
+// timed out or ctx is cancelled, leaving it to the caller to stop Bucardo.
+func (m *MonitorAdapter) MonitorSyncs(ctx context.Context, config *domain.BucardoConfig, timeouts map[string]int) *domain.RunOnceSummary {
 	if config.LogLevel != "VERBOSE" && config.LogLevel != "DEBUG" {
 		m.logger.Warn("'exit_on_complete' is true, but 'log_level' is not 'VERBOSE' or 'DEBUG'. The completion message may not be logged.")
 	}
 
-	m.logger.Info("Monitoring sync(s) for completion", "count", len(runOnceSyncs), "syncs", getMapKeys(runOnceSyncs))
-
-	allSyncsAreRunOnce := len(config.Syncs) == len(runOnceSyncs)
-	var timeoutChannel <-chan time.Time
-
-	// Setup log tailing
-	cmd := exec.CommandContext(ctx, "tail", "-F", m.bucardoLogPath)
-	stdout, err := cmd.StdoutPipe()
-	if err != nil {
-		m.logger.Error("Could not create pipe for tail command", "error", err)
-		os.Exit(1) // This is a fatal startup error
+	startedAt := time.Now()
+	pending := make(map[string]bool, len(timeouts))
+	results := make(map[string]*domain.RunOnceSyncResult, len(timeouts))
+	for syncName, timeout := range timeouts {
+		pending[syncName] = true
+		results[syncName] = &domain.RunOnceSyncResult{SyncName: syncName, TimeoutSeconds: timeout}
+	}
+	finish := func(syncName, state string) {
+		results[syncName].State = state
+		results[syncName].DurationMs = time.Since(startedAt).Milliseconds()
+		delete(pending, syncName)
 	}
-	cmd.Stderr = os.Stderr
-	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
-	defer func() {
-		if cmd.Process != nil {
-			syscall.Kill(-cmd.Process.Pid, syscall.SIGKILL)
-			cmd.Wait()
+	summarize := func() *domain.RunOnceSummary {
+		for syncName := range pending {
+			finish(syncName, domain.RunOnceCancelled)
 		}
-	}()
-
-	if err := cmd.Start(); err != nil {
-		m.logger.Error("Could not start log tailing command", "error", err)
-		os.Exit(1) // Fatal
+		return newRunOnceSummary(startedAt, results)
 	}
 
-	if maxTimeout != nil && *maxTimeout > 0 {
-		timeoutDuration := time.Duration(*maxTimeout) * time.Second
-		m.logger.Info("Setting a timeout for run-once sync completion", "timeout", timeoutDuration)
-		timeoutChannel = time.After(timeoutDuration)
-	}
+	m.logger.Info("Monitoring sync(s) for completion", "count", len(pending), "syncs", getMapKeys(pending))
 
-	lineChan := make(chan string)
-	go func() {
-		defer close(lineChan)
-		scanner := bufio.NewScanner(stdout)
-		for scanner.Scan() {
-			select {
-			case lineChan <- scanner.Text():
-			case <-ctx.Done():
-				return
-			}
+	// Every sync with a timeout gets its own timer; expired syncs are reported on this channel.
+	expired := make(chan string, len(timeouts))
+	for syncName, timeout := range timeouts {
+		if timeout <= 0 {
+			continue
 		}
-	}()
+		syncName := syncName
+		m.logger.Info("Setting a timeout for run-once sync completion", "sync_name", syncName, "timeout", time.Duration(timeout)*time.Second)
+		timer := time.AfterFunc(time.Duration(timeout)*time.Second, func() { expired <- syncName })
+		defer timer.Stop()
+	}
 
-	bucardoExecutor := NewCLIExecutor(m.logger, m.bucardoUser, m.bucardoCmd)
+	lineChan := m.logLines(ctx)
+	// Only used to stop single syncs, so there is no Bucardo pid file to wait for.
+	bucardoExecutor := NewCLIExecutor(m.logger, m.audit, m.redactor, m.bucardoUser, m.bucardoCmd, "")
 
-	for {
+	for len(pending) > 0 {
 		select {
 		case line, ok := <-lineChan:
 			if !ok {
 				m.logger.Info("Log streaming finished unexpectedly.")
-				return
+				return summarize()
 			}
-			// Log the line to ensure it goes to the websocket/stdout via the multiwriter
-			m.logger.Info(line, "component", "bucardo_log")
+			m.handleLogLine(ctx, line)
 
 			if strings.Contains(line, "Reason: Normal exit") {
-				for syncName := range runOnceSyncs {
+				for syncName := range pending {
 					if strings.Contains(line, fmt.Sprintf("KID (%s)", syncName)) {
 						m.logger.Info("Completion message for sync detected", "sync_name", syncName)
+						finish(syncName, domain.RunOnceCompleted)
+						m.notifier.Notify(ctx, domain.Event{
+							Type:     domain.EventRunOnceCompleted,
+							Severity: domain.SeverityInfo,
+							SyncName: syncName,
+							Message:  fmt.Sprintf("Run-once sync %s completed", syncName),
+						})
 						if err := bucardoExecutor.ExecuteBucardoCommand(ctx, "stop", syncName); err != nil {
 							m.logger.Warn("Failed to stop sync after completion", "error", err, "sync_name", syncName)
 						}
-						delete(runOnceSyncs, syncName)
-						m.logger.Info("Run-once sync(s) remaining", "count", len(runOnceSyncs))
+						m.logger.Info("Run-once sync(s) remaining", "count", len(pending))
 					}
 				}
 			}
-
-			if len(runOnceSyncs) == 0 {
-				m.logger.Info("All monitored syncs have completed.")
-				if allSyncsAreRunOnce {
-					m.logger.Info("All configured syncs were run-once. Shutting down container.")
-					stopBucardoFunc()
-					return
-				}
-				m.logger.Info("Other syncs are still running. Switching to standard monitoring mode.")
-				m.MonitorBucardo(ctx, stopBucardoFunc)
-				return
+		case syncName := <-expired:
+			if !pending[syncName] {
+				continue
 			}
-		case <-timeoutChannel:
-			m.logger.Error("Timeout reached for run-once syncs", "timeout_seconds", *maxTimeout, "incomplete_syncs", getMapKeys(runOnceSyncs))
-			stopBucardoFunc()
-			os.Exit(1)
+			m.logger.Error("Timeout reached for run-once sync", "sync_name", syncName, "timeout_seconds", timeouts[syncName])
+			finish(syncName, domain.RunOnceTimedOut)
+			m.notifier.Notify(ctx, domain.Event{
+				Type:     domain.EventRunOnceTimedOut,
+				Severity: domain.SeverityError,
+				SyncName: syncName,
+				Message:  fmt.Sprintf("Run-once sync %s did not complete within %d seconds", syncName, timeouts[syncName]),
+			})
 		case <-ctx.Done():
 			m.logger.Info("Context cancelled during sync monitoring.")
-			stopBucardoFunc()
-			return
+			return summarize()
 		}
 	}
+
+	m.logger.Info("All monitored syncs have finished.")
+	return summarize()
 }
 
-func (m *MonitorAdapter) streamBucardoLog(ctx context.Context) *exec.Cmd {
-	time.Sleep(2 * time.Second)
-	cmd := exec.CommandContext(ctx, "tail", "-F", m.bucardoLogPath)
-	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
-	
-	// Capture stdout to pipe it through our logger (so it goes to WebSocket)
-	stdout, err := cmd.StdoutPipe()
-	if err != nil {
-		m.logger.Error("Could not create pipe for tail command", "error", err)
-		return nil
+// newRunOnceSummary builds the summary of a run-once monitoring session from its per-sync results.
+func newRunOnceSummary(startedAt time.Time, results map[string]*domain.RunOnceSyncResult) *domain.RunOnceSummary {
+	summary := &domain.RunOnceSummary{
+		StartedAt:  startedAt,
+		FinishedAt: time.Now(),
+		Syncs:      make([]domain.RunOnceSyncResult, 0, len(results)),
 	}
-	cmd.Stderr = os.Stderr
+	summary.DurationMs = summary.FinishedAt.Sub(startedAt).Milliseconds()
 
-	m.logger.Info("Streaming Bucardo log file", "path", m.bucardoLogPath)
-	if err := cmd.Start(); err != nil {
-		m.logger.Warn("Could not start streaming Bucardo log file", "error", err)
-		return nil
+	var completed, timedOut, cancelled int
+	for _, r := range results {
+		summary.Syncs = append(summary.Syncs, *r)
+		switch r.State {
+		case domain.RunOnceCompleted:
+			completed++
+		case domain.RunOnceTimedOut:
+			timedOut++
+		case domain.RunOnceCancelled:
+			cancelled++
+		}
 	}
+	sort.Slice(summary.Syncs, func(i, j int) bool { return summary.Syncs[i].SyncName < summary.Syncs[j].SyncName })
 
-	// Consume the log stream in a background goroutine
-	go func() {
-		scanner := bufio.NewScanner(stdout)
-		for scanner.Scan() {
-			// We log it as INFO so it appears in the standard log stream/websocket
-			// We use a specific component tag to distinguish it
-			m.logger.Info(scanner.Text(), "component", "bucardo_log")
-		}
-	}()
+	switch {
+	case completed == len(results):
+		summary.Outcome = domain.RunOnceOutcomeSuccess
+	case cancelled > 0:
+		summary.Outcome = domain.RunOnceOutcomeCancelled
+	case completed == 0 && timedOut == len(results):
+		summary.Outcome = domain.RunOnceOutcomeTimeout
+	default:
+		summary.Outcome = domain.RunOnceOutcomePartial
+	}
+	return summary
+}
+
+// logLines starts following the Bucardo log on first use and returns the channel its lines
+// are delivered on. The channel is closed when the follower stops with ctx.
+func (m *MonitorAdapter) logLines(ctx context.Context) <-chan string {
+	m.followOnce.Do(func() {
+		go func() {
+			defer close(m.lines)
+			m.logger.Info("Streaming Bucardo log file", "path", m.bucardoLogPath)
+			m.follower.Follow(ctx, func(line string) {
+				select {
+				case m.lines <- line:
+				case <-ctx.Done():
+				}
+			})
+		}()
+	})
+	return m.lines
+}
 
-	return cmd
+// handleLogLine forwards a Bucardo log line to the application log and raises events for it.
+func (m *MonitorAdapter) handleLogLine(ctx context.Context, line string) {
+	// Log the line to ensure it goes to the websocket/stdout via the multiwriter
+	m.logger.Info(line, "component", "bucardo_log")
+	m.inspectLogLine(ctx, line)
+}
+
+var (
+	kidDiedRe     = regexp.MustCompile(`(?i)\bkid\b.*\b(has died|died)\b`)
+	logSyncNameRe = regexp.MustCompile(`(?:KID|CTL) \(([^)]+)\)`)
+)
+
+// inspectLogLine raises events for noteworthy Bucardo log lines.
+func (m *MonitorAdapter) inspectLogLine(ctx context.Context, line string) {
+	if !kidDiedRe.MatchString(line) {
+		return
+	}
+	var syncName string
+	if match := logSyncNameRe.FindStringSubmatch(line); match != nil {
+		syncName = match[1]
+	}
+	m.notifier.Notify(ctx, domain.Event{
+		Type:     domain.EventKidDied,
+		Severity: domain.SeverityError,
+		SyncName: syncName,
+		Message:  line,
+	})
 }
 
 func getMapKeys(m map[string]bool) []string {
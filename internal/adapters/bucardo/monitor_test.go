@@ -0,0 +1,96 @@
+package bucardo
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"replication-service/internal/adapters/fake"
+	"replication-service/internal/adapters/logger"
+	"replication-service/internal/core/domain"
+)
+
+func TestMonitorSyncs(t *testing.T) {
+	h := newHarness(t, "bucardo-5.6")
+	logPath := filepath.Join(h.dir, "log.bucardo")
+	if err := os.WriteFile(logPath, []byte("(2800) [Sat Oct 18 10:14:00 2026] MCP Old line, before monitoring started\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	notifier := fake.NewNotifier()
+	monitor := NewMonitorAdapter(testLogger(), notifier, h.audit, logger.NewRedactor(), logPath, "", "postgres", "bucardo")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	summaries := make(chan *domain.RunOnceSummary)
+	go func() {
+		config := &domain.BucardoConfig{LogLevel: "VERBOSE"}
+		summaries <- monitor.MonitorSyncs(ctx, config, map[string]int{"orders": 0, "mesh": 1})
+	}()
+
+	// Bucardo writes the recorded run to its log.
+	recorded, err := os.ReadFile(filepath.Join(h.golden, "bucardo.log"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	f, err := os.OpenFile(logPath, os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.Write(recorded)
+	f.Close()
+
+	summary := <-summaries
+	if summary.Outcome != domain.RunOnceOutcomePartial {
+		t.Errorf("outcome = %q, want partial", summary.Outcome)
+	}
+	states := make(map[string]string)
+	for _, s := range summary.Syncs {
+		states[s.SyncName] = s.State
+	}
+	if want := map[string]string{"orders": domain.RunOnceCompleted, "mesh": domain.RunOnceTimedOut}; !reflect.DeepEqual(states, want) {
+		t.Errorf("states = %v, want %v", states, want)
+	}
+
+	var events []string
+	for _, e := range notifier.Events() {
+		events = append(events, e.Type+":"+e.SyncName)
+	}
+	want := []string{
+		domain.EventRunOnceCompleted + ":orders",
+		domain.EventKidDied + ":mesh",
+		domain.EventRunOnceTimedOut + ":mesh",
+	}
+	if !reflect.DeepEqual(events, want) {
+		t.Errorf("events = %q, want %q", events, want)
+	}
+	// The completed sync is stopped, the rest is left to the caller.
+	if got := h.commands(t); !reflect.DeepEqual(got, []string{"bucardo stop orders"}) {
+		t.Errorf("commands = %q, want the completed sync stopped", got)
+	}
+}
+
+func TestMonitorSyncsCancelled(t *testing.T) {
+	h := newHarness(t, "bucardo-5.6")
+	logPath := filepath.Join(h.dir, "log.bucardo")
+	if err := os.WriteFile(logPath, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	monitor := NewMonitorAdapter(testLogger(), fake.NewNotifier(), h.audit, logger.NewRedactor(), logPath, "", "postgres", "bucardo")
+
+	// The container is stopped before either sync finishes.
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+	summary := monitor.MonitorSyncs(ctx, &domain.BucardoConfig{}, map[string]int{"orders": 0, "mesh": 60})
+
+	if summary.Outcome != domain.RunOnceOutcomeCancelled {
+		t.Errorf("outcome = %q, want cancelled", summary.Outcome)
+	}
+	for _, s := range summary.Syncs {
+		if s.State != domain.RunOnceCancelled {
+			t.Errorf("%s: state = %q, want cancelled", s.SyncName, s.State)
+		}
+	}
+}
@@ -0,0 +1,23 @@
+# Shared by the bucardo and psql stand-ins. replay prints the recording made for a command
+# from $STANDIN_GOLDEN and exits with its status. A recording is named after the words of
+# the command joined by "_", e.g. list_sync_orders; the longest recorded prefix wins, so
+# list_sync answers for every sync without a recording of its own. <name>.out goes to
+# stdout, <name>.err to stderr and <name>.exit holds the exit status (0 when missing).
+# Commands without any recording succeed silently.
+replay() {
+	key=""
+	match=""
+	for word in "$@"; do
+		word=$(printf '%s' "$word" | tr -c 'A-Za-z0-9._=-' '_')
+		key=${key:+${key}_}$word
+		if [ -e "$STANDIN_GOLDEN/$key.out" ] || [ -e "$STANDIN_GOLDEN/$key.err" ] || [ -e "$STANDIN_GOLDEN/$key.exit" ]; then
+			match=$key
+		fi
+	done
+	[ -n "$match" ] || exit 0
+	[ -e "$STANDIN_GOLDEN/$match.out" ] && cat "$STANDIN_GOLDEN/$match.out"
+	[ -e "$STANDIN_GOLDEN/$match.err" ] && cat "$STANDIN_GOLDEN/$match.err" >&2
+	status=0
+	[ -e "$STANDIN_GOLDEN/$match.exit" ] && status=$(cat "$STANDIN_GOLDEN/$match.exit")
+	exit "$status"
+}
@@ -0,0 +1,192 @@
+package bucardo
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"go.opentelemetry.io/otel"
+	coltracepb "go.opentelemetry.io/proto/otlp/collector/trace/v1"
+	"google.golang.org/protobuf/proto"
+
+	"replication-service/internal/adapters/fake"
+	"replication-service/internal/adapters/logger"
+	"replication-service/internal/adapters/tracing"
+	"replication-service/internal/core/domain"
+	"replication-service/internal/core/services/orchestrator"
+)
+
+// collectedSpan is a span as received by the collector, with hex IDs as logged.
+type collectedSpan struct {
+	name, traceID, spanID, parentID string
+}
+
+// collector is an OTLP/HTTP trace receiver.
+type collector struct {
+	mu    sync.Mutex
+	spans []collectedSpan
+}
+
+func (c *collector) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path != "/v1/traces" {
+		http.NotFound(w, r)
+		return
+	}
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	req := &coltracepb.ExportTraceServiceRequest{}
+	if err := proto.Unmarshal(body, req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	c.mu.Lock()
+	for _, resource := range req.GetResourceSpans() {
+		for _, scope := range resource.GetScopeSpans() {
+			for _, span := range scope.GetSpans() {
+				c.spans = append(c.spans, collectedSpan{
+					name:     span.GetName(),
+					traceID:  hex.EncodeToString(span.GetTraceId()),
+					spanID:   hex.EncodeToString(span.GetSpanId()),
+					parentID: hex.EncodeToString(span.GetParentSpanId()),
+				})
+			}
+		}
+	}
+	c.mu.Unlock()
+	w.Header().Set("Content-Type", "application/x-protobuf")
+}
+
+func TestReconcileTrace(t *testing.T) {
+	h := newHarness(t, "empty")
+	received := &collector{}
+	srv := httptest.NewServer(received)
+	defer srv.Close()
+	t.Setenv("OTEL_EXPORTER_OTLP_ENDPOINT", srv.URL)
+
+	previous := otel.GetTracerProvider()
+	shutdown, enabled, err := tracing.Setup(context.Background(), "replication-service-test")
+	if err != nil || !enabled {
+		t.Fatalf("Setup() = %v, %v", enabled, err)
+	}
+	defer otel.SetTracerProvider(previous)
+
+	configPath := filepath.Join(h.dir, "bucardo.json")
+	if err := os.WriteFile(configPath, []byte("{}"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	var logs bytes.Buffer
+	redactor := logger.NewRedactor()
+	appLogger := logger.NewSlogAdapter(slog.New(logger.NewTraceHandler(slog.NewJSONHandler(&logs, nil))))
+	service := orchestrator.NewService(
+		appLogger,
+		fake.NewConfigProvider(&domain.BucardoConfig{
+			Databases: []domain.Database{
+				{ID: 1, DBName: "app", Host: "pg1", User: "replicator", Pass: "s3cret-pass"},
+				{ID: 2, DBName: "app", Host: "pg2", User: "replicator", Pass: "s3cret-pass"},
+			},
+			Syncs: []domain.Sync{
+				{Name: "orders", Sources: []domain.DBRef{"1"}, Targets: []domain.DBRef{"2"}, Tables: "public.orders"},
+			},
+		}),
+		fake.NewCredentialManager(),
+		NewCLIExecutor(appLogger, h.audit, redactor, "postgres", "bucardo", h.pidPath),
+		fake.NewMonitor(),
+		nil,
+		fake.NewNotifier(),
+		tracing.NewOTelTracer(),
+		logger.NewLevelController(slog.LevelInfo),
+		redactor,
+		configPath, "", "postgres", "bucardo", "",
+	)
+	if err := service.ReloadAndRestart(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	// Shutting down flushes the batched spans to the collector.
+	if err := shutdown(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	received.mu.Lock()
+	spans := received.spans
+	received.mu.Unlock()
+	var root collectedSpan
+	for _, span := range spans {
+		if span.name == "reconcile" {
+			root = span
+		}
+	}
+	if root.traceID == "" {
+		t.Fatalf("no reconcile span among %v", spans)
+	}
+	phases := map[string]collectedSpan{}
+	commands := map[string]int{} // Number of bucardo command spans per phase.
+	for _, span := range spans {
+		if span.traceID != root.traceID {
+			t.Errorf("span %q is in trace %s, want %s", span.name, span.traceID, root.traceID)
+		}
+		if span.parentID == root.spanID {
+			phases[span.name] = span
+		}
+	}
+	for _, span := range spans {
+		if span.name != "bucardo command" {
+			continue
+		}
+		for name, phase := range phases {
+			if span.parentID == phase.spanID {
+				commands[name]++
+			}
+		}
+	}
+	for _, name := range []string{
+		"Load configuration", "Stop Bucardo", "Set up credentials", "Install Bucardo", "Rename databases",
+		"Remove orphaned databases and syncs", "Reconcile databases", "Reconcile syncs", "Start Bucardo",
+	} {
+		if _, ok := phases[name]; !ok {
+			t.Errorf("no %q phase span", name)
+		}
+	}
+	for _, name := range []string{"Stop Bucardo", "Install Bucardo", "Reconcile databases", "Reconcile syncs", "Start Bucardo"} {
+		if commands[name] == 0 {
+			t.Errorf("no bucardo command spans in the %q phase", name)
+		}
+	}
+
+	// Lines carry the span of the phase they were logged in.
+	var finished bool
+	for _, line := range strings.Split(strings.TrimSpace(logs.String()), "\n") {
+		var entry struct {
+			Msg     string `json:"msg"`
+			TraceID string `json:"trace_id"`
+			SpanID  string `json:"span_id"`
+		}
+		if err := json.Unmarshal([]byte(line), &entry); err != nil {
+			t.Fatalf("log line %q: %v", line, err)
+		}
+		if entry.TraceID != root.traceID {
+			t.Errorf("log line %q has trace_id %q, want %s", entry.Msg, entry.TraceID, root.traceID)
+		}
+		if entry.Msg == "Reload and restart complete." {
+			finished = true
+			if want := phases["Start Bucardo"].spanID; entry.SpanID != want {
+				t.Errorf("final log line has span_id %q, want the Start Bucardo phase %s", entry.SpanID, want)
+			}
+		}
+	}
+	if !finished {
+		t.Errorf("reconcile did not log its completion:\n%s", logs.String())
+	}
+}
@@ -2,22 +2,49 @@ package config
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
 
 	"replication-service/internal/core/domain"
+	"replication-service/internal/core/ports"
+	"replication-service/internal/core/reqctx"
 )
 
+// maxConfigRevisions is the number of revisions kept in the history directory.
+const maxConfigRevisions = 200
+
 // JSONProvider implements the ports.ConfigProvider interface for JSON files.
 type JSONProvider struct {
-	filePath string
+	filePath   string
+	historyDir string
+	mu         sync.Mutex // Serialises saves so revision numbers are unique.
 }
 
-// NewJSONProvider creates a new JSONProvider.
-func NewJSONProvider(filePath string) *JSONProvider {
-	return &JSONProvider{filePath: filePath}
+// revisionFile is the on-disk form of a revision in the history directory.
+type revisionFile struct {
+	domain.ConfigRevision
+	Config json.RawMessage `json:"config"`
+}
+
+// NewJSONProvider creates a new JSONProvider. Revisions are kept in historyDir, or in a
+// .history directory next to the file when historyDir is empty.
+func NewJSONProvider(filePath, historyDir string) *JSONProvider {
+	if historyDir == "" {
+		historyDir = filepath.Join(filepath.Dir(filePath), ".history")
+	}
+	return &JSONProvider{filePath: filePath, historyDir: historyDir}
 }
 
 // LoadConfig reads and parses the bucardo.json file.
@@ -41,15 +68,203 @@ func (p *JSONProvider) LoadConfig(_ context.Context) (*domain.BucardoConfig, err
 	return &config, nil
 }
 
-// SaveConfig writes the configuration to the bucardo.json file.
-func (p *JSONProvider) SaveConfig(_ context.Context, config *domain.BucardoConfig) error {
+// SaveConfig records the configuration as a new revision and then atomically replaces the
+// bucardo.json file with it. The first save also records the file it replaces, so the
+// configuration the container started with can always be restored.
+func (p *JSONProvider) SaveConfig(ctx context.Context, config *domain.BucardoConfig) error {
 	byteValue, err := json.MarshalIndent(config, "", "  ")
 	if err != nil {
 		return fmt.Errorf("failed to marshal config: %w", err)
 	}
 
-	if err := os.WriteFile(p.filePath, byteValue, 0644); err != nil {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	revisions, err := p.revisionNumbers()
+	if err != nil {
+		return err
+	}
+	last := 0
+	if len(revisions) > 0 {
+		last = revisions[len(revisions)-1]
+	}
+	if expected, ok := reqctx.ExpectedRevision(ctx); ok && expected != last {
+		return fmt.Errorf("%w: expected revision %d, latest is %d", ports.ErrRevisionConflict, expected, last)
+	}
+	if len(revisions) == 0 {
+		if current, err := os.ReadFile(p.filePath); err == nil && json.Valid(current) {
+			last = 1
+			initial := domain.ConfigRevision{Revision: last, Actor: reqctx.SystemActor, Message: "Configuration before the first recorded change"}
+			if info, err := os.Stat(p.filePath); err == nil {
+				initial.Time = info.ModTime()
+			}
+			if err := p.writeRevision(initial, current); err != nil {
+				return err
+			}
+		}
+	}
+
+	revision := domain.ConfigRevision{
+		Revision: last + 1,
+		Time:     time.Now(),
+		Actor:    reqctx.Actor(ctx),
+		Message:  reqctx.ChangeMessage(ctx),
+	}
+	if err := p.writeRevision(revision, byteValue); err != nil {
+		return err
+	}
+	if err := writeFileAtomic(p.filePath, byteValue); err != nil {
+		os.Remove(p.revisionPath(revision.Revision))
 		return fmt.Errorf("failed to write to %s: %w", p.filePath, err)
 	}
+	p.prune()
 	return nil
 }
+
+// ListRevisions returns the recorded revisions, oldest first.
+func (p *JSONProvider) ListRevisions(_ context.Context) ([]domain.ConfigRevision, error) {
+	numbers, err := p.revisionNumbers()
+	if err != nil {
+		return nil, err
+	}
+	revisions := make([]domain.ConfigRevision, 0, len(numbers))
+	for _, n := range numbers {
+		rf, err := p.readRevision(n)
+		if err != nil {
+			return nil, err
+		}
+		revisions = append(revisions, rf.ConfigRevision)
+	}
+	return revisions, nil
+}
+
+// GetRevision returns a recorded revision and the configuration it saved.
+func (p *JSONProvider) GetRevision(_ context.Context, revision int) (*domain.ConfigRevisionDetail, error) {
+	rf, err := p.readRevision(revision)
+	if err != nil {
+		return nil, err
+	}
+	var config domain.BucardoConfig
+	if err := json.Unmarshal(rf.Config, &config); err != nil {
+		return nil, fmt.Errorf("failed to parse revision %d: %w", revision, err)
+	}
+	return &domain.ConfigRevisionDetail{ConfigRevision: rf.ConfigRevision, Config: &config}, nil
+}
+
+// LatestRevision returns the number of the latest revision, or 0 before the first save.
+func (p *JSONProvider) LatestRevision(_ context.Context) (int, error) {
+	numbers, err := p.revisionNumbers()
+	if err != nil || len(numbers) == 0 {
+		return 0, err
+	}
+	return numbers[len(numbers)-1], nil
+}
+
+func (p *JSONProvider) revisionPath(revision int) string {
+	return filepath.Join(p.historyDir, fmt.Sprintf("%06d.json", revision))
+}
+
+// revisionNumbers lists the revisions in the history directory in ascending order.
+func (p *JSONProvider) revisionNumbers() ([]int, error) {
+	entries, err := os.ReadDir(p.historyDir)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config history %s: %w", p.historyDir, err)
+	}
+	var numbers []int
+	for _, entry := range entries {
+		name, ok := strings.CutSuffix(entry.Name(), ".json")
+		if !ok || entry.IsDir() {
+			continue
+		}
+		if n, err := strconv.Atoi(name); err == nil && n > 0 {
+			numbers = append(numbers, n)
+		}
+	}
+	sort.Ints(numbers)
+	return numbers, nil
+}
+
+func (p *JSONProvider) readRevision(revision int) (*revisionFile, error) {
+	data, err := os.ReadFile(p.revisionPath(revision))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("%w: %d", ports.ErrRevisionNotFound, revision)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read revision %d: %w", revision, err)
+	}
+	var rf revisionFile
+	if err := json.Unmarshal(data, &rf); err != nil {
+		return nil, fmt.Errorf("failed to parse revision %d: %w", revision, err)
+	}
+	return &rf, nil
+}
+
+// writeRevision stores a revision. History files contain database passwords, so they are
+// only readable by the owner.
+func (p *JSONProvider) writeRevision(revision domain.ConfigRevision, config []byte) error {
+	sum := sha256.Sum256(config)
+	revision.Checksum = hex.EncodeToString(sum[:])
+	data, err := json.MarshalIndent(revisionFile{ConfigRevision: revision, Config: config}, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal revision %d: %w", revision.Revision, err)
+	}
+	if err := os.MkdirAll(p.historyDir, 0700); err != nil {
+		return fmt.Errorf("failed to create config history %s: %w", p.historyDir, err)
+	}
+	if err := os.WriteFile(p.revisionPath(revision.Revision), data, 0600); err != nil {
+		return fmt.Errorf("failed to record revision %d: %w", revision.Revision, err)
+	}
+	return nil
+}
+
+// prune removes the oldest revisions beyond maxConfigRevisions.
+func (p *JSONProvider) prune() {
+	numbers, err := p.revisionNumbers()
+	if err != nil {
+		return
+	}
+	for len(numbers) > maxConfigRevisions {
+		os.Remove(p.revisionPath(numbers[0]))
+		numbers = numbers[1:]
+	}
+}
+
+// writeFileAtomic replaces path with data through a temporary file in the same directory,
+// so readers never see a partially written file. The file keeps its permissions.
+func writeFileAtomic(path string, data []byte) error {
+	mode := os.FileMode(0644)
+	if info, err := os.Stat(path); err == nil {
+		mode = info.Mode().Perm()
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmp.Name(), mode); err != nil {
+		return err
+	}
+
+	err = os.Rename(tmp.Name(), path)
+	if errors.Is(err, syscall.EBUSY) || errors.Is(err, syscall.EXDEV) {
+		// A file bind-mounted on its own (e.g. docker -v ./bucardo.json:...) cannot be
+		// replaced by a rename; fall back to rewriting it in place.
+		return os.WriteFile(path, data, mode)
+	}
+	return err
+}
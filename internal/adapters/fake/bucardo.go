@@ -0,0 +1,616 @@
+// Package fake provides in-memory implementations of the ports, for exercising the
+// orchestrator without Bucardo, PostgreSQL or the file system.
+package fake
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"replication-service/internal/core/domain"
+)
+
+// Database is a database registered in the fake Bucardo with "add db".
+type Database struct {
+	DBName string
+	Host   string
+	User   string
+	Pass   string
+	Port   int
+}
+
+// Sync is a sync registered in the fake Bucardo with "add sync".
+type Sync struct {
+	Relgroup         string
+	Dbgroup          string
+	Onetimecopy      int
+	Status           string // "active" or "inactive".
+	StrictChecking   string // As given, e.g. "false"; empty when never set.
+	ConflictStrategy string
+	StayAlive        bool
+}
+
+// BucardoExecutor is an in-memory ports.BucardoExecutor. It models Bucardo's databases,
+// dbgroups, relgroups (which Bucardo also calls herds) and syncs, and answers commands the
+// way the bucardo CLI does, including its errors, e.g. for an unknown sync. Every command is
+// recorded in Commands.
+type BucardoExecutor struct {
+	mu sync.Mutex
+
+	Installed    bool
+	Running      bool
+	LogLevel     string
+	BucardoPass  string                           // Password set with EnsureBucardoUserPassword.
+	Databases    map[string]*Database             // By Bucardo name, e.g. "db1".
+	Dbgroups     map[string][]string              // Members, e.g. "db1:source".
+	Relgroups    map[string][]string              // Tables, schema-qualified and sorted.
+	Syncs        map[string]*Sync                 // By name.
+	SourceTables map[string][]string              // Tables found by "add all tables", by Bucardo database name.
+	SyncStates   map[string]*domain.SyncRunResult // Answers of GetSyncStatus; "Good" when not set.
+	Commands     []string                         // Every command run, as "add sync orders ...".
+	Failures     map[string]error                 // Commands starting with a key fail with its error.
+}
+
+// NewBucardoExecutor creates an empty, not yet installed Bucardo.
+func NewBucardoExecutor() *BucardoExecutor {
+	return &BucardoExecutor{
+		Databases:    make(map[string]*Database),
+		Dbgroups:     make(map[string][]string),
+		Relgroups:    make(map[string][]string),
+		Syncs:        make(map[string]*Sync),
+		SourceTables: make(map[string][]string),
+		SyncStates:   make(map[string]*domain.SyncRunResult),
+		Failures:     make(map[string]error),
+	}
+}
+
+// FailOn makes every later command starting with prefix, e.g. "add sync orders", fail.
+func (b *BucardoExecutor) FailOn(prefix string, err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.Failures[prefix] = err
+}
+
+// Ran reports whether a command starting with prefix was run.
+func (b *BucardoExecutor) Ran(prefix string) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for _, command := range b.Commands {
+		if command == prefix || strings.HasPrefix(command, prefix+" ") {
+			return true
+		}
+	}
+	return false
+}
+
+// ResetCommands forgets the recorded commands.
+func (b *BucardoExecutor) ResetCommands() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.Commands = nil
+}
+
+// record logs a command and returns the failure injected for it, if any. Callers hold mu.
+func (b *BucardoExecutor) record(args ...string) error {
+	command := strings.Join(args, " ")
+	b.Commands = append(b.Commands, command)
+	for prefix, err := range b.Failures {
+		if command == prefix || strings.HasPrefix(command, prefix+" ") {
+			return err
+		}
+	}
+	return nil
+}
+
+func (b *BucardoExecutor) InstallBucardo(ctx context.Context, dbname, host, user, pass string) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if err := b.record("install", "--batch", "--dbname="+dbname, "--dbhost="+host, "--dbuser="+user); err != nil {
+		return fmt.Errorf("bucardo install failed: %w", err)
+	}
+	b.Installed = true
+	return nil
+}
+
+func (b *BucardoExecutor) EnsureBucardoUserPassword(ctx context.Context, dbhost, dbuser, dbpass, bucardoUser, bucardoPass string, dbport int) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if err := b.record("psql", "ALTER USER", bucardoUser); err != nil {
+		return fmt.Errorf("failed to reset bucardo user password: %w", err)
+	}
+	if b.Installed { // Before the install the user does not exist, which is not an error.
+		b.BucardoPass = bucardoPass
+	}
+	return nil
+}
+
+func (b *BucardoExecutor) SetLogLevel(ctx context.Context, level string) error {
+	return b.ExecuteBucardoCommand(ctx, "set", "log_level="+level)
+}
+
+func (b *BucardoExecutor) ListDatabases(ctx context.Context) ([]string, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if err := b.record("list", "dbs"); err != nil {
+		return nil, fmt.Errorf("failed to execute 'bucardo list dbs': %w", err)
+	}
+	return sortedKeys(b.Databases), nil
+}
+
+func (b *BucardoExecutor) DatabaseExists(ctx context.Context, dbName string) (bool, error) {
+	dbs, err := b.ListDatabases(ctx)
+	if err != nil {
+		return false, err
+	}
+	for _, name := range dbs {
+		if name == dbName {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (b *BucardoExecutor) RemoveDatabase(ctx context.Context, dbName string) error {
+	return b.ExecuteBucardoCommand(ctx, "del", "dbs", dbName)
+}
+
+// RenameDatabase renames a database along with its dbgroup memberships and the tables found
+// on it, as Bucardo's foreign keys do for the SQL update of the real executor.
+func (b *BucardoExecutor) RenameDatabase(ctx context.Context, oldName, newName, dbHost, dbUser, dbPass string, dbPort int) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if err := b.record("psql", "UPDATE bucardo.db", oldName, newName); err != nil {
+		return fmt.Errorf("failed to rename database %s to %s: %w", oldName, newName, err)
+	}
+	db, ok := b.Databases[oldName]
+	if !ok {
+		return fmt.Errorf("failed to rename database %s to %s: no such database", oldName, newName)
+	}
+	if _, ok := b.Databases[newName]; ok {
+		return fmt.Errorf("failed to rename database %s to %s: duplicate key value violates unique constraint \"db_pkey\"", oldName, newName)
+	}
+	delete(b.Databases, oldName)
+	b.Databases[newName] = db
+	for _, members := range b.Dbgroups {
+		for i, member := range members {
+			if name, role, _ := strings.Cut(member, ":"); name == oldName {
+				members[i] = newName + ":" + role
+			}
+		}
+	}
+	if tables, ok := b.SourceTables[oldName]; ok {
+		delete(b.SourceTables, oldName)
+		b.SourceTables[newName] = tables
+	}
+	return nil
+}
+
+func (b *BucardoExecutor) ListSyncs(ctx context.Context) ([]string, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if err := b.record("list", "syncs"); err != nil {
+		return nil, fmt.Errorf("failed to execute 'bucardo list syncs': %w", err)
+	}
+	return sortedKeys(b.Syncs), nil
+}
+
+// SyncExists answers like `bucardo list sync <name>`; the details name the relgroup.
+func (b *BucardoExecutor) SyncExists(ctx context.Context, syncName string) (bool, []byte, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if err := b.record("list", "sync", syncName); err != nil {
+		return false, nil, nil // The CLI treats a failing listing as a missing sync.
+	}
+	s, ok := b.Syncs[syncName]
+	if !ok {
+		return false, []byte("No such sync: " + syncName + "\n"), nil
+	}
+	details := fmt.Sprintf("Sync: %s\nRelgroup: %s\nDbgroup: %s\nStatus: %s\n", syncName, s.Relgroup, s.Dbgroup, s.Status)
+	return true, []byte(details), nil
+}
+
+var relgroupRe = regexp.MustCompile(`Relgroup(?::\s*|\s+")([^"\s]+)`)
+
+func (b *BucardoExecutor) GetSyncRelgroup(_ context.Context, syncDetailsOutput []byte) (string, error) {
+	m := relgroupRe.FindSubmatch(syncDetailsOutput)
+	if m == nil {
+		return "", fmt.Errorf("could not find relgroup in sync details")
+	}
+	return string(m[1]), nil
+}
+
+func (b *BucardoExecutor) GetSyncTables(ctx context.Context, relgroupName string) ([]string, error) {
+	if relgroupName == "" {
+		return []string{}, nil
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if err := b.record("list", "relgroup", relgroupName, "--verbose"); err != nil {
+		return nil, fmt.Errorf("failed to list relgroup %s: %w", relgroupName, err)
+	}
+	tables, ok := b.Relgroups[relgroupName]
+	if !ok {
+		return nil, fmt.Errorf("failed to list relgroup %s: No such relgroup: %s", relgroupName, relgroupName)
+	}
+	return append([]string{}, tables...), nil
+}
+
+// RemoveSyncAndRelgroup removes both objects. Like the CLI's SQL fallback, removing what
+// does not exist succeeds.
+func (b *BucardoExecutor) RemoveSyncAndRelgroup(ctx context.Context, syncName, relgroupName, dbHost, dbUser, dbPass string, dbPort int) error {
+	if err := b.ExecuteBucardoCommand(ctx, "del", "sync", syncName, "--force"); err != nil && !strings.Contains(err.Error(), "No such sync") {
+		return err
+	}
+	b.ExecuteBucardoCommand(ctx, "del", "relgroup", relgroupName)
+	return nil
+}
+
+func (b *BucardoExecutor) KickSync(ctx context.Context, syncName string, timeout int) (*domain.SyncRunResult, error) {
+	args := []string{"kick", syncName}
+	if timeout > 0 {
+		args = append(args, strconv.Itoa(timeout))
+	}
+	if err := b.ExecuteBucardoCommand(ctx, args...); err != nil {
+		if strings.Contains(err.Error(), "No such sync") {
+			return nil, fmt.Errorf("bucardo does not know sync %s. Output: %v", syncName, err)
+		}
+		return nil, fmt.Errorf("failed to kick sync %s: %w", syncName, err)
+	}
+	if timeout <= 0 {
+		return &domain.SyncRunResult{SyncName: syncName, Outcome: domain.SyncRunKicked}, nil
+	}
+	result, err := b.GetSyncStatus(ctx, syncName)
+	if err != nil {
+		return nil, err
+	}
+	result.Outcome = domain.SyncRunDone
+	return result, nil
+}
+
+func (b *BucardoExecutor) GetSyncStatus(ctx context.Context, syncName string) (*domain.SyncRunResult, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if err := b.record("status", syncName); err != nil {
+		return nil, fmt.Errorf("failed to execute 'bucardo status %s': %w", syncName, err)
+	}
+	if _, ok := b.Syncs[syncName]; !ok {
+		return nil, fmt.Errorf("failed to execute 'bucardo status %s': No such sync: %s", syncName, syncName)
+	}
+	if state, ok := b.SyncStates[syncName]; ok {
+		result := *state
+		result.SyncName = syncName
+		return &result, nil
+	}
+	return &domain.SyncRunResult{SyncName: syncName, State: "Good"}, nil
+}
+
+func (b *BucardoExecutor) ActivateSync(ctx context.Context, syncName string) error {
+	return b.ExecuteBucardoCommand(ctx, "activate", syncName)
+}
+
+func (b *BucardoExecutor) DeactivateSync(ctx context.Context, syncName string) error {
+	return b.ExecuteBucardoCommand(ctx, "deactivate", syncName)
+}
+
+func (b *BucardoExecutor) SetSyncOnetimecopy(ctx context.Context, syncName string, mode int) error {
+	if err := b.ExecuteBucardoCommand(ctx, "update", "sync", syncName, fmt.Sprintf("onetimecopy=%d", mode)); err != nil {
+		return fmt.Errorf("failed to set onetimecopy=%d on sync %s: %w", mode, syncName, err)
+	}
+	return nil
+}
+
+func (b *BucardoExecutor) StartBucardo(ctx context.Context) error {
+	return b.ExecuteBucardoCommand(ctx, "start")
+}
+
+func (b *BucardoExecutor) StopBucardo(ctx context.Context) error {
+	return b.ExecuteBucardoCommand(ctx, "stop")
+}
+
+// ExecuteBucardoCommand runs a bucardo CLI command against the in-memory state.
+func (b *BucardoExecutor) ExecuteBucardoCommand(ctx context.Context, args ...string) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if err := b.record(args...); err != nil {
+		return err
+	}
+	if len(args) == 0 {
+		return fmt.Errorf("no command given")
+	}
+
+	verb, rest := args[0], args[1:]
+	noun, name := "", ""
+	if len(rest) > 0 {
+		noun = rest[0]
+	}
+	if len(rest) > 1 {
+		name = rest[1]
+	}
+	switch verb {
+	case "start":
+		if !b.Installed {
+			return fmt.Errorf("bucardo is not installed")
+		}
+		b.Running = true
+		return nil
+	case "stop":
+		b.Running = false
+		return nil
+	case "set":
+		if len(rest) == 1 && strings.HasPrefix(rest[0], "log_level=") {
+			b.LogLevel = strings.TrimPrefix(rest[0], "log_level=")
+			return nil
+		}
+	case "activate", "deactivate":
+		s, ok := b.Syncs[noun]
+		if !ok {
+			return fmt.Errorf("No such sync: %s", noun)
+		}
+		s.Status = map[string]string{"activate": domain.SyncStatusActive, "deactivate": domain.SyncStatusInactive}[verb]
+		return nil
+	case "kick":
+		if _, ok := b.Syncs[noun]; !ok {
+			return fmt.Errorf("No such sync: %s", noun)
+		}
+		return nil
+	case "reload":
+		if noun == "sync" {
+			if _, ok := b.Syncs[name]; !ok {
+				return fmt.Errorf("No such sync: %s", name)
+			}
+			return nil
+		}
+	case "add":
+		return b.add(noun, name, rest[min(2, len(rest)):])
+	case "update":
+		return b.update(noun, name, rest[min(2, len(rest)):])
+	case "del":
+		return b.del(noun, name, rest[min(2, len(rest)):])
+	}
+	return fmt.Errorf("unknown command: bucardo %s", strings.Join(args, " "))
+}
+
+func (b *BucardoExecutor) add(noun, name string, params []string) error {
+	switch noun {
+	case "db":
+		if _, ok := b.Databases[name]; ok {
+			return fmt.Errorf("Cannot add database: the name %q already exists", name)
+		}
+		db := &Database{Port: 5432}
+		applyDatabaseParams(db, params)
+		if db.DBName == "" {
+			return fmt.Errorf("Cannot add database: must supply a database name to connect to")
+		}
+		b.Databases[name] = db
+		return nil
+	case "dbgroup":
+		if _, ok := b.Dbgroups[name]; ok {
+			return fmt.Errorf("Cannot add dbgroup: the name %q already exists", name)
+		}
+		for _, member := range params {
+			db, role, _ := strings.Cut(member, ":")
+			if _, ok := b.Databases[db]; !ok {
+				return fmt.Errorf("No such database: %s", db)
+			}
+			if role != "" && role != "source" && role != "target" {
+				return fmt.Errorf("Invalid role %q for database %s", role, db)
+			}
+		}
+		b.Dbgroups[name] = append([]string{}, params...)
+		return nil
+	case "herd", "relgroup":
+		if _, ok := b.Relgroups[name]; ok {
+			return fmt.Errorf("Cannot add relgroup: the name %q already exists", name)
+		}
+		b.Relgroups[name] = []string{}
+		return nil
+	case "all":
+		// add all tables --herd=<herd> db=<db>
+		if name != "tables" {
+			break
+		}
+		values := paramValues(params)
+		db := values["db"]
+		if _, ok := b.Databases[db]; !ok {
+			return fmt.Errorf("No such database: %s", db)
+		}
+		herd := values["--herd"]
+		tables := b.Relgroups[herd]
+		for _, table := range b.SourceTables[db] {
+			tables = appendTable(tables, table)
+		}
+		b.Relgroups[herd] = tables
+		return nil
+	case "sync":
+		if _, ok := b.Syncs[name]; ok {
+			return fmt.Errorf("Cannot add sync: the name %q already exists", name)
+		}
+		values := paramValues(params)
+		s := &Sync{Status: domain.SyncStatusActive, StayAlive: true}
+		s.Dbgroup = values["dbs"]
+		if _, ok := b.Dbgroups[s.Dbgroup]; !ok {
+			return fmt.Errorf("No such dbgroup: %s", s.Dbgroup)
+		}
+		switch {
+		case values["herd"] != "":
+			if _, ok := b.Relgroups[values["herd"]]; !ok {
+				return fmt.Errorf("No such relgroup: %s", values["herd"])
+			}
+			s.Relgroup = values["herd"]
+		case values["tables"] != "":
+			// Bucardo creates a relgroup named after the sync for a list of tables.
+			var tables []string
+			for _, table := range strings.Split(values["tables"], ",") {
+				tables = appendTable(tables, table)
+			}
+			b.Relgroups[name] = tables
+			s.Relgroup = name
+		default:
+			return fmt.Errorf("Cannot add sync %s: must provide tables or a relgroup", name)
+		}
+		if err := applySyncParams(s, values); err != nil {
+			return err
+		}
+		b.Syncs[name] = s
+		return nil
+	}
+	return fmt.Errorf("unknown command: bucardo add %s", noun)
+}
+
+func (b *BucardoExecutor) update(noun, name string, params []string) error {
+	switch noun {
+	case "db":
+		db, ok := b.Databases[name]
+		if !ok {
+			return fmt.Errorf("No such database: %s", name)
+		}
+		applyDatabaseParams(db, params)
+		return nil
+	case "sync":
+		s, ok := b.Syncs[name]
+		if !ok {
+			return fmt.Errorf("No such sync: %s", name)
+		}
+		return applySyncParams(s, paramValues(params))
+	}
+	return fmt.Errorf("unknown command: bucardo update %s", noun)
+}
+
+func (b *BucardoExecutor) del(noun, name string, params []string) error {
+	switch noun {
+	case "db", "dbs":
+		if _, ok := b.Databases[name]; !ok {
+			return fmt.Errorf("No such database: %s", name)
+		}
+		delete(b.Databases, name)
+		// Memberships go with the database.
+		for group, members := range b.Dbgroups {
+			kept := members[:0]
+			for _, member := range members {
+				if db, _, _ := strings.Cut(member, ":"); db != name {
+					kept = append(kept, member)
+				}
+			}
+			b.Dbgroups[group] = kept
+		}
+		return nil
+	case "dbgroup":
+		if _, ok := b.Dbgroups[name]; !ok {
+			return fmt.Errorf("No such dbgroup: %s", name)
+		}
+		for syncName, s := range b.Syncs {
+			if s.Dbgroup == name {
+				return fmt.Errorf("Cannot remove dbgroup %s: it is used by sync %s", name, syncName)
+			}
+		}
+		delete(b.Dbgroups, name)
+		return nil
+	case "herd", "relgroup":
+		if _, ok := b.Relgroups[name]; !ok {
+			return fmt.Errorf("No such relgroup: %s", name)
+		}
+		force := len(params) > 0 && params[0] == "--force"
+		for syncName, s := range b.Syncs {
+			if s.Relgroup == name {
+				if !force {
+					return fmt.Errorf("Cannot remove relgroup %s: it is used by sync %s", name, syncName)
+				}
+				delete(b.Syncs, syncName)
+			}
+		}
+		delete(b.Relgroups, name)
+		return nil
+	case "sync":
+		if _, ok := b.Syncs[name]; !ok {
+			return fmt.Errorf("No such sync: %s", name)
+		}
+		delete(b.Syncs, name)
+		return nil
+	}
+	return fmt.Errorf("unknown command: bucardo del %s", noun)
+}
+
+// paramValues splits key=value parameters.
+func paramValues(params []string) map[string]string {
+	values := make(map[string]string, len(params))
+	for _, p := range params {
+		key, value, _ := strings.Cut(p, "=")
+		values[key] = value
+	}
+	return values
+}
+
+func applyDatabaseParams(db *Database, params []string) {
+	for key, value := range paramValues(params) {
+		switch key {
+		case "dbname":
+			db.DBName = value
+		case "host":
+			db.Host = value
+		case "user":
+			db.User = value
+		case "pass":
+			db.Pass = value
+		case "port":
+			db.Port, _ = strconv.Atoi(value)
+		}
+	}
+}
+
+func applySyncParams(s *Sync, values map[string]string) error {
+	for key, value := range values {
+		switch key {
+		case "onetimecopy":
+			mode, err := strconv.Atoi(value)
+			if err != nil || mode < 0 || mode > 2 {
+				return fmt.Errorf("Invalid onetimecopy value: %s", value)
+			}
+			s.Onetimecopy = mode
+		case "status":
+			if value != domain.SyncStatusActive && value != domain.SyncStatusInactive {
+				return fmt.Errorf("Invalid status value: %s", value)
+			}
+			s.Status = value
+		case "strict_checking":
+			s.StrictChecking = value
+		case "conflict_strategy":
+			s.ConflictStrategy = value
+		case "stayalive":
+			s.StayAlive = value != "0"
+		}
+	}
+	return nil
+}
+
+// appendTable adds a table to a sorted list, qualified with "public." when it has no
+// schema, as Bucardo resolves it.
+func appendTable(tables []string, table string) []string {
+	table = strings.TrimSpace(table)
+	if table == "" {
+		return tables
+	}
+	if !strings.Contains(table, ".") {
+		table = "public." + table
+	}
+	for _, t := range tables {
+		if t == table {
+			return tables
+		}
+	}
+	tables = append(tables, table)
+	sort.Strings(tables)
+	return tables
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,65 @@
+package fake
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestBucardoExecutorErrors(t *testing.T) {
+	ctx := context.Background()
+	b := NewBucardoExecutor()
+	b.InstallBucardo(ctx, "bucardo", "postgres", "postgres", "pass")
+	for _, args := range [][]string{
+		{"add", "db", "db1", "dbname=app", "host=pg1"},
+		{"add", "db", "db2", "dbname=app", "host=pg2"},
+		{"add", "dbgroup", "g", "db1:source", "db2:target"},
+		{"add", "sync", "orders", "dbs=g", "tables=orders,public.customers"},
+	} {
+		if err := b.ExecuteBucardoCommand(ctx, args...); err != nil {
+			t.Fatalf("%v: %v", args, err)
+		}
+	}
+
+	tests := []struct {
+		args    []string
+		wantErr string
+	}{
+		{[]string{"update", "sync", "missing", "status=active"}, "No such sync: missing"},
+		{[]string{"del", "sync", "missing", "--force"}, "No such sync: missing"},
+		{[]string{"kick", "missing"}, "No such sync: missing"},
+		{[]string{"update", "db", "db9", "host=x"}, "No such database: db9"},
+		{[]string{"add", "db", "db1", "dbname=app"}, "already exists"},
+		{[]string{"add", "dbgroup", "h", "db9:source"}, "No such database: db9"},
+		{[]string{"add", "sync", "orders", "dbs=g", "tables=orders"}, "already exists"},
+		{[]string{"add", "sync", "other", "dbs=nope", "tables=orders"}, "No such dbgroup: nope"},
+		{[]string{"add", "sync", "other", "dbs=g", "herd=nope"}, "No such relgroup: nope"},
+		{[]string{"del", "dbgroup", "g"}, "used by sync orders"},
+		{[]string{"update", "sync", "orders", "onetimecopy=5"}, "Invalid onetimecopy value"},
+		{[]string{"frobnicate"}, "unknown command"},
+	}
+	for _, tt := range tests {
+		err := b.ExecuteBucardoCommand(ctx, tt.args...)
+		if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+			t.Errorf("%v: error = %v, want %q", tt.args, err, tt.wantErr)
+		}
+	}
+
+	exists, details, _ := b.SyncExists(ctx, "orders")
+	relgroup, err := b.GetSyncRelgroup(ctx, details)
+	if !exists || err != nil || relgroup != "orders" {
+		t.Fatalf("SyncExists/GetSyncRelgroup = %t, %q, %v", exists, relgroup, err)
+	}
+	tables, _ := b.GetSyncTables(ctx, relgroup)
+	if want := []string{"public.customers", "public.orders"}; !reflect.DeepEqual(tables, want) {
+		t.Errorf("tables = %v, want %v", tables, want)
+	}
+	if _, err := b.GetSyncStatus(ctx, "missing"); err == nil {
+		t.Error("GetSyncStatus of an unknown sync succeeded")
+	}
+	// Removing what is already gone succeeds, as the CLI's SQL fallback does.
+	if err := b.RemoveSyncAndRelgroup(ctx, "missing", "missing", "", "", "", 0); err != nil {
+		t.Errorf("RemoveSyncAndRelgroup of an unknown sync: %v", err)
+	}
+}
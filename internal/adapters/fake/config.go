@@ -0,0 +1,134 @@
+package fake
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"replication-service/internal/core/domain"
+	"replication-service/internal/core/ports"
+	"replication-service/internal/core/reqctx"
+)
+
+// ConfigProvider is an in-memory ports.ConfigProvider. Like the JSON file provider it
+// records every save as a revision and rejects a save that expects an older revision.
+// Configurations are copied on the way in and out, so callers cannot change stored ones.
+type ConfigProvider struct {
+	mu        sync.Mutex
+	current   []byte
+	revisions []revision
+	LoadErr   error // Returned by LoadConfig when set.
+	loads     int
+	SaveErr   error // Returned by SaveConfig when set.
+}
+
+type revision struct {
+	domain.ConfigRevision
+	config []byte
+}
+
+// NewConfigProvider creates a provider holding config, which is not recorded as a revision.
+// A nil config makes LoadConfig fail until the first save.
+func NewConfigProvider(config *domain.BucardoConfig) *ConfigProvider {
+	p := &ConfigProvider{}
+	if config != nil {
+		p.current, _ = json.Marshal(config)
+	}
+	return p
+}
+
+// Set replaces the current configuration without recording a revision, as an operator
+// editing the file would.
+func (p *ConfigProvider) Set(config *domain.BucardoConfig) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.current, _ = json.Marshal(config)
+}
+
+// Loads returns the number of LoadConfig calls so far.
+func (p *ConfigProvider) Loads() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.loads
+}
+
+func (p *ConfigProvider) LoadConfig(_ context.Context) (*domain.BucardoConfig, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.loads++
+	if p.LoadErr != nil {
+		return nil, p.LoadErr
+	}
+	if p.current == nil {
+		return nil, fmt.Errorf("failed to open bucardo.json: no configuration")
+	}
+	var config domain.BucardoConfig
+	if err := json.Unmarshal(p.current, &config); err != nil {
+		return nil, fmt.Errorf("failed to parse bucardo.json: %w", err)
+	}
+	return &config, nil
+}
+
+func (p *ConfigProvider) SaveConfig(ctx context.Context, config *domain.BucardoConfig) error {
+	data, err := json.Marshal(config)
+	if err != nil {
+		return fmt.Errorf("failed to marshal config: %w", err)
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.SaveErr != nil {
+		return p.SaveErr
+	}
+	last := len(p.revisions)
+	if expected, ok := reqctx.ExpectedRevision(ctx); ok && expected != last {
+		return fmt.Errorf("%w: expected revision %d, latest is %d", ports.ErrRevisionConflict, expected, last)
+	}
+	sum := sha256.Sum256(data)
+	p.revisions = append(p.revisions, revision{
+		ConfigRevision: domain.ConfigRevision{
+			Revision: last + 1,
+			Time:     time.Now(),
+			Actor:    reqctx.Actor(ctx),
+			Message:  reqctx.ChangeMessage(ctx),
+			Checksum: hex.EncodeToString(sum[:]),
+		},
+		config: data,
+	})
+	p.current = data
+	return nil
+}
+
+func (p *ConfigProvider) ListRevisions(_ context.Context) ([]domain.ConfigRevision, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	revisions := make([]domain.ConfigRevision, len(p.revisions))
+	for i, r := range p.revisions {
+		revisions[i] = r.ConfigRevision
+	}
+	return revisions, nil
+}
+
+func (p *ConfigProvider) GetRevision(_ context.Context, number int) (*domain.ConfigRevisionDetail, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if number < 1 || number > len(p.revisions) {
+		return nil, fmt.Errorf("%w: %d", ports.ErrRevisionNotFound, number)
+	}
+	r := p.revisions[number-1]
+	var config domain.BucardoConfig
+	if err := json.Unmarshal(r.config, &config); err != nil {
+		return nil, fmt.Errorf("failed to parse revision %d: %w", number, err)
+	}
+	return &domain.ConfigRevisionDetail{ConfigRevision: r.ConfigRevision, Config: &config}, nil
+}
+
+func (p *ConfigProvider) LatestRevision(_ context.Context) (int, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return len(p.revisions), nil
+}
@@ -0,0 +1,64 @@
+package fake
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+
+	"replication-service/internal/core/domain"
+)
+
+// CredentialManager is a ports.CredentialManager that keeps the .pgpass entries in memory.
+// Like the real one, it fails for an "env" password whose environment variables are unset.
+type CredentialManager struct {
+	mu        sync.Mutex
+	Entries   []domain.Database // Databases of the last SetupPgpass call.
+	Installed bool              // Whether a .pgpass file would exist.
+	Setups    int
+	Cleanups  int
+}
+
+// NewCredentialManager creates a credential manager without entries.
+func NewCredentialManager() *CredentialManager {
+	return &CredentialManager{}
+}
+
+func (m *CredentialManager) SetupPgpass(_ context.Context, dbs []domain.Database) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.Setups++
+	m.Entries = nil
+	m.Installed = false
+	for _, db := range dbs {
+		if db.Pass == "env" && !envPasswordSet(db) {
+			return fmt.Errorf("failed to get password for .pgpass setup for db %s: environment variable %s not set for db %s",
+				db.BucardoName(), strings.Join(db.PasswordEnvVars(), " or "), db.BucardoName())
+		}
+		m.Entries = append(m.Entries, db)
+	}
+	m.Installed = true
+	return nil
+}
+
+func (m *CredentialManager) CleanupPgpass(_ context.Context) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.Cleanups++
+	if !m.Installed {
+		return fmt.Errorf("remove .pgpass: %w", os.ErrNotExist)
+	}
+	m.Entries = nil
+	m.Installed = false
+	return nil
+}
+
+func envPasswordSet(db domain.Database) bool {
+	for _, envVar := range db.PasswordEnvVars() {
+		if os.Getenv(envVar) != "" {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,177 @@
+package fake
+
+import (
+	"context"
+	"crypto/md5"
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"replication-service/internal/core/domain"
+)
+
+// Table is a table of the fake inspector. Rows map column names to their text values.
+type Table struct {
+	PrimaryKey []string
+	Columns    []string // In table order.
+	Rows       []map[string]string
+}
+
+// Inspector is an in-memory ports.DatabaseInspector over the tables in Tables. Keys that
+// parse as integers are ordered numerically, like an integer primary key in PostgreSQL.
+type Inspector struct {
+	mu     sync.Mutex
+	Tables map[string]map[string]*Table // By database host, then by table name.
+}
+
+// NewInspector creates an inspector without tables.
+func NewInspector() *Inspector {
+	return &Inspector{Tables: make(map[string]map[string]*Table)}
+}
+
+// AddTable adds a table to the database on host.
+func (i *Inspector) AddTable(host, name string, table *Table) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	if i.Tables[host] == nil {
+		i.Tables[host] = make(map[string]*Table)
+	}
+	i.Tables[host][name] = table
+}
+
+func (i *Inspector) table(conn domain.ConnInfo, name string) (*Table, error) {
+	t, ok := i.Tables[conn.Host][name]
+	if !ok {
+		return nil, fmt.Errorf("relation %q does not exist on %s", name, conn.Host)
+	}
+	return t, nil
+}
+
+func (i *Inspector) DescribeTable(_ context.Context, conn domain.ConnInfo, name string) (*domain.TableInfo, error) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	t, err := i.table(conn, name)
+	if err != nil {
+		return nil, err
+	}
+	if len(t.PrimaryKey) == 0 {
+		return nil, fmt.Errorf("table %s has no primary key", name)
+	}
+	columns := append([]string(nil), t.Columns...)
+	sort.Strings(columns)
+	return &domain.TableInfo{
+		Name:          name,
+		QualifiedName: name,
+		PrimaryKey:    append([]string(nil), t.PrimaryKey...),
+		Columns:       columns,
+	}, nil
+}
+
+func (i *Inspector) CountRows(_ context.Context, conn domain.ConnInfo, info *domain.TableInfo) (int64, error) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	t, err := i.table(conn, info.Name)
+	if err != nil {
+		return 0, err
+	}
+	return int64(len(t.Rows)), nil
+}
+
+func (i *Inspector) KeyRanges(_ context.Context, conn domain.ConnInfo, info *domain.TableInfo, chunkSize int) ([]domain.KeyRange, error) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	t, err := i.table(conn, info.Name)
+	if err != nil {
+		return nil, err
+	}
+	keys := tableKeys(t, info.PrimaryKey)
+	var ranges []domain.KeyRange
+	var lower []string
+	for n := chunkSize; n < len(keys); n += chunkSize {
+		ranges = append(ranges, domain.KeyRange{Lower: lower, Upper: keys[n]})
+		lower = keys[n]
+	}
+	return append(ranges, domain.KeyRange{Lower: lower}), nil
+}
+
+func (i *Inspector) RangeChecksum(_ context.Context, conn domain.ConnInfo, info *domain.TableInfo, keyRange domain.KeyRange) (int64, string, error) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	t, err := i.table(conn, info.Name)
+	if err != nil {
+		return 0, "", err
+	}
+	rows := append([]map[string]string(nil), t.Rows...)
+	sort.Slice(rows, func(a, b int) bool {
+		return compareKeys(rowKey(rows[a], info.PrimaryKey), rowKey(rows[b], info.PrimaryKey)) < 0
+	})
+	var count int64
+	hash := md5.New()
+	for _, row := range rows {
+		key := rowKey(row, info.PrimaryKey)
+		if keyRange.Lower != nil && compareKeys(key, keyRange.Lower) < 0 {
+			continue
+		}
+		if keyRange.Upper != nil && compareKeys(key, keyRange.Upper) >= 0 {
+			continue
+		}
+		count++
+		for _, column := range info.Columns {
+			fmt.Fprintf(hash, "%s\x1f", row[column])
+		}
+		hash.Write([]byte{'\x1e'})
+	}
+	if count == 0 {
+		return 0, "", nil
+	}
+	return count, hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// DeltaBacklog reports no Bucardo delta tables.
+func (i *Inspector) DeltaBacklog(context.Context, domain.ConnInfo) ([]domain.DeltaTableStats, error) {
+	return nil, nil
+}
+
+// PurgeDeltas purges nothing, as there are no delta tables.
+func (i *Inspector) PurgeDeltas(context.Context, domain.ConnInfo, int, bool) ([]domain.DeltaPurgeResult, error) {
+	return nil, nil
+}
+
+func rowKey(row map[string]string, primaryKey []string) []string {
+	key := make([]string, len(primaryKey))
+	for n, column := range primaryKey {
+		key[n] = row[column]
+	}
+	return key
+}
+
+func tableKeys(t *Table, primaryKey []string) [][]string {
+	keys := make([][]string, len(t.Rows))
+	for n, row := range t.Rows {
+		keys[n] = rowKey(row, primaryKey)
+	}
+	sort.Slice(keys, func(a, b int) bool { return compareKeys(keys[a], keys[b]) < 0 })
+	return keys
+}
+
+func compareKeys(a, b []string) int {
+	for n := range a {
+		x, errX := strconv.ParseInt(a[n], 10, 64)
+		y, errY := strconv.ParseInt(b[n], 10, 64)
+		switch {
+		case errX == nil && errY == nil && x != y:
+			if x < y {
+				return -1
+			}
+			return 1
+		case errX != nil || errY != nil:
+			if c := strings.Compare(a[n], b[n]); c != 0 {
+				return c
+			}
+		}
+	}
+	return 0
+}
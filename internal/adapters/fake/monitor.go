@@ -0,0 +1,74 @@
+package fake
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"replication-service/internal/core/domain"
+)
+
+// Monitor is a ports.Monitor that does not watch anything. MonitorSyncs reports every
+// sync as completed unless States says otherwise; MonitorBucardo returns when its context
+// is cancelled.
+type Monitor struct {
+	mu       sync.Mutex
+	States   map[string]string // Run-once state by sync name, e.g. domain.RunOnceTimedOut.
+	Timeouts map[string]int    // The timeouts passed to the last MonitorSyncs call.
+	Watching bool              // Whether MonitorBucardo is running.
+}
+
+// NewMonitor creates a monitor that reports every run-once sync as completed.
+func NewMonitor() *Monitor {
+	return &Monitor{States: make(map[string]string)}
+}
+
+func (m *Monitor) MonitorSyncs(_ context.Context, _ *domain.BucardoConfig, timeouts map[string]int) *domain.RunOnceSummary {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.Timeouts = timeouts
+
+	now := time.Now()
+	summary := &domain.RunOnceSummary{StartedAt: now, FinishedAt: now}
+	completed, timedOut, cancelled := 0, 0, 0
+	for syncName, timeout := range timeouts {
+		state := domain.RunOnceCompleted
+		if s, ok := m.States[syncName]; ok {
+			state = s
+		}
+		switch state {
+		case domain.RunOnceCompleted:
+			completed++
+		case domain.RunOnceTimedOut:
+			timedOut++
+		case domain.RunOnceCancelled:
+			cancelled++
+		}
+		summary.Syncs = append(summary.Syncs, domain.RunOnceSyncResult{SyncName: syncName, State: state, TimeoutSeconds: timeout})
+	}
+	sort.Slice(summary.Syncs, func(i, j int) bool { return summary.Syncs[i].SyncName < summary.Syncs[j].SyncName })
+	switch {
+	case completed == len(timeouts):
+		summary.Outcome = domain.RunOnceOutcomeSuccess
+	case cancelled > 0:
+		summary.Outcome = domain.RunOnceOutcomeCancelled
+	case completed == 0 && timedOut == len(timeouts):
+		summary.Outcome = domain.RunOnceOutcomeTimeout
+	default:
+		summary.Outcome = domain.RunOnceOutcomePartial
+	}
+	return summary
+}
+
+func (m *Monitor) MonitorBucardo(ctx context.Context, _ func()) {
+	m.setWatching(true)
+	defer m.setWatching(false)
+	<-ctx.Done()
+}
+
+func (m *Monitor) setWatching(watching bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.Watching = watching
+}
@@ -0,0 +1,43 @@
+package fake
+
+import (
+	"context"
+	"sync"
+
+	"replication-service/internal/core/domain"
+)
+
+// Notifier is a ports.Notifier that records the events it is given.
+type Notifier struct {
+	mu     sync.Mutex
+	events []domain.Event
+}
+
+// NewNotifier creates a notifier without events.
+func NewNotifier() *Notifier {
+	return &Notifier{}
+}
+
+func (n *Notifier) Notify(_ context.Context, event domain.Event) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.events = append(n.events, event)
+}
+
+// Events returns the recorded events, oldest first.
+func (n *Notifier) Events() []domain.Event {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	return append([]domain.Event{}, n.events...)
+}
+
+// EventTypes returns the types of the recorded events, oldest first.
+func (n *Notifier) EventTypes() []string {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	types := make([]string, len(n.events))
+	for i, e := range n.events {
+		types[i] = e.Type
+	}
+	return types
+}
@@ -0,0 +1,386 @@
+package grpcserver
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+
+	"replication-service/internal/adapters/grpcserver/managementpb"
+	"replication-service/internal/core/domain"
+	"replication-service/internal/core/ports"
+	"replication-service/internal/core/services/orchestrator"
+)
+
+func toConfig(c *domain.BucardoConfig) *managementpb.Config {
+	out := &managementpb.Config{LogLevel: c.LogLevel}
+	for _, db := range c.Databases {
+		out.Databases = append(out.Databases, toDatabase(db))
+	}
+	for _, sync := range c.Syncs {
+		out.Syncs = append(out.Syncs, toSync(sync))
+	}
+	if m := c.DeltaMonitor; m != nil {
+		out.DeltaMonitor = &managementpb.DeltaMonitor{
+			IntervalSeconds: int32(m.IntervalSeconds),
+			WarnRows:        m.WarnRows,
+			WarnAgeSeconds:  int32(m.WarnAgeSeconds),
+		}
+	}
+	for _, ch := range c.Notifications {
+		out.Notifications = append(out.Notifications, &managementpb.NotificationChannel{
+			Name:         ch.Name,
+			Type:         ch.Type,
+			Events:       ch.Events,
+			MinSeverity:  ch.MinSeverity,
+			Url:          ch.URL,
+			Secret:       ch.Secret,
+			SmtpHost:     ch.SMTPHost,
+			SmtpPort:     int32(ch.SMTPPort),
+			SmtpUser:     ch.SMTPUser,
+			SmtpPass:     ch.SMTPPass,
+			From:         ch.From,
+			To:           ch.To,
+			DedupSeconds: int32(ch.DedupSeconds),
+			MaxRetries:   int32(ch.MaxRetries),
+		})
+	}
+	if l := c.ServiceLog; l != nil {
+		out.ServiceLog = &managementpb.ServiceLog{Level: l.Level, Components: l.Components}
+	}
+	return out
+}
+
+func fromConfig(c *managementpb.Config) *domain.BucardoConfig {
+	out := &domain.BucardoConfig{LogLevel: c.GetLogLevel()}
+	for _, db := range c.GetDatabases() {
+		out.Databases = append(out.Databases, fromDatabase(db))
+	}
+	for _, sync := range c.GetSyncs() {
+		out.Syncs = append(out.Syncs, fromSync(sync))
+	}
+	if m := c.GetDeltaMonitor(); m != nil {
+		out.DeltaMonitor = &domain.DeltaMonitorConfig{
+			IntervalSeconds: int(m.GetIntervalSeconds()),
+			WarnRows:        m.GetWarnRows(),
+			WarnAgeSeconds:  int(m.GetWarnAgeSeconds()),
+		}
+	}
+	for _, ch := range c.GetNotifications() {
+		out.Notifications = append(out.Notifications, domain.NotificationChannel{
+			Name:         ch.GetName(),
+			Type:         ch.GetType(),
+			Events:       ch.GetEvents(),
+			MinSeverity:  ch.GetMinSeverity(),
+			URL:          ch.GetUrl(),
+			Secret:       ch.GetSecret(),
+			SMTPHost:     ch.GetSmtpHost(),
+			SMTPPort:     int(ch.GetSmtpPort()),
+			SMTPUser:     ch.GetSmtpUser(),
+			SMTPPass:     ch.GetSmtpPass(),
+			From:         ch.GetFrom(),
+			To:           ch.GetTo(),
+			DedupSeconds: int(ch.GetDedupSeconds()),
+			MaxRetries:   int(ch.GetMaxRetries()),
+		})
+	}
+	if l := c.GetServiceLog(); l != nil {
+		out.ServiceLog = &domain.ServiceLogConfig{Level: l.GetLevel(), Components: l.GetComponents()}
+	}
+	return out
+}
+
+func toDatabase(db domain.Database) *managementpb.Database {
+	out := &managementpb.Database{
+		Id:     int32(db.ID),
+		Name:   db.Name,
+		Dbname: db.DBName,
+		Host:   db.Host,
+		User:   db.User,
+		Pass:   db.Pass,
+	}
+	if db.Port != nil {
+		out.Port = wrapperspb.Int32(int32(*db.Port))
+	}
+	return out
+}
+
+func fromDatabase(db *managementpb.Database) domain.Database {
+	out := domain.Database{
+		ID:     int(db.GetId()),
+		Name:   db.GetName(),
+		DBName: db.GetDbname(),
+		Host:   db.GetHost(),
+		User:   db.GetUser(),
+		Pass:   db.GetPass(),
+	}
+	if db.GetPort() != nil {
+		port := int(db.GetPort().GetValue())
+		out.Port = &port
+	}
+	return out
+}
+
+func toSync(s domain.Sync) *managementpb.Sync {
+	out := &managementpb.Sync{
+		Name:             s.Name,
+		Sources:          toRefs(s.Sources),
+		Targets:          toRefs(s.Targets),
+		Bidirectional:    toRefs(s.Bidirectional),
+		Herd:             s.Herd,
+		Tables:           s.Tables,
+		Onetimecopy:      int32(s.Onetimecopy),
+		ConflictStrategy: s.ConflictStrategy,
+		Status:           s.Status,
+	}
+	if s.StrictChecking != nil {
+		out.StrictChecking = wrapperspb.Bool(*s.StrictChecking)
+	}
+	if s.ExitOnComplete != nil {
+		out.ExitOnComplete = wrapperspb.Bool(*s.ExitOnComplete)
+	}
+	if s.ExitOnCompleteTimeout != nil {
+		out.ExitOnCompleteTimeout = wrapperspb.Int32(int32(*s.ExitOnCompleteTimeout))
+	}
+	return out
+}
+
+func fromSync(s *managementpb.Sync) domain.Sync {
+	out := domain.Sync{
+		Name:             s.GetName(),
+		Sources:          fromRefs(s.GetSources()),
+		Targets:          fromRefs(s.GetTargets()),
+		Bidirectional:    fromRefs(s.GetBidirectional()),
+		Herd:             s.GetHerd(),
+		Tables:           s.GetTables(),
+		Onetimecopy:      int(s.GetOnetimecopy()),
+		ConflictStrategy: s.GetConflictStrategy(),
+		Status:           s.GetStatus(),
+	}
+	if v := s.GetStrictChecking(); v != nil {
+		b := v.GetValue()
+		out.StrictChecking = &b
+	}
+	if v := s.GetExitOnComplete(); v != nil {
+		b := v.GetValue()
+		out.ExitOnComplete = &b
+	}
+	if v := s.GetExitOnCompleteTimeout(); v != nil {
+		timeout := int(v.GetValue())
+		out.ExitOnCompleteTimeout = &timeout
+	}
+	return out
+}
+
+func toRefs(refs []domain.DBRef) []string {
+	var out []string
+	for _, ref := range refs {
+		out = append(out, string(ref))
+	}
+	return out
+}
+
+func fromRefs(refs []string) []domain.DBRef {
+	var out []domain.DBRef
+	for _, ref := range refs {
+		out = append(out, domain.DBRef(ref))
+	}
+	return out
+}
+
+func toJob(j *domain.Job) *managementpb.Job {
+	out := &managementpb.Job{
+		Id:         j.ID,
+		Type:       j.Type,
+		Target:     j.Target,
+		State:      j.State,
+		Coalesced:  int32(j.Coalesced),
+		CreatedAt:  timestamppb.New(j.CreatedAt),
+		StartedAt:  toTimestamp(j.StartedAt),
+		FinishedAt: toTimestamp(j.FinishedAt),
+		Error:      j.Error,
+	}
+	for _, step := range j.Steps {
+		out.Steps = append(out.Steps, &managementpb.JobStep{
+			Name:       step.Name,
+			State:      step.State,
+			StartedAt:  timestamppb.New(step.StartedAt),
+			FinishedAt: toTimestamp(step.FinishedAt),
+			Error:      step.Error,
+		})
+	}
+	switch result := j.Result.(type) {
+	case domain.RecopyStatus:
+		out.Result = &managementpb.Job_Recopy{Recopy: toRecopyStatus(result)}
+	case domain.VerifyRun:
+		out.Result = &managementpb.Job_Verify{Verify: toVerifyRun(result)}
+	}
+	return out
+}
+
+func toRecopyStatus(st domain.RecopyStatus) *managementpb.RecopyStatus {
+	out := &managementpb.RecopyStatus{
+		SyncName:   st.SyncName,
+		JobId:      st.JobID,
+		Tables:     st.Tables,
+		State:      st.State,
+		Step:       st.Step,
+		StartedAt:  timestamppb.New(st.StartedAt),
+		FinishedAt: toTimestamp(st.FinishedAt),
+		Error:      st.Error,
+	}
+	if r := st.Result; r != nil {
+		out.Result = &managementpb.SyncRunResult{
+			SyncName:     r.SyncName,
+			Outcome:      r.Outcome,
+			DurationMs:   r.DurationMs,
+			State:        r.State,
+			RowsDeleted:  toInt64Value(r.RowsDeleted),
+			RowsInserted: toInt64Value(r.RowsInserted),
+			Output:       r.Output,
+		}
+	}
+	return out
+}
+
+func toVerifyRun(run domain.VerifyRun) *managementpb.VerifyRun {
+	out := &managementpb.VerifyRun{
+		Id:          run.ID,
+		JobId:       run.JobID,
+		SyncName:    run.SyncName,
+		State:       run.State,
+		Source:      run.Source,
+		Targets:     run.Targets,
+		Parallelism: int32(run.Parallelism),
+		ChunkSize:   int32(run.ChunkSize),
+		StartedAt:   timestamppb.New(run.StartedAt),
+		FinishedAt:  toTimestamp(run.FinishedAt),
+		Mismatches:  int32(run.Mismatches),
+		Error:       run.Error,
+	}
+	for _, t := range run.Tables {
+		table := &managementpb.TableVerification{
+			Table:         t.Table,
+			Target:        t.Target,
+			SourceRows:    t.SourceRows,
+			TargetRows:    t.TargetRows,
+			RowCountMatch: t.RowCountMatch,
+			ChunksChecked: int32(t.ChunksChecked),
+			Error:         t.Error,
+		}
+		for _, m := range t.Mismatches {
+			table.Mismatches = append(table.Mismatches, &managementpb.KeyRangeMismatch{
+				Range:      &managementpb.KeyRange{Lower: m.Range.Lower, Upper: m.Range.Upper},
+				SourceRows: m.SourceRows,
+				TargetRows: m.TargetRows,
+			})
+		}
+		out.Tables = append(out.Tables, table)
+	}
+	return out
+}
+
+// toLogLine converts a JSON log line of the broadcaster. Lines that are not JSON are kept
+// as they are.
+func toLogLine(seq uint64, data []byte) *managementpb.LogLine {
+	out := &managementpb.LogLine{Seq: seq, Line: string(data)}
+	var fields struct {
+		Time      time.Time `json:"time"`
+		Level     string    `json:"level"`
+		Msg       string    `json:"msg"`
+		Component string    `json:"component"`
+		SyncName  string    `json:"sync_name"`
+		DBName    string    `json:"db_name"`
+	}
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return out
+	}
+	if !fields.Time.IsZero() {
+		out.Time = timestamppb.New(fields.Time)
+	}
+	out.Level = fields.Level
+	out.Msg = fields.Msg
+	out.Component = fields.Component
+	out.SyncName = fields.SyncName
+	out.DbName = fields.DBName
+	return out
+}
+
+// toEvent converts an event of the broadcaster, which carries it as JSON.
+func toEvent(seq uint64, data []byte) (*managementpb.Event, error) {
+	var e domain.Event
+	if err := json.Unmarshal(data, &e); err != nil {
+		return nil, err
+	}
+	out := &managementpb.Event{
+		Seq:      seq,
+		Type:     e.Type,
+		Severity: e.Severity,
+		Time:     timestamppb.New(e.Time),
+		SyncName: e.SyncName,
+		Message:  e.Message,
+	}
+	if len(e.Details) > 0 {
+		out.Details = make(map[string]string, len(e.Details))
+		for k, v := range e.Details {
+			out.Details[k] = fmt.Sprint(v)
+		}
+	}
+	return out, nil
+}
+
+func toTimestamp(t *time.Time) *timestamppb.Timestamp {
+	if t == nil {
+		return nil
+	}
+	return timestamppb.New(*t)
+}
+
+func toInt64Value(v *int) *wrapperspb.Int64Value {
+	if v == nil {
+		return nil
+	}
+	return wrapperspb.Int64(int64(*v))
+}
+
+// toStatus maps service errors to gRPC status codes, like the REST API maps them to HTTP
+// statuses.
+func toStatus(err error) error {
+	code := codes.Internal
+	switch {
+	case errors.Is(err, orchestrator.ErrSyncNotFound), errors.Is(err, orchestrator.ErrJobNotFound),
+		errors.Is(err, ports.ErrRevisionNotFound):
+		code = codes.NotFound
+	case errors.Is(err, orchestrator.ErrInvalidConfig):
+		code = codes.InvalidArgument
+	case errors.Is(err, ports.ErrRevisionConflict), errors.Is(err, orchestrator.ErrJobFinished):
+		code = codes.FailedPrecondition
+	}
+	return status.Error(code, err.Error())
+}
+
+// httpStatus gives the HTTP status equivalent to a gRPC error, for the audit trail.
+func httpStatus(err error) int {
+	switch status.Code(err) {
+	case codes.OK:
+		return 200
+	case codes.InvalidArgument:
+		return 400
+	case codes.Unauthenticated:
+		return 401
+	case codes.NotFound:
+		return 404
+	case codes.AlreadyExists:
+		return 409
+	case codes.FailedPrecondition:
+		return 412
+	case codes.Unavailable:
+		return 503
+	}
+	return 500
+}
@@ -0,0 +1,225 @@
+// Package grpcserver serves the management API over gRPC, next to the REST API of package
+// server. The service is described in management.proto; package managementpb holds the
+// code generated from it.
+package grpcserver
+
+//go:generate protoc --go_out=managementpb --go_opt=paths=source_relative --go-grpc_out=managementpb --go-grpc_opt=paths=source_relative management.proto
+
+import (
+	"context"
+	"crypto/subtle"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	otelcodes "go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+
+	"replication-service/internal/adapters/grpcserver/managementpb"
+	"replication-service/internal/adapters/server"
+	"replication-service/internal/adapters/tracing"
+	"replication-service/internal/core/ports"
+	"replication-service/internal/core/reqctx"
+	"replication-service/internal/core/services/orchestrator"
+)
+
+type GRPCServer struct {
+	logger      ports.Logger
+	service     *orchestrator.Service
+	broadcaster *server.LogBroadcaster
+	audit       ports.AuditLog
+	apiToken    string
+	port        int
+	server      *grpc.Server
+}
+
+// NewGRPCServer creates the gRPC management server. When apiToken is set, every call must
+// present it as a bearer token in the authorization metadata.
+func NewGRPCServer(logger ports.Logger, service *orchestrator.Service, broadcaster *server.LogBroadcaster, audit ports.AuditLog, apiToken string, port int) *GRPCServer {
+	s := &GRPCServer{
+		logger:      logger.With("component", "grpc_server"),
+		service:     service,
+		broadcaster: broadcaster,
+		audit:       audit,
+		apiToken:    apiToken,
+		port:        port,
+	}
+	s.server = grpc.NewServer(
+		grpc.ChainUnaryInterceptor(s.unaryInterceptor),
+		grpc.ChainStreamInterceptor(s.streamInterceptor),
+	)
+	managementpb.RegisterManagementServer(s.server, &managementServer{service: service, broadcaster: broadcaster})
+	return s
+}
+
+func (s *GRPCServer) Start() {
+	listener, err := net.Listen("tcp", fmt.Sprintf(":%d", s.port))
+	if err != nil {
+		s.logger.Error("gRPC server failed", "error", err)
+		return
+	}
+	s.logger.Info("Starting gRPC server", "address", listener.Addr().String())
+	if err := s.server.Serve(listener); err != nil {
+		s.logger.Error("gRPC server failed", "error", err)
+	}
+}
+
+// Stop waits for running calls to finish until ctx is done, then closes the remaining
+// ones. Log and event streams only end when their client leaves, so they are closed.
+func (s *GRPCServer) Stop(ctx context.Context) error {
+	done := make(chan struct{})
+	go func() {
+		s.server.GracefulStop()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		s.server.Stop()
+		return ctx.Err()
+	}
+}
+
+// unaryInterceptor traces every call as a span. Streams are not traced, as they last as
+// long as their client stays connected.
+func (s *GRPCServer) unaryInterceptor(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	ctx, span := startSpan(ctx, info.FullMethod)
+	defer span.End()
+	resp, err := s.handleUnary(ctx, req, info, handler)
+	span.SetAttributes(attribute.String("rpc.grpc.status_code", status.Code(err).String()))
+	if httpStatus(err) >= 500 {
+		span.SetStatus(otelcodes.Error, status.Convert(err).Message())
+	}
+	return resp, err
+}
+
+// handleUnary authenticates calls, reads the request metadata into the context and records
+// calls that may change something in the audit trail, like the REST middleware.
+func (s *GRPCServer) handleUnary(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	ctx, err := s.prepare(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if readOnlyMethods[info.FullMethod] {
+		return handler(ctx, req)
+	}
+
+	var resp any
+	server.AuditCall(ctx, s.service, s.audit, "GRPC", info.FullMethod, configMethods[info.FullMethod], func() (int, string) {
+		resp, err = handler(ctx, req)
+		return httpStatus(err), status.Convert(err).Message()
+	})
+	return resp, err
+}
+
+func (s *GRPCServer) streamInterceptor(srv any, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	ctx, err := s.prepare(stream.Context())
+	if err != nil {
+		return err
+	}
+	return handler(srv, &contextStream{ServerStream: stream, ctx: ctx})
+}
+
+// contextStream replaces the context of a server stream.
+type contextStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *contextStream) Context() context.Context {
+	return s.ctx
+}
+
+// prepare checks the API token and stores the actor, change message, expected revision and
+// correlation ID of a call in its context. The correlation ID is returned in the
+// x-request-id response header.
+func (s *GRPCServer) prepare(ctx context.Context) (context.Context, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	get := func(key string) string {
+		if values := md.Get(key); len(values) > 0 {
+			return values[0]
+		}
+		return ""
+	}
+
+	if s.apiToken != "" {
+		presented, _ := strings.CutPrefix(get("authorization"), "Bearer ")
+		if subtle.ConstantTimeCompare([]byte(presented), []byte(s.apiToken)) != 1 {
+			return nil, status.Error(codes.Unauthenticated, "missing or invalid API token")
+		}
+	}
+
+	actor := get("x-actor")
+	if actor == "" {
+		if p, ok := peer.FromContext(ctx); ok {
+			actor = p.Addr.String()
+			if host, _, err := net.SplitHostPort(actor); err == nil {
+				actor = host
+			}
+		}
+	}
+	ctx = reqctx.WithActor(ctx, actor)
+	if message := get("x-change-message"); message != "" {
+		ctx = reqctx.WithChangeMessage(ctx, message)
+	}
+	if match := get("if-match"); match != "" && match != "*" {
+		revision, err := strconv.Atoi(strings.Trim(strings.TrimPrefix(match, "W/"), `"`))
+		if err != nil {
+			return nil, status.Error(codes.InvalidArgument, "invalid if-match metadata, expected a configuration revision")
+		}
+		ctx = reqctx.WithExpectedRevision(ctx, revision)
+	}
+
+	id := get("x-request-id")
+	if id == "" {
+		id = reqctx.NewCorrelationID()
+	}
+	grpc.SetHeader(ctx, metadata.Pairs("x-request-id", id))
+	return reqctx.WithCorrelationID(ctx, id), nil
+}
+
+// startSpan traces a call, continuing the W3C trace context sent in its metadata.
+func startSpan(ctx context.Context, fullMethod string) (context.Context, trace.Span) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	ctx = otel.GetTextMapPropagator().Extract(ctx, metadataCarrier(md))
+	service, method, _ := strings.Cut(strings.TrimPrefix(fullMethod, "/"), "/")
+	return tracing.Tracer().Start(ctx, strings.TrimPrefix(fullMethod, "/"),
+		trace.WithSpanKind(trace.SpanKindServer),
+		trace.WithAttributes(
+			attribute.String("rpc.system", "grpc"),
+			attribute.String("rpc.service", service),
+			attribute.String("rpc.method", method),
+		),
+	)
+}
+
+// metadataCarrier reads the trace context from gRPC metadata.
+type metadataCarrier metadata.MD
+
+func (c metadataCarrier) Get(key string) string {
+	if values := metadata.MD(c).Get(key); len(values) > 0 {
+		return values[0]
+	}
+	return ""
+}
+
+func (c metadataCarrier) Set(key, value string) {
+	metadata.MD(c).Set(key, value)
+}
+
+func (c metadataCarrier) Keys() []string {
+	keys := make([]string, 0, len(c))
+	for key := range c {
+		keys = append(keys, key)
+	}
+	return keys
+}
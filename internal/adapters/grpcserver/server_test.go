@@ -0,0 +1,336 @@
+package grpcserver
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/types/known/emptypb"
+
+	"replication-service/internal/adapters/audit"
+	"replication-service/internal/adapters/fake"
+	"replication-service/internal/adapters/grpcserver/managementpb"
+	"replication-service/internal/adapters/logger"
+	"replication-service/internal/adapters/server"
+	"replication-service/internal/adapters/tracing"
+	"replication-service/internal/core/domain"
+	"replication-service/internal/core/services/orchestrator"
+)
+
+// testServer is the gRPC server wired to in-memory adapters, with a client connected to it.
+type testServer struct {
+	client      managementpb.ManagementClient
+	broadcaster *server.LogBroadcaster
+	audit       *audit.JSONLLog
+}
+
+func newTestServer(t *testing.T, apiToken string) *testServer {
+	t.Helper()
+	dir := t.TempDir()
+	configPath := filepath.Join(dir, "bucardo.json")
+	if err := os.WriteFile(configPath, []byte("{}"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	appLogger := logger.NewSlogAdapter(slog.New(slog.NewTextHandler(io.Discard, nil)))
+	ts := &testServer{
+		broadcaster: server.NewLogBroadcaster(100, "", ""),
+		audit:       audit.NewJSONLLog(appLogger, filepath.Join(dir, "audit.jsonl")),
+	}
+	service := orchestrator.NewService(
+		appLogger,
+		fake.NewConfigProvider(testConfig()),
+		fake.NewCredentialManager(),
+		fake.NewBucardoExecutor(),
+		fake.NewMonitor(),
+		nil,
+		fake.NewNotifier(),
+		tracing.NewOTelTracer(),
+		logger.NewLevelController(slog.LevelInfo),
+		logger.NewRedactor(),
+		configPath, "", "bucardo", "bucardo", "",
+	)
+	// Bucardo starts out matching the configuration.
+	if err := service.ReloadAndRestart(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	go ts.broadcaster.Start()
+
+	s := NewGRPCServer(appLogger, service, ts.broadcaster, ts.audit, apiToken, 0)
+	listener := bufconn.Listen(1 << 20)
+	go s.server.Serve(listener)
+	t.Cleanup(s.server.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufconn",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return listener.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	ts.client = managementpb.NewManagementClient(conn)
+	return ts
+}
+
+// testConfig has two databases and one sync between them.
+func testConfig() *domain.BucardoConfig {
+	return &domain.BucardoConfig{
+		Databases: []domain.Database{
+			{ID: 1, DBName: "app", Host: "pg1", User: "replicator", Pass: "s3cret-pass"},
+			{ID: 2, DBName: "app", Host: "pg2", User: "replicator", Pass: "s3cret-pass"},
+		},
+		Syncs: []domain.Sync{
+			{Name: "orders", Sources: []domain.DBRef{"1"}, Targets: []domain.DBRef{"2"}, Tables: "public.orders"},
+		},
+	}
+}
+
+func testSync(name string) *managementpb.Sync {
+	return &managementpb.Sync{Name: name, Sources: []string{"1"}, Targets: []string{"2"}, Tables: "public." + name}
+}
+
+func TestConfigRoundTrip(t *testing.T) {
+	ts := newTestServer(t, "")
+	ctx := context.Background()
+
+	config, err := ts.client.GetConfig(ctx, &emptypb.Empty{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Beyond the 53 bits a JSON number keeps exactly.
+	const warnRows = 1<<53 + 1
+	config.DeltaMonitor = &managementpb.DeltaMonitor{WarnRows: warnRows}
+	config.ServiceLog = &managementpb.ServiceLog{Level: "info", Components: map[string]string{"db_reconciler": "debug"}}
+	if _, err := ts.client.UpdateConfig(ctx, config); err != nil {
+		t.Fatal(err)
+	}
+
+	var header metadata.MD
+	got, err := ts.client.GetConfig(ctx, &emptypb.Empty{}, grpc.Header(&header))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.GetDeltaMonitor().GetWarnRows() != warnRows {
+		t.Errorf("warn_rows = %d, want %d", got.GetDeltaMonitor().GetWarnRows(), warnRows)
+	}
+	if got.GetServiceLog().GetComponents()["db_reconciler"] != "debug" {
+		t.Errorf("service_log = %v", got.GetServiceLog())
+	}
+	if sync := got.GetSyncs()[0]; sync.GetName() != "orders" || sync.GetSources()[0] != "1" || sync.GetStrictChecking() != nil {
+		t.Errorf("sync = %v", sync)
+	}
+	if etag := header.Get("etag"); len(etag) != 1 || etag[0] == `"0"` {
+		t.Errorf("etag = %q, want the revision of the update", etag)
+	}
+}
+
+func TestUpdateSyncKeepsName(t *testing.T) {
+	ts := newTestServer(t, "")
+	ctx := context.Background()
+
+	sync := testSync("renamed")
+	sync.Tables = "public.orders,public.lines"
+	if _, err := ts.client.UpdateSync(ctx, &managementpb.UpdateSyncRequest{Name: "orders", Sync: sync}); err != nil {
+		t.Fatal(err)
+	}
+	got, err := ts.client.GetSync(ctx, &managementpb.SyncRequest{Name: "orders"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.GetTables() != sync.Tables {
+		t.Errorf("tables = %q, want %q", got.GetTables(), sync.Tables)
+	}
+	if _, err := ts.client.GetSync(ctx, &managementpb.SyncRequest{Name: "renamed"}); status.Code(err) != codes.NotFound {
+		t.Errorf("GetSync(renamed) = %v, want NotFound", err)
+	}
+}
+
+func TestErrorCodes(t *testing.T) {
+	ts := newTestServer(t, "")
+	invalid := testSync("invalid")
+	invalid.Sources = nil
+
+	tests := []struct {
+		name string
+		call func(ctx context.Context) error
+		want codes.Code
+	}{
+		{"unknown sync", func(ctx context.Context) error {
+			_, err := ts.client.GetSync(ctx, &managementpb.SyncRequest{Name: "missing"})
+			return err
+		}, codes.NotFound},
+		{"unknown job", func(ctx context.Context) error {
+			_, err := ts.client.GetJob(ctx, &managementpb.JobRequest{Id: "missing"})
+			return err
+		}, codes.NotFound},
+		{"duplicate sync", func(ctx context.Context) error {
+			_, err := ts.client.CreateSync(ctx, testSync("orders"))
+			return err
+		}, codes.InvalidArgument},
+		{"invalid sync", func(ctx context.Context) error {
+			_, err := ts.client.CreateSync(ctx, invalid)
+			return err
+		}, codes.InvalidArgument},
+		{"invalid update", func(ctx context.Context) error {
+			_, err := ts.client.UpdateSync(ctx, &managementpb.UpdateSyncRequest{Name: "orders", Sync: invalid})
+			return err
+		}, codes.InvalidArgument},
+		{"update without sync", func(ctx context.Context) error {
+			_, err := ts.client.UpdateSync(ctx, &managementpb.UpdateSyncRequest{Name: "orders"})
+			return err
+		}, codes.InvalidArgument},
+		{"update of an unknown sync", func(ctx context.Context) error {
+			_, err := ts.client.UpdateSync(ctx, &managementpb.UpdateSyncRequest{Name: "missing", Sync: testSync("missing")})
+			return err
+		}, codes.NotFound},
+		{"outdated if-match", func(ctx context.Context) error {
+			ctx = metadata.AppendToOutgoingContext(ctx, "if-match", `"99"`)
+			_, err := ts.client.CreateSync(ctx, testSync("customers"))
+			return err
+		}, codes.FailedPrecondition},
+		{"malformed if-match", func(ctx context.Context) error {
+			ctx = metadata.AppendToOutgoingContext(ctx, "if-match", "latest")
+			_, err := ts.client.CreateSync(ctx, testSync("customers"))
+			return err
+		}, codes.InvalidArgument},
+	}
+	for _, tt := range tests {
+		if got := status.Code(tt.call(context.Background())); got != tt.want {
+			t.Errorf("%s: code = %s, want %s", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestAuthentication(t *testing.T) {
+	ts := newTestServer(t, "token")
+
+	if _, err := ts.client.ListSyncs(context.Background(), &emptypb.Empty{}); status.Code(err) != codes.Unauthenticated {
+		t.Errorf("ListSyncs() without a token = %v, want Unauthenticated", err)
+	}
+	ctx := metadata.AppendToOutgoingContext(context.Background(), "authorization", "Bearer token")
+	if resp, err := ts.client.ListSyncs(ctx, &emptypb.Empty{}); err != nil || len(resp.GetSyncs()) != 1 {
+		t.Errorf("ListSyncs() = %v, %v, want the orders sync", resp, err)
+	}
+}
+
+func TestAuditRecords(t *testing.T) {
+	ts := newTestServer(t, "")
+	ctx := metadata.AppendToOutgoingContext(context.Background(), "x-actor", "alice", "x-request-id", "req-1")
+
+	if _, err := ts.client.GetConfig(ctx, &emptypb.Empty{}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ts.client.CreateSync(ctx, testSync("customers")); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ts.client.CreateSync(ctx, testSync("customers")); status.Code(err) != codes.InvalidArgument {
+		t.Fatalf("CreateSync() of a duplicate = %v, want InvalidArgument", err)
+	}
+	if _, err := ts.client.Start(ctx, &emptypb.Empty{}); err != nil {
+		t.Fatal(err)
+	}
+
+	records, err := ts.audit.Query(context.Background(), domain.AuditQuery{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 3 {
+		t.Fatalf("records = %+v, want the two CreateSync calls and Start", records)
+	}
+	start, duplicate, create := records[0], records[1], records[2]
+	if create.Method != "GRPC" || create.Endpoint != managementpb.Management_CreateSync_FullMethodName ||
+		create.Status != 200 || create.Actor != "alice" || create.CorrelationID != "req-1" {
+		t.Errorf("CreateSync record = %+v", create)
+	}
+	if len(create.Changes) != 1 || create.Changes[0].Path != "syncs[name=customers]" {
+		t.Errorf("CreateSync changes = %+v, want the new sync", create.Changes)
+	}
+	if duplicate.Status != 400 || duplicate.Error == "" || len(duplicate.Changes) != 0 {
+		t.Errorf("failed CreateSync record = %+v", duplicate)
+	}
+	if start.Endpoint != managementpb.Management_Start_FullMethodName || len(start.Changes) != 0 {
+		t.Errorf("Start record = %+v", start)
+	}
+}
+
+func TestStreams(t *testing.T) {
+	ts := newTestServer(t, "")
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	logs, err := ts.client.StreamLogs(ctx, &managementpb.StreamLogsRequest{Level: "warn"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	events, err := ts.client.StreamEvents(ctx, &emptypb.Empty{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The header is sent once the subscription is registered.
+	if _, err := logs.Header(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := events.Header(); err != nil {
+		t.Fatal(err)
+	}
+
+	ts.broadcaster.Write([]byte(`{"time":"2026-10-18T10:00:00Z","level":"INFO","msg":"filtered out"}`))
+	ts.broadcaster.Write([]byte(`{"time":"2026-10-18T10:00:01Z","level":"WARN","msg":"lagging","component":"monitor","sync_name":"orders","rows":9007199254740993}`))
+	ts.broadcaster.Notify(ctx, domain.Event{Type: domain.EventSyncStatusChanged, SyncName: "orders", Message: "paused", Details: map[string]any{"status": "inactive"}})
+
+	line, err := logs.Recv()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if line.GetMsg() != "lagging" || line.GetLevel() != "WARN" || line.GetComponent() != "monitor" || line.GetSyncName() != "orders" ||
+		!line.GetTime().AsTime().Equal(time.Date(2026, 10, 18, 10, 0, 1, 0, time.UTC)) || line.GetSeq() != 2 {
+		t.Errorf("log line = %v", line)
+	}
+	if want := `"rows":9007199254740993`; !strings.Contains(line.GetLine(), want) {
+		t.Errorf("line = %q, want it to contain %s", line.GetLine(), want)
+	}
+
+	event, err := events.Recv()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if event.GetType() != domain.EventSyncStatusChanged || event.GetSyncName() != "orders" || event.GetSeverity() != domain.SeverityInfo ||
+		event.GetDetails()["status"] != "inactive" || event.GetSeq() != 3 {
+		t.Errorf("event = %v", event)
+	}
+}
+
+func TestToJobResult(t *testing.T) {
+	finished := time.Date(2026, 10, 18, 10, 0, 0, 0, time.UTC)
+	job := &domain.Job{
+		ID:         "job-1",
+		Type:       domain.JobTypeVerify,
+		State:      domain.JobStateSucceeded,
+		FinishedAt: &finished,
+		Result: domain.VerifyRun{
+			ID:     "run-1",
+			Tables: []domain.TableVerification{{Table: "public.orders", SourceRows: 1<<53 + 1}},
+		},
+	}
+	got := toJob(job)
+	if got.GetVerify().GetTables()[0].GetSourceRows() != 1<<53+1 || !got.GetFinishedAt().AsTime().Equal(finished) || got.GetStartedAt() != nil {
+		t.Errorf("verify job = %v", got)
+	}
+
+	job.Result = nil
+	if got := toJob(job); got.GetResult() != nil {
+		t.Errorf("reconcile job result = %v, want none", got.GetResult())
+	}
+}
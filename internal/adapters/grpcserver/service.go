@@ -0,0 +1,223 @@
+package grpcserver
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strconv"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/emptypb"
+
+	"replication-service/internal/adapters/grpcserver/managementpb"
+	"replication-service/internal/adapters/server"
+	"replication-service/internal/core/services/orchestrator"
+)
+
+// readOnlyMethods are not recorded in the audit trail.
+var readOnlyMethods = map[string]bool{
+	managementpb.Management_GetConfig_FullMethodName:    true,
+	managementpb.Management_ListSyncs_FullMethodName:    true,
+	managementpb.Management_GetSync_FullMethodName:      true,
+	managementpb.Management_GetJob_FullMethodName:       true,
+	managementpb.Management_StreamLogs_FullMethodName:   true,
+	managementpb.Management_StreamEvents_FullMethodName: true,
+}
+
+// configMethods change the configuration; their audit records list the changes they made.
+var configMethods = map[string]bool{
+	managementpb.Management_UpdateConfig_FullMethodName: true,
+	managementpb.Management_CreateSync_FullMethodName:   true,
+	managementpb.Management_UpdateSync_FullMethodName:   true,
+	managementpb.Management_DeleteSync_FullMethodName:   true,
+	managementpb.Management_PauseSync_FullMethodName:    true,
+	managementpb.Management_ResumeSync_FullMethodName:   true,
+}
+
+// managementServer implements the RPCs of management.proto. It is separate from GRPCServer,
+// whose Start and Stop run the server itself.
+type managementServer struct {
+	managementpb.UnimplementedManagementServer
+	service     *orchestrator.Service
+	broadcaster *server.LogBroadcaster
+}
+
+func (m *managementServer) GetConfig(ctx context.Context, _ *emptypb.Empty) (*managementpb.Config, error) {
+	m.setRevisionETag(ctx)
+	config, err := m.service.GetConfig(ctx)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return toConfig(config), nil
+}
+
+func (m *managementServer) UpdateConfig(ctx context.Context, req *managementpb.Config) (*emptypb.Empty, error) {
+	if err := m.service.UpdateConfig(ctx, fromConfig(req)); err != nil {
+		return nil, toStatus(err)
+	}
+	return &emptypb.Empty{}, nil
+}
+
+func (m *managementServer) ListSyncs(ctx context.Context, _ *emptypb.Empty) (*managementpb.ListSyncsResponse, error) {
+	syncs, err := m.service.ListSyncs(ctx)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	out := &managementpb.ListSyncsResponse{}
+	for _, sync := range syncs {
+		out.Syncs = append(out.Syncs, toSync(sync))
+	}
+	return out, nil
+}
+
+func (m *managementServer) GetSync(ctx context.Context, req *managementpb.SyncRequest) (*managementpb.Sync, error) {
+	m.setRevisionETag(ctx)
+	sync, err := m.service.GetSync(ctx, req.GetName())
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return toSync(*sync), nil
+}
+
+func (m *managementServer) CreateSync(ctx context.Context, req *managementpb.Sync) (*emptypb.Empty, error) {
+	if err := m.service.AddSync(ctx, fromSync(req)); err != nil {
+		return nil, toStatus(err)
+	}
+	return &emptypb.Empty{}, nil
+}
+
+func (m *managementServer) UpdateSync(ctx context.Context, req *managementpb.UpdateSyncRequest) (*emptypb.Empty, error) {
+	if req.GetName() == "" || req.GetSync() == nil {
+		return nil, status.Error(codes.InvalidArgument, "expected name and sync fields")
+	}
+	if err := m.service.UpdateSync(ctx, req.GetName(), fromSync(req.GetSync())); err != nil {
+		return nil, toStatus(err)
+	}
+	return &emptypb.Empty{}, nil
+}
+
+func (m *managementServer) DeleteSync(ctx context.Context, req *managementpb.SyncRequest) (*emptypb.Empty, error) {
+	if err := m.service.DeleteSync(ctx, req.GetName()); err != nil {
+		return nil, toStatus(err)
+	}
+	return &emptypb.Empty{}, nil
+}
+
+func (m *managementServer) PauseSync(ctx context.Context, req *managementpb.SyncRequest) (*emptypb.Empty, error) {
+	if err := m.service.PauseSync(ctx, req.GetName()); err != nil {
+		return nil, toStatus(err)
+	}
+	return &emptypb.Empty{}, nil
+}
+
+func (m *managementServer) ResumeSync(ctx context.Context, req *managementpb.SyncRequest) (*emptypb.Empty, error) {
+	if err := m.service.ResumeSync(ctx, req.GetName()); err != nil {
+		return nil, toStatus(err)
+	}
+	return &emptypb.Empty{}, nil
+}
+
+func (m *managementServer) Start(ctx context.Context, _ *emptypb.Empty) (*emptypb.Empty, error) {
+	if err := m.service.StartBucardoProcess(ctx); err != nil {
+		return nil, toStatus(err)
+	}
+	return &emptypb.Empty{}, nil
+}
+
+func (m *managementServer) Stop(ctx context.Context, _ *emptypb.Empty) (*emptypb.Empty, error) {
+	if err := m.service.StopBucardoProcess(ctx); err != nil {
+		return nil, toStatus(err)
+	}
+	return &emptypb.Empty{}, nil
+}
+
+func (m *managementServer) Reload(ctx context.Context, _ *emptypb.Empty) (*managementpb.Job, error) {
+	job, err := m.service.StartReconcileJob(ctx)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return toJob(job), nil
+}
+
+func (m *managementServer) GetJob(ctx context.Context, req *managementpb.JobRequest) (*managementpb.Job, error) {
+	job, err := m.service.GetJob(ctx, req.GetId())
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return toJob(job), nil
+}
+
+func (m *managementServer) StreamLogs(req *managementpb.StreamLogsRequest, stream grpc.ServerStreamingServer[managementpb.LogLine]) error {
+	query := url.Values{}
+	if req.GetTail() != 0 {
+		query.Set("tail", strconv.Itoa(int(req.GetTail())))
+	}
+	if req.GetSince() != nil {
+		query.Set("since", req.GetSince().AsTime().Format(time.RFC3339Nano))
+	}
+	for name, value := range map[string]string{
+		"level":     req.GetLevel(),
+		"component": req.GetComponent(),
+		"sync_name": req.GetSyncName(),
+		"db_name":   req.GetDbName(),
+	} {
+		if value != "" {
+			query.Set(name, value)
+		}
+	}
+	return m.stream(stream, server.SubscribeOptions{Query: query, Logs: true}, func(msg server.StreamMessage) error {
+		return stream.Send(toLogLine(msg.Seq, msg.Data))
+	})
+}
+
+func (m *managementServer) StreamEvents(_ *emptypb.Empty, stream grpc.ServerStreamingServer[managementpb.Event]) error {
+	return m.stream(stream, server.SubscribeOptions{Events: true}, func(msg server.StreamMessage) error {
+		event, err := toEvent(msg.Seq, msg.Data)
+		if err != nil {
+			return status.Error(codes.Internal, fmt.Sprintf("failed to convert event %d: %v", msg.Seq, err))
+		}
+		return stream.Send(event)
+	})
+}
+
+// stream forwards broadcaster messages to a gRPC stream with send until the client goes
+// away.
+func (m *managementServer) stream(stream grpc.ServerStream, opts server.SubscribeOptions, send func(server.StreamMessage) error) error {
+	ctx := stream.Context()
+	remoteAddr := ""
+	if p, ok := peer.FromContext(ctx); ok {
+		remoteAddr = p.Addr.String()
+	}
+	sub, err := m.broadcaster.Subscribe("grpc", remoteAddr, opts)
+	if err != nil {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+	defer sub.Close()
+
+	stream.SendHeader(metadata.MD{})
+	for {
+		msg, err := sub.Next(ctx)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return status.Error(codes.Unavailable, err.Error())
+		}
+		if err := send(msg); err != nil {
+			return err
+		}
+	}
+}
+
+// setRevisionETag sends the latest configuration revision in the etag response header, for
+// clients to send back in if-match.
+func (m *managementServer) setRevisionETag(ctx context.Context) {
+	if revision, err := m.service.LatestConfigRevision(ctx); err == nil {
+		grpc.SetHeader(ctx, metadata.Pairs("etag", fmt.Sprintf(`"%d"`, revision)))
+	}
+}
@@ -0,0 +1,195 @@
+package logger
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sort"
+	"strings"
+	"sync"
+
+	"replication-service/internal/core/domain"
+)
+
+// LevelController holds the level of the service's logs: a global level and optional
+// levels for single components, selected by the "component" attribute of a line. Levels can
+// be changed while the service runs; Handler applies them to a slog.Handler.
+type LevelController struct {
+	global     slog.LevelVar
+	minimum    slog.LevelVar // Lowest of the global and component levels.
+	mu         sync.RWMutex
+	components map[string]slog.Level
+}
+
+// NewLevelController creates a controller with the given global level.
+func NewLevelController(level slog.Level) *LevelController {
+	c := &LevelController{components: make(map[string]slog.Level)}
+	c.global.Set(level)
+	c.minimum.Set(level)
+	return c
+}
+
+// ParseLevel parses one of domain.ServiceLogLevels, in any case.
+func ParseLevel(level string) (slog.Level, error) {
+	var l slog.Level
+	for _, name := range domain.ServiceLogLevels {
+		if strings.EqualFold(level, name) {
+			err := l.UnmarshalText([]byte(name))
+			return l, err
+		}
+	}
+	return l, fmt.Errorf("invalid log level %q, expected one of %s", level, strings.Join(domain.ServiceLogLevels, ", "))
+}
+
+// ParseComponentLevels parses a comma-separated list of component=level pairs, as in the
+// LOG_COMPONENT_LEVELS environment variable.
+func ParseComponentLevels(list string) (map[string]slog.Level, error) {
+	levels := make(map[string]slog.Level)
+	for _, pair := range strings.Split(list, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+		component, level, ok := strings.Cut(pair, "=")
+		if !ok || strings.TrimSpace(component) == "" {
+			return nil, fmt.Errorf("invalid component level %q, expected component=level", pair)
+		}
+		l, err := ParseLevel(strings.TrimSpace(level))
+		if err != nil {
+			return nil, fmt.Errorf("component %s: %w", component, err)
+		}
+		levels[strings.TrimSpace(component)] = l
+	}
+	return levels, nil
+}
+
+// Levels returns the global level and the component overrides.
+func (c *LevelController) Levels() domain.ServiceLogConfig {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	levels := domain.ServiceLogConfig{Level: levelName(c.global.Level())}
+	if len(c.components) > 0 {
+		levels.Components = make(map[string]string, len(c.components))
+		for component, level := range c.components {
+			levels.Components[component] = levelName(level)
+		}
+	}
+	return levels
+}
+
+// SetLevel sets the level of a component, or the global level when component is empty. An
+// empty level removes the override of a component.
+func (c *LevelController) SetLevel(component, level string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if component != "" && level == "" {
+		delete(c.components, component)
+	} else {
+		l, err := ParseLevel(level)
+		if err != nil {
+			return err
+		}
+		if component == "" {
+			c.global.Set(l)
+		} else {
+			c.components[component] = l
+		}
+	}
+
+	minimum := c.global.Level()
+	for _, l := range c.components {
+		minimum = min(minimum, l)
+	}
+	c.minimum.Set(minimum)
+	return nil
+}
+
+// SetComponentLevels sets the level of each listed component. It stops at the first level
+// that is not one of domain.ServiceLogLevels.
+func (c *LevelController) SetComponentLevels(levels map[string]slog.Level) error {
+	components := make([]string, 0, len(levels))
+	for component := range levels {
+		components = append(components, component)
+	}
+	sort.Strings(components)
+	for _, component := range components {
+		if err := c.SetLevel(component, levels[component].String()); err != nil {
+			return fmt.Errorf("component %s: %w", component, err)
+		}
+	}
+	return nil
+}
+
+// level returns the level that applies to the lines of component.
+func (c *LevelController) level(component string) slog.Level {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	if l, ok := c.components[component]; ok {
+		return l
+	}
+	return c.global.Level()
+}
+
+// Handler wraps next so that it only receives lines at or above the level of their
+// component. next should accept every level.
+func (c *LevelController) Handler(next slog.Handler) slog.Handler {
+	return &levelHandler{controller: c, next: next}
+}
+
+func levelName(l slog.Level) string {
+	return strings.ToLower(l.String())
+}
+
+// levelHandler drops the lines below the level of their component. The component is known
+// up front for loggers made with With("component", ...); otherwise it is looked up in the
+// attributes of each line.
+type levelHandler struct {
+	controller *LevelController
+	next       slog.Handler
+	component  string
+	hasGroup   bool // Attributes added after WithGroup cannot name the component.
+}
+
+func (h *levelHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	if h.component != "" {
+		return level >= h.controller.level(h.component)
+	}
+	return level >= h.controller.minimum.Level()
+}
+
+func (h *levelHandler) Handle(ctx context.Context, r slog.Record) error {
+	component := h.component
+	if component == "" && !h.hasGroup {
+		r.Attrs(func(a slog.Attr) bool {
+			if a.Key == "component" {
+				component = a.Value.String()
+				return false
+			}
+			return true
+		})
+	}
+	if r.Level < h.controller.level(component) {
+		return nil
+	}
+	return h.next.Handle(ctx, r)
+}
+
+func (h *levelHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	clone := *h
+	clone.next = h.next.WithAttrs(attrs)
+	if !h.hasGroup {
+		for _, a := range attrs {
+			if a.Key == "component" {
+				clone.component = a.Value.String()
+			}
+		}
+	}
+	return &clone
+}
+
+func (h *levelHandler) WithGroup(name string) slog.Handler {
+	clone := *h
+	clone.next = h.next.WithGroup(name)
+	clone.hasGroup = true
+	return &clone
+}
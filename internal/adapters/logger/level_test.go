@@ -0,0 +1,85 @@
+package logger
+
+import (
+	"bytes"
+	"fmt"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func TestLevelHandler(t *testing.T) {
+	tests := []struct {
+		name  string
+		log   func(l *slog.Logger)
+		shown bool
+	}{
+		{"component bound with With", func(l *slog.Logger) { l.With("component", "jobs").Debug("m") }, true},
+		{"quieter component bound with With", func(l *slog.Logger) { l.With("component", "bucardo_log").Info("m") }, false},
+		{"component attribute of the line", func(l *slog.Logger) { l.Debug("m", "component", "jobs") }, true},
+		{"quieter component attribute of the line", func(l *slog.Logger) { l.Info("m", "component", "bucardo_log") }, false},
+		{"no component below the global level", func(l *slog.Logger) { l.Debug("m") }, false},
+		{"no component at the global level", func(l *slog.Logger) { l.Info("m") }, true},
+		{"unknown component", func(l *slog.Logger) { l.Debug("m", "component", "api") }, false},
+		{"component attribute in a group", func(l *slog.Logger) { l.WithGroup("g").Debug("m", "component", "jobs") }, false},
+		{"component bound in a group", func(l *slog.Logger) { l.WithGroup("g").With("component", "jobs").Debug("m") }, false},
+		{"component bound before a group", func(l *slog.Logger) { l.With("component", "jobs").WithGroup("g").Debug("m") }, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := NewLevelController(slog.LevelInfo)
+			if err := c.SetComponentLevels(map[string]slog.Level{"jobs": slog.LevelDebug, "bucardo_log": slog.LevelWarn}); err != nil {
+				t.Fatal(err)
+			}
+			var buf bytes.Buffer
+			tt.log(slog.New(c.Handler(slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}))))
+			if shown := buf.Len() > 0; shown != tt.shown {
+				t.Errorf("shown = %t, want %t: %q", shown, tt.shown, buf.String())
+			}
+		})
+	}
+}
+
+func TestLevelHandlerFollowsLevelChanges(t *testing.T) {
+	c := NewLevelController(slog.LevelInfo)
+	var buf bytes.Buffer
+	root := slog.New(c.Handler(slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug})))
+	jobs := root.With("component", "jobs")
+
+	// Loggers made before a change follow it.
+	steps := []struct {
+		component, level string
+		want             string
+	}{
+		{"jobs", "debug", "jobs=true root=false"},
+		{"", "debug", "jobs=true root=true"},
+		{"jobs", "error", "jobs=false root=true"},
+		{"jobs", "", "jobs=true root=true"}, // Removing the override falls back to the global level.
+		{"", "warn", "jobs=false root=false"},
+	}
+	for _, step := range steps {
+		if err := c.SetLevel(step.component, step.level); err != nil {
+			t.Fatal(err)
+		}
+		buf.Reset()
+		jobs.Debug("jobs line")
+		jobsShown := strings.Contains(buf.String(), "jobs line")
+		buf.Reset()
+		root.Debug("root line")
+		rootShown := strings.Contains(buf.String(), "root line")
+		if got := fmt.Sprintf("jobs=%t root=%t", jobsShown, rootShown); got != step.want {
+			t.Errorf("after SetLevel(%q, %q): debug lines shown %s, want %s", step.component, step.level, got, step.want)
+		}
+	}
+}
+
+func TestSetComponentLevels(t *testing.T) {
+	c := NewLevelController(slog.LevelInfo)
+	err := c.SetComponentLevels(map[string]slog.Level{"jobs": slog.LevelDebug, "verifier": slog.LevelDebug + 2})
+	if err == nil || !strings.HasPrefix(err.Error(), "component verifier: invalid log level") {
+		t.Fatalf("SetComponentLevels() error = %v, want the unnamed level rejected", err)
+	}
+	if got := c.Levels().Components; got["jobs"] != "debug" {
+		t.Errorf("components = %v, want the levels before the invalid one applied", got)
+	}
+}
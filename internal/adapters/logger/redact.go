@@ -0,0 +1,153 @@
+package logger
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// redactedValue replaces secrets in log lines.
+const redactedValue = "*****"
+
+// minSecretLength is the length below which registered secrets are ignored. Replacing very
+// short strings everywhere would garble the logs; such secrets are still caught by the
+// patterns when they follow a known key.
+const minSecretLength = 4
+
+// secretPatterns match secrets by their context, whether or not they were registered.
+var secretPatterns = []struct {
+	re          *regexp.Regexp
+	replacement string
+}{
+	// PGPASSWORD=, password=, pass= and --dbpass= in commands and connection strings.
+	{regexp.MustCompile(`(?i)((?:pg|db)?pass(?:word)?\s*=\s*)(?:"[^"]*"|'[^']*'|[^\s&;'"]+)`), "${1}" + redactedValue},
+	// SQL: ALTER USER ... WITH PASSWORD '...'.
+	{regexp.MustCompile(`(?i)(password\s+')[^']*`), "${1}" + redactedValue},
+	// Credentials in postgres:// and postgresql:// URLs.
+	{regexp.MustCompile(`(?i)(postgres(?:ql)?://[^:/@\s]*:)[^@\s]+(@)`), "${1}" + redactedValue + "${2}"},
+	// JSON fields such as "pass": "..." in logged configuration.
+	{regexp.MustCompile(`(?i)("(?:pass|password|secret|smtp_pass)"\s*:\s*")(?:[^"\\]|\\.)*`), "${1}" + redactedValue},
+}
+
+// Redactor scrubs secrets from log lines: every registered secret value, such as resolved
+// passwords, and the values of known secret patterns. It implements ports.SecretRegistry;
+// Handler applies it to a slog.Handler.
+type Redactor struct {
+	mu       sync.RWMutex
+	secrets  map[string]bool
+	replacer *strings.Replacer
+}
+
+// NewRedactor creates a Redactor that knows no secrets yet.
+func NewRedactor() *Redactor {
+	return &Redactor{secrets: make(map[string]bool)}
+}
+
+// AddSecret registers secret values to remove from every later log line. Empty and very
+// short values are ignored.
+func (r *Redactor) AddSecret(secrets ...string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	added := false
+	for _, secret := range secrets {
+		if len(secret) >= minSecretLength && !r.secrets[secret] {
+			r.secrets[secret] = true
+			added = true
+		}
+	}
+	if !added {
+		return
+	}
+	pairs := make([]string, 0, 2*len(r.secrets))
+	for secret := range r.secrets {
+		pairs = append(pairs, secret, redactedValue)
+	}
+	r.replacer = strings.NewReplacer(pairs...)
+}
+
+// Redact returns s without secrets.
+func (r *Redactor) Redact(s string) string {
+	r.mu.RLock()
+	replacer := r.replacer
+	r.mu.RUnlock()
+	if replacer != nil {
+		s = replacer.Replace(s)
+	}
+	for _, p := range secretPatterns {
+		s = p.re.ReplaceAllString(s, p.replacement)
+	}
+	return s
+}
+
+// Handler wraps next so that it receives log lines with secrets removed from the message
+// and from attribute values: strings, errors and Stringers, and the fmt form of other
+// values such as structs, maps and slices.
+func (r *Redactor) Handler(next slog.Handler) slog.Handler {
+	return &redactHandler{redactor: r, next: next}
+}
+
+type redactHandler struct {
+	redactor *Redactor
+	next     slog.Handler
+}
+
+func (h *redactHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.next.Enabled(ctx, level)
+}
+
+func (h *redactHandler) Handle(ctx context.Context, r slog.Record) error {
+	redacted := slog.NewRecord(r.Time, r.Level, h.redactor.Redact(r.Message), r.PC)
+	r.Attrs(func(a slog.Attr) bool {
+		redacted.AddAttrs(h.redactAttr(a))
+		return true
+	})
+	return h.next.Handle(ctx, redacted)
+}
+
+// WithAttrs redacts attributes bound to a logger once, with the secrets known at that time.
+func (h *redactHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	redacted := make([]slog.Attr, len(attrs))
+	for i, a := range attrs {
+		redacted[i] = h.redactAttr(a)
+	}
+	return &redactHandler{redactor: h.redactor, next: h.next.WithAttrs(redacted)}
+}
+
+func (h *redactHandler) WithGroup(name string) slog.Handler {
+	return &redactHandler{redactor: h.redactor, next: h.next.WithGroup(name)}
+}
+
+func (h *redactHandler) redactAttr(a slog.Attr) slog.Attr {
+	value := a.Value.Resolve()
+	switch value.Kind() {
+	case slog.KindString:
+		return slog.String(a.Key, h.redactor.Redact(value.String()))
+	case slog.KindGroup:
+		group := value.Group()
+		attrs := make([]any, len(group))
+		for i, ga := range group {
+			attrs[i] = h.redactAttr(ga)
+		}
+		return slog.Group(a.Key, attrs...)
+	case slog.KindAny:
+		switch v := value.Any().(type) {
+		case error:
+			return slog.String(a.Key, h.redactor.Redact(v.Error()))
+		case fmt.Stringer:
+			return slog.String(a.Key, h.redactor.Redact(v.String()))
+		case []byte:
+			return slog.String(a.Key, h.redactor.Redact(string(v)))
+		default:
+			// Maps, slices and structs, such as a logged domain.Database, are checked in their
+			// fmt form, and logged in it when it holds a secret.
+			s := fmt.Sprintf("%+v", v)
+			if redacted := h.redactor.Redact(s); redacted != s {
+				return slog.String(a.Key, redacted)
+			}
+		}
+	}
+	return slog.Attr{Key: a.Key, Value: value}
+}
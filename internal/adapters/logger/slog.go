@@ -1,6 +1,7 @@
 package logger
 
 import (
+	"context"
 	"log/slog"
 
 	"replication-service/internal/core/ports"
@@ -9,29 +10,36 @@ import (
 // SlogAdapter wraps slog.Logger to implement the ports.Logger interface.
 type SlogAdapter struct {
 	logger *slog.Logger
+	ctx    context.Context
 }
 
 // NewSlogAdapter creates a new SlogAdapter.
 func NewSlogAdapter(logger *slog.Logger) *SlogAdapter {
-	return &SlogAdapter{logger: logger}
+	return &SlogAdapter{logger: logger, ctx: context.Background()}
 }
 
 func (s *SlogAdapter) Info(msg string, args ...any) {
-	s.logger.Info(msg, args...)
+	s.logger.InfoContext(s.ctx, msg, args...)
 }
 
 func (s *SlogAdapter) Warn(msg string, args ...any) {
-	s.logger.Warn(msg, args...)
+	s.logger.WarnContext(s.ctx, msg, args...)
 }
 
 func (s *SlogAdapter) Error(msg string, args ...any) {
-	s.logger.Error(msg, args...)
+	s.logger.ErrorContext(s.ctx, msg, args...)
 }
 
 func (s *SlogAdapter) Debug(msg string, args ...any) {
-	s.logger.Debug(msg, args...)
+	s.logger.DebugContext(s.ctx, msg, args...)
 }
 
 func (s *SlogAdapter) With(args ...any) ports.Logger {
-	return NewSlogAdapter(s.logger.With(args...))
+	return &SlogAdapter{logger: s.logger.With(args...), ctx: s.ctx}
+}
+
+// WithContext returns a logger whose lines carry the trace and span IDs of ctx, when the
+// handler is wrapped with TraceHandler.
+func (s *SlogAdapter) WithContext(ctx context.Context) ports.Logger {
+	return &SlogAdapter{logger: s.logger, ctx: ctx}
 }
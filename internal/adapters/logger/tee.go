@@ -0,0 +1,52 @@
+package logger
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+)
+
+// TeeHandler passes every line to several handlers, so that each can use its own format.
+type TeeHandler struct {
+	handlers []slog.Handler
+}
+
+// NewTeeHandler creates a handler that writes to all the given handlers.
+func NewTeeHandler(handlers ...slog.Handler) *TeeHandler {
+	return &TeeHandler{handlers: handlers}
+}
+
+func (t *TeeHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	for _, h := range t.handlers {
+		if h.Enabled(ctx, level) {
+			return true
+		}
+	}
+	return false
+}
+
+func (t *TeeHandler) Handle(ctx context.Context, r slog.Record) error {
+	var errs []error
+	for _, h := range t.handlers {
+		if h.Enabled(ctx, r.Level) {
+			errs = append(errs, h.Handle(ctx, r.Clone()))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (t *TeeHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	handlers := make([]slog.Handler, len(t.handlers))
+	for i, h := range t.handlers {
+		handlers[i] = h.WithAttrs(attrs)
+	}
+	return NewTeeHandler(handlers...)
+}
+
+func (t *TeeHandler) WithGroup(name string) slog.Handler {
+	handlers := make([]slog.Handler, len(t.handlers))
+	for i, h := range t.handlers {
+		handlers[i] = h.WithGroup(name)
+	}
+	return NewTeeHandler(handlers...)
+}
@@ -0,0 +1,39 @@
+package logger
+
+import (
+	"context"
+	"log/slog"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+// TraceHandler adds the trace_id and span_id of the span in the context of a line, if any,
+// so that log lines can be matched with traces.
+type TraceHandler struct {
+	next slog.Handler
+}
+
+// NewTraceHandler wraps next with trace IDs.
+func NewTraceHandler(next slog.Handler) *TraceHandler {
+	return &TraceHandler{next: next}
+}
+
+func (h *TraceHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.next.Enabled(ctx, level)
+}
+
+func (h *TraceHandler) Handle(ctx context.Context, r slog.Record) error {
+	if span := trace.SpanContextFromContext(ctx); span.IsValid() {
+		r = r.Clone()
+		r.AddAttrs(slog.String("trace_id", span.TraceID().String()), slog.String("span_id", span.SpanID().String()))
+	}
+	return h.next.Handle(ctx, r)
+}
+
+func (h *TraceHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return NewTraceHandler(h.next.WithAttrs(attrs))
+}
+
+func (h *TraceHandler) WithGroup(name string) slog.Handler {
+	return NewTraceHandler(h.next.WithGroup(name))
+}
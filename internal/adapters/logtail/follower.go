@@ -0,0 +1,256 @@
+package logtail
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"syscall"
+	"time"
+
+	"replication-service/internal/core/ports"
+)
+
+const (
+	defaultPollInterval = 250 * time.Millisecond
+	saveInterval        = time.Second
+	readChunkSize       = 64 * 1024
+	// MaxLineLength bounds how much of a line without a newline is buffered. Longer lines
+	// are delivered in pieces of this size rather than dropped.
+	MaxLineLength = 8 * 1024 * 1024
+)
+
+// position identifies a byte offset within a particular file, so a saved offset is only
+// reused when the file has not been replaced since.
+type position struct {
+	Device uint64 `json:"device"`
+	Inode  uint64 `json:"inode"`
+	Offset int64  `json:"offset"`
+}
+
+// Follower reads lines appended to a file, like `tail -F`, without a child process.
+// It follows the path across truncation, rotation and late creation, and can persist its
+// offset to a state file so a restarted follower continues where the last one stopped.
+type Follower struct {
+	logger       ports.Logger
+	path         string
+	statePath    string
+	PollInterval time.Duration
+
+	start    position
+	hasStart bool
+}
+
+// NewFollower creates a Follower for path. The starting point is fixed here: the offset
+// saved in statePath if it still refers to the same file, otherwise the current end of
+// the file. A file that does not exist yet is read from its beginning once it appears.
+// statePath may be empty to disable offset persistence.
+func NewFollower(logger ports.Logger, path, statePath string) *Follower {
+	f := &Follower{
+		logger:       logger,
+		path:         path,
+		statePath:    statePath,
+		PollInterval: defaultPollInterval,
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return f
+	}
+	current := identify(info)
+	if saved, ok := f.loadState(); ok && saved.Device == current.Device && saved.Inode == current.Inode && saved.Offset <= info.Size() {
+		f.start = saved
+	} else {
+		current.Offset = info.Size()
+		f.start = current
+	}
+	f.hasStart = true
+	return f
+}
+
+// Follow calls handle for every complete line appended to the file, without its trailing
+// newline, until ctx is cancelled. handle is called from the calling goroutine.
+func (f *Follower) Follow(ctx context.Context, handle func(line string)) error {
+	var (
+		file    *os.File
+		pos     position
+		pending []byte
+		saved   = f.start
+		buf     = make([]byte, readChunkSize)
+	)
+	defer func() {
+		if file != nil {
+			file.Close()
+		}
+		f.saveState(pos, saved)
+	}()
+
+	lastSave := time.Now()
+	ticker := time.NewTicker(f.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		if file == nil {
+			file, pos = f.open()
+			pending = pending[:0]
+		}
+
+		if file != nil {
+			// Truncated in place: start over from the beginning.
+			if info, err := file.Stat(); err == nil && info.Size() < pos.Offset+int64(len(pending)) {
+				f.logger.Info("Log file was truncated, reading from the start", "component", "log_follower", "path", f.path)
+				file.Seek(0, io.SeekStart)
+				pos.Offset = 0
+				pending = pending[:0]
+			}
+
+			var err error
+			pending, err = f.readLines(file, &pos, pending, buf, handle)
+			if err != nil {
+				f.logger.Warn("Error reading log file", "component", "log_follower", "path", f.path, "error", err)
+			}
+
+			// Rotated or removed: the old file has been drained, switch to the new one.
+			if f.replaced(file) {
+				f.logger.Info("Log file was rotated, following the new file", "component", "log_follower", "path", f.path)
+				if len(pending) > 0 {
+					handle(string(pending))
+				}
+				file.Close()
+				file = nil
+				f.start, f.hasStart = position{}, false
+				continue
+			}
+		}
+
+		if time.Since(lastSave) >= saveInterval {
+			saved = f.saveState(pos, saved)
+			lastSave = time.Now()
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// open opens the followed path and seeks to where reading should resume.
+func (f *Follower) open() (*os.File, position) {
+	file, err := os.Open(f.path)
+	if err != nil {
+		if !errors.Is(err, os.ErrNotExist) {
+			f.logger.Warn("Could not open log file", "component", "log_follower", "path", f.path, "error", err)
+		}
+		return nil, position{}
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return nil, position{}
+	}
+
+	pos := identify(info)
+	if f.hasStart && f.start.Device == pos.Device && f.start.Inode == pos.Inode && f.start.Offset <= info.Size() {
+		pos.Offset = f.start.Offset
+	}
+	if _, err := file.Seek(pos.Offset, io.SeekStart); err != nil {
+		file.Close()
+		return nil, position{}
+	}
+	f.logger.Info("Following log file", "component", "log_follower", "path", f.path, "offset", pos.Offset)
+	return file, pos
+}
+
+// readLines reads everything currently available and hands complete lines to handle.
+// pos.Offset only advances past delivered lines, so a partial line is re-read after a restart.
+func (f *Follower) readLines(file *os.File, pos *position, pending, buf []byte, handle func(string)) ([]byte, error) {
+	for {
+		n, err := file.Read(buf)
+		if n > 0 {
+			pending = append(pending, buf[:n]...)
+			for {
+				i := bytes.IndexByte(pending, '\n')
+				if i < 0 {
+					break
+				}
+				handle(string(bytes.TrimSuffix(pending[:i], []byte("\r"))))
+				pos.Offset += int64(i + 1)
+				pending = pending[i+1:]
+			}
+			for len(pending) >= MaxLineLength {
+				handle(string(pending[:MaxLineLength]))
+				pos.Offset += MaxLineLength
+				pending = pending[MaxLineLength:]
+			}
+			// Compact so the buffer does not grow with everything ever read.
+			pending = append([]byte(nil), pending...)
+		}
+		if err == io.EOF {
+			return pending, nil
+		}
+		if err != nil {
+			return pending, err
+		}
+	}
+}
+
+// replaced reports whether the path now refers to a different file than the open one.
+func (f *Follower) replaced(file *os.File) bool {
+	current, err := os.Stat(f.path)
+	if err != nil {
+		// Removed and not recreated yet; keep the old file until a new one shows up.
+		return false
+	}
+	open, err := file.Stat()
+	if err != nil {
+		return true
+	}
+	return !os.SameFile(open, current)
+}
+
+func (f *Follower) loadState() (position, bool) {
+	if f.statePath == "" {
+		return position{}, false
+	}
+	data, err := os.ReadFile(f.statePath)
+	if err != nil {
+		return position{}, false
+	}
+	var pos position
+	if err := json.Unmarshal(data, &pos); err != nil {
+		f.logger.Warn("Ignoring unreadable log offset file", "component", "log_follower", "path", f.statePath, "error", err)
+		return position{}, false
+	}
+	return pos, true
+}
+
+// saveState writes pos to the state file if it differs from the last saved position and
+// returns the position now on disk.
+func (f *Follower) saveState(pos, saved position) position {
+	if f.statePath == "" || pos == saved || (pos == position{}) {
+		return saved
+	}
+	data, _ := json.Marshal(pos)
+	tmp := filepath.Join(filepath.Dir(f.statePath), "."+filepath.Base(f.statePath)+".tmp")
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		f.logger.Warn("Could not save log offset", "component", "log_follower", "path", f.statePath, "error", err)
+		return saved
+	}
+	if err := os.Rename(tmp, f.statePath); err != nil {
+		f.logger.Warn("Could not save log offset", "component", "log_follower", "path", f.statePath, "error", err)
+		return saved
+	}
+	return pos
+}
+
+func identify(info os.FileInfo) position {
+	if st, ok := info.Sys().(*syscall.Stat_t); ok {
+		return position{Device: uint64(st.Dev), Inode: st.Ino}
+	}
+	return position{}
+}
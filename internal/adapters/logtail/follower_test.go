@@ -0,0 +1,174 @@
+package logtail
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"replication-service/internal/adapters/logger"
+)
+
+// follow runs a follower for path until the returned stop function is called, which waits
+// for it to save its offset. Lines are delivered on the channel.
+func follow(t *testing.T, path, statePath string) (<-chan string, func()) {
+	t.Helper()
+	f := NewFollower(logger.NewSlogAdapter(slog.New(slog.NewTextHandler(io.Discard, nil))), path, statePath)
+	f.PollInterval = 10 * time.Millisecond
+
+	ctx, cancel := context.WithCancel(context.Background())
+	lines := make(chan string, 100)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		f.Follow(ctx, func(line string) { lines <- line })
+	}()
+	stop := func() {
+		cancel()
+		<-done
+	}
+	t.Cleanup(stop)
+	return lines, stop
+}
+
+// expect checks that the next lines delivered are want.
+func expect(t *testing.T, lines <-chan string, want ...string) {
+	t.Helper()
+	for _, w := range want {
+		select {
+		case got := <-lines:
+			if got != w {
+				t.Fatalf("line = %q, want %q", shorten(got), shorten(w))
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("no line delivered, want %q", shorten(w))
+		}
+	}
+}
+
+func shorten(s string) string {
+	if len(s) > 40 {
+		return s[:20] + "..." + s[len(s)-20:]
+	}
+	return s
+}
+
+func write(t *testing.T, path, data string) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func appendTo(t *testing.T, path, data string) {
+	t.Helper()
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	if _, err := f.WriteString(data); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestFollowAppends(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "log.bucardo")
+	write(t, path, "before the follower started\n")
+	lines, _ := follow(t, path, "")
+
+	appendTo(t, path, "first\nsecond\r\nthi")
+	expect(t, lines, "first", "second")
+	appendTo(t, path, "rd\n")
+	expect(t, lines, "third")
+}
+
+func TestFollowLateCreation(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "log.bucardo")
+	lines, _ := follow(t, path, "")
+
+	appendTo(t, path, "first\n")
+	expect(t, lines, "first")
+}
+
+func TestFollowTruncation(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "log.bucardo")
+	write(t, path, "")
+	lines, _ := follow(t, path, "")
+
+	appendTo(t, path, "a line before truncation\n")
+	expect(t, lines, "a line before truncation")
+	write(t, path, "after\n")
+	expect(t, lines, "after")
+}
+
+func TestFollowRotation(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "log.bucardo")
+	write(t, path, "")
+	lines, _ := follow(t, path, "")
+
+	appendTo(t, path, "old file\n")
+	expect(t, lines, "old file")
+
+	// Rotated by rename: the rest of the old file is read before switching to the new one.
+	if err := os.Rename(path, path+".1"); err != nil {
+		t.Fatal(err)
+	}
+	appendTo(t, path+".1", "written after the rename\nunterminated")
+	appendTo(t, path, "new file\n")
+	expect(t, lines, "written after the rename", "unterminated", "new file")
+}
+
+func TestFollowResumesFromState(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "log.bucardo")
+	statePath := filepath.Join(dir, "offset.json")
+	write(t, path, "")
+
+	lines, stop := follow(t, path, statePath)
+	appendTo(t, path, "first\npartial")
+	expect(t, lines, "first")
+	stop()
+
+	// Lines written while no follower ran are delivered by the next one, and so is the
+	// line that was incomplete when the last one stopped.
+	appendTo(t, path, " line\nwhile stopped\n")
+	lines, _ = follow(t, path, statePath)
+	expect(t, lines, "partial line", "while stopped")
+}
+
+func TestFollowIgnoresStateOfReplacedFile(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "log.bucardo")
+	statePath := filepath.Join(dir, "offset.json")
+	write(t, path, "")
+
+	lines, stop := follow(t, path, statePath)
+	appendTo(t, path, "first\n")
+	expect(t, lines, "first")
+	stop()
+
+	// The saved offset belongs to the old file, so the new one is read from its end.
+	if err := os.Rename(path, path+".1"); err != nil {
+		t.Fatal(err)
+	}
+	write(t, path, "already there\n")
+	lines, _ = follow(t, path, statePath)
+	appendTo(t, path, "appended\n")
+	expect(t, lines, "appended")
+}
+
+func TestFollowLongLine(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "log.bucardo")
+	write(t, path, "")
+	lines, _ := follow(t, path, "")
+
+	long := strings.Repeat("x", MaxLineLength) + "tail"
+	appendTo(t, path, long+"\nnext\n")
+	expect(t, lines, long[:MaxLineLength], "tail", "next")
+}
@@ -0,0 +1,195 @@
+package notify
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"replication-service/internal/core/domain"
+	"replication-service/internal/core/ports"
+)
+
+const (
+	defaultDedupWindow = 300 * time.Second
+	defaultMaxRetries  = 3
+	initialBackoff     = time.Second
+	maxBackoff         = 30 * time.Second
+)
+
+var severityRank = map[string]int{
+	domain.SeverityInfo:    0,
+	domain.SeverityWarning: 1,
+	domain.SeverityError:   2,
+}
+
+// Dispatcher implements the Notifier port by delivering events to the channels configured
+// in bucardo.json. Channels are re-read on every event so configuration changes apply
+// without a restart. Deliveries run in the background and are retried with backoff.
+type Dispatcher struct {
+	logger  ports.Logger
+	config  ports.ConfigProvider
+	client  *http.Client
+	backoff time.Duration
+
+	mu        sync.Mutex
+	lastSent  map[string]time.Time
+	wg        sync.WaitGroup
+	done      chan struct{} // Closed by Close to stop retrying.
+	closeOnce sync.Once
+}
+
+// NewDispatcher creates a new Dispatcher.
+func NewDispatcher(logger ports.Logger, config ports.ConfigProvider) *Dispatcher {
+	return &Dispatcher{
+		logger:   logger,
+		config:   config,
+		client:   &http.Client{Timeout: 10 * time.Second},
+		backoff:  initialBackoff,
+		lastSent: make(map[string]time.Time),
+		done:     make(chan struct{}),
+	}
+}
+
+// Notify sends an event to every channel whose filters match it.
+func (d *Dispatcher) Notify(ctx context.Context, event domain.Event) {
+	if event.Time.IsZero() {
+		event.Time = time.Now()
+	}
+	if event.Severity == "" {
+		event.Severity = domain.SeverityInfo
+	}
+
+	config, err := d.config.LoadConfig(ctx)
+	if err != nil {
+		d.logger.Warn("Could not load notification channels", "component", "notifier", "error", err)
+		return
+	}
+
+	for _, channel := range config.Notifications {
+		if !matches(channel, event) || d.isDuplicate(channel, event) {
+			continue
+		}
+		d.wg.Add(1)
+		go func(channel domain.NotificationChannel) {
+			defer d.wg.Done()
+			d.deliver(channel, event)
+		}(channel)
+	}
+}
+
+// Close stops retrying failed deliveries and waits until the attempts in flight have
+// finished or ctx is done.
+func (d *Dispatcher) Close(ctx context.Context) error {
+	d.closeOnce.Do(func() { close(d.done) })
+	done := make(chan struct{})
+	go func() {
+		d.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func matches(channel domain.NotificationChannel, event domain.Event) bool {
+	if severityRank[event.Severity] < severityRank[channel.MinSeverity] {
+		return false
+	}
+	if len(channel.Events) == 0 {
+		return true
+	}
+	for _, t := range channel.Events {
+		if t == event.Type {
+			return true
+		}
+	}
+	return false
+}
+
+// isDuplicate reports whether an identical event was sent to the channel within its
+// dedup window, and records the event otherwise.
+func (d *Dispatcher) isDuplicate(channel domain.NotificationChannel, event domain.Event) bool {
+	window := defaultDedupWindow
+	if channel.DedupSeconds > 0 {
+		window = time.Duration(channel.DedupSeconds) * time.Second
+	}
+	key := strings.Join([]string{channel.Name, event.Type, event.SyncName, event.Message}, "\x00")
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if last, ok := d.lastSent[key]; ok && event.Time.Sub(last) < window {
+		return true
+	}
+	d.lastSent[key] = event.Time
+	for k, t := range d.lastSent {
+		if event.Time.Sub(t) >= window {
+			delete(d.lastSent, k)
+		}
+	}
+	return false
+}
+
+func (d *Dispatcher) deliver(channel domain.NotificationChannel, event domain.Event) {
+	channelLogger := d.logger.With("component", "notifier", "channel", channel.Name, "channel_type", channel.Type, "event_type", event.Type)
+
+	retries := defaultMaxRetries
+	if channel.MaxRetries > 0 {
+		retries = channel.MaxRetries
+	}
+	backoff := d.backoff
+
+	var err error
+	for attempt := 0; attempt <= retries; attempt++ {
+		if attempt > 0 {
+			select {
+			case <-time.After(backoff):
+			case <-d.done:
+				channelLogger.Error("Giving up on notification at shutdown", "attempt", attempt, "error", err)
+				return
+			}
+			backoff = min(backoff*2, maxBackoff)
+		}
+		if err = d.send(channel, event); err == nil {
+			channelLogger.Debug("Notification delivered", "attempt", attempt+1)
+			return
+		}
+		channelLogger.Warn("Notification delivery failed", "attempt", attempt+1, "error", err)
+	}
+	channelLogger.Error("Giving up on notification", "error", err)
+}
+
+func (d *Dispatcher) send(channel domain.NotificationChannel, event domain.Event) error {
+	switch channel.Type {
+	case "webhook":
+		return d.sendWebhook(channel, event)
+	case "slack":
+		return d.sendSlack(channel, event)
+	case "email":
+		return sendEmail(channel, event)
+	default:
+		return fmt.Errorf("unknown notification channel type %q", channel.Type)
+	}
+}
+
+var nonAlnum = regexp.MustCompile(`[^A-Z0-9]+`)
+
+// resolveSecret returns value, or the BUCARDO_NOTIFY_<NAME> environment variable when value is "env".
+func resolveSecret(channel domain.NotificationChannel, value string) (string, error) {
+	if value != "env" {
+		return value, nil
+	}
+	envVar := "BUCARDO_NOTIFY_" + nonAlnum.ReplaceAllString(strings.ToUpper(channel.Name), "_")
+	secret := os.Getenv(envVar)
+	if secret == "" {
+		return "", fmt.Errorf("environment variable %s not set for notification channel %s", envVar, channel.Name)
+	}
+	return secret, nil
+}
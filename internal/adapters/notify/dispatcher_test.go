@@ -0,0 +1,159 @@
+package notify
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"replication-service/internal/adapters/fake"
+	"replication-service/internal/adapters/logger"
+	"replication-service/internal/core/domain"
+)
+
+// newTestDispatcher returns a dispatcher for the channels that retries without waiting.
+func newTestDispatcher(channels ...domain.NotificationChannel) *Dispatcher {
+	config := fake.NewConfigProvider(&domain.BucardoConfig{Notifications: channels})
+	d := NewDispatcher(logger.NewSlogAdapter(slog.New(slog.NewTextHandler(io.Discard, nil))), config)
+	d.backoff = time.Millisecond
+	return d
+}
+
+// flush waits for the dispatcher's deliveries to finish, retries included.
+func flush(t *testing.T, d *Dispatcher) {
+	t.Helper()
+	done := make(chan struct{})
+	go func() {
+		d.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(10 * time.Second):
+		t.Fatal("deliveries did not finish")
+	}
+}
+
+func TestIsDuplicate(t *testing.T) {
+	d := newTestDispatcher()
+	channel := domain.NotificationChannel{Name: "ops", DedupSeconds: 60}
+	start := time.Date(2026, 10, 18, 10, 0, 0, 0, time.UTC)
+	event := domain.Event{Type: domain.EventKidDied, SyncName: "orders", Message: "kid died", Time: start}
+
+	at := func(e domain.Event, offset time.Duration) domain.Event {
+		e.Time = start.Add(offset)
+		return e
+	}
+	other := event
+	other.SyncName = "mesh"
+
+	tests := []struct {
+		name    string
+		channel domain.NotificationChannel
+		event   domain.Event
+		want    bool
+	}{
+		{"first event", channel, event, false},
+		{"same event within the window", channel, at(event, 30*time.Second), true},
+		{"other sync", channel, at(other, 30*time.Second), false},
+		{"other channel", domain.NotificationChannel{Name: "pager", DedupSeconds: 60}, at(event, 30*time.Second), false},
+		{"window measured from the first event", channel, at(event, 59*time.Second), true},
+		{"after the window", channel, at(event, 60*time.Second), false},
+		{"window restarts", channel, at(event, 90*time.Second), true},
+		{"default window", domain.NotificationChannel{Name: "default"}, at(event, 0), false},
+		{"within the default window", domain.NotificationChannel{Name: "default"}, at(event, defaultDedupWindow-time.Second), true},
+	}
+	for _, tt := range tests {
+		if got := d.isDuplicate(tt.channel, tt.event); got != tt.want {
+			t.Errorf("%s: isDuplicate() = %t, want %t", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestNotifyFilters(t *testing.T) {
+	var received atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received.Add(1)
+	}))
+	defer srv.Close()
+
+	d := newTestDispatcher(
+		domain.NotificationChannel{Name: "all", Type: "webhook", URL: srv.URL},
+		domain.NotificationChannel{Name: "errors", Type: "webhook", URL: srv.URL, MinSeverity: domain.SeverityError},
+		domain.NotificationChannel{Name: "kids", Type: "webhook", URL: srv.URL, Events: []string{domain.EventKidDied}},
+	)
+	ctx := context.Background()
+	d.Notify(ctx, domain.Event{Type: domain.EventReconcileStarted, Message: "started"})                         // all
+	d.Notify(ctx, domain.Event{Type: domain.EventKidDied, Severity: domain.SeverityError, Message: "kid died"}) // all, errors, kids
+	d.Notify(ctx, domain.Event{Type: domain.EventKidDied, Severity: domain.SeverityError, Message: "kid died"}) // duplicate
+	flush(t, d)
+
+	if got := received.Load(); got != 4 {
+		t.Errorf("deliveries = %d, want 4", got)
+	}
+}
+
+func TestDeliverRetries(t *testing.T) {
+	tests := []struct {
+		name       string
+		maxRetries int
+		failures   int32
+		want       int32
+	}{
+		{"succeeds after failures", 0, 2, 3},
+		{"gives up after the default retries", 0, 100, defaultMaxRetries + 1},
+		{"gives up after the channel's retries", 1, 100, 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var attempts atomic.Int32
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if attempts.Add(1) <= tt.failures {
+					w.WriteHeader(http.StatusServiceUnavailable)
+				}
+			}))
+			defer srv.Close()
+
+			d := newTestDispatcher(domain.NotificationChannel{Name: "ops", Type: "webhook", URL: srv.URL, MaxRetries: tt.maxRetries})
+			d.Notify(context.Background(), domain.Event{Type: domain.EventReconcileFailed, Message: "failed"})
+			flush(t, d)
+
+			if got := attempts.Load(); got != tt.want {
+				t.Errorf("attempts = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCloseStopsRetries(t *testing.T) {
+	var attempts atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts.Add(1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer srv.Close()
+
+	d := newTestDispatcher(domain.NotificationChannel{Name: "ops", Type: "webhook", URL: srv.URL, MaxRetries: 10})
+	d.backoff = time.Hour
+	d.Notify(context.Background(), domain.Event{Type: domain.EventReconcileFailed, Message: "failed"})
+	for attempts.Load() == 0 {
+		time.Sleep(time.Millisecond)
+	}
+
+	// The delivery is waiting to retry; Close ends the wait instead of sitting it out.
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := d.Close(ctx); err != nil {
+		t.Fatalf("Close() = %v", err)
+	}
+	if got := attempts.Load(); got != 1 {
+		t.Errorf("attempts = %d, want 1", got)
+	}
+	if err := d.Close(ctx); err != nil {
+		t.Errorf("second Close() = %v", err)
+	}
+}
@@ -0,0 +1,53 @@
+package notify
+
+import (
+	"fmt"
+	"net/smtp"
+	"strings"
+	"time"
+
+	"replication-service/internal/core/domain"
+)
+
+// sendEmail delivers the event as a plain text email through the channel's SMTP server.
+func sendEmail(channel domain.NotificationChannel, event domain.Event) error {
+	if channel.SMTPHost == "" || channel.From == "" || len(channel.To) == 0 {
+		return fmt.Errorf("email channel %s requires smtp_host, from and to", channel.Name)
+	}
+	port := channel.SMTPPort
+	if port == 0 {
+		port = 25
+	}
+
+	var auth smtp.Auth
+	if channel.SMTPUser != "" {
+		password, err := resolveSecret(channel, channel.SMTPPass)
+		if err != nil {
+			return err
+		}
+		auth = smtp.PlainAuth("", channel.SMTPUser, password, channel.SMTPHost)
+	}
+
+	subject := fmt.Sprintf("[bucardo][%s] %s", event.Severity, event.Type)
+	if event.SyncName != "" {
+		subject += " " + event.SyncName
+	}
+
+	var body strings.Builder
+	fmt.Fprintf(&body, "From: %s\r\n", channel.From)
+	fmt.Fprintf(&body, "To: %s\r\n", strings.Join(channel.To, ", "))
+	fmt.Fprintf(&body, "Subject: %s\r\n", subject)
+	fmt.Fprintf(&body, "Date: %s\r\n", event.Time.Format(time.RFC1123Z))
+	body.WriteString("Content-Type: text/plain; charset=utf-8\r\n\r\n")
+	fmt.Fprintf(&body, "%s\r\n\r\n", event.Message)
+	fmt.Fprintf(&body, "Type: %s\r\nSeverity: %s\r\nTime: %s\r\n", event.Type, event.Severity, event.Time.Format(time.RFC3339))
+	if event.SyncName != "" {
+		fmt.Fprintf(&body, "Sync: %s\r\n", event.SyncName)
+	}
+	for k, v := range event.Details {
+		fmt.Fprintf(&body, "%s: %v\r\n", k, v)
+	}
+
+	addr := fmt.Sprintf("%s:%d", channel.SMTPHost, port)
+	return smtp.SendMail(addr, auth, channel.From, channel.To, []byte(body.String()))
+}
@@ -0,0 +1,133 @@
+package notify
+
+import (
+	"context"
+	"net"
+	"net/textproto"
+	"strings"
+	"testing"
+	"time"
+
+	"replication-service/internal/core/domain"
+)
+
+// message is a mail received by the SMTP stub.
+type message struct {
+	from string
+	to   []string
+	data string
+}
+
+// newSMTPStub starts a minimal SMTP server on a local port that accepts every message.
+func newSMTPStub(t *testing.T) (port int, messages <-chan message) {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { ln.Close() })
+
+	received := make(chan message, 10)
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go serveSMTP(conn, received)
+		}
+	}()
+	return ln.Addr().(*net.TCPAddr).Port, received
+}
+
+func serveSMTP(conn net.Conn, received chan<- message) {
+	defer conn.Close()
+	tp := textproto.NewConn(conn)
+	tp.PrintfLine("220 stub ESMTP")
+
+	var msg message
+	for {
+		line, err := tp.ReadLine()
+		if err != nil {
+			return
+		}
+		verb, arg, _ := strings.Cut(line, " ")
+		switch strings.ToUpper(verb) {
+		case "EHLO", "HELO":
+			tp.PrintfLine("250 stub")
+		case "MAIL":
+			msg = message{from: strings.Trim(strings.TrimPrefix(arg, "FROM:"), "<>")}
+			tp.PrintfLine("250 OK")
+		case "RCPT":
+			msg.to = append(msg.to, strings.Trim(strings.TrimPrefix(arg, "TO:"), "<>"))
+			tp.PrintfLine("250 OK")
+		case "DATA":
+			tp.PrintfLine("354 End data with <CR><LF>.<CR><LF>")
+			data, err := tp.ReadDotBytes()
+			if err != nil {
+				return
+			}
+			msg.data = string(data)
+			received <- msg
+			tp.PrintfLine("250 OK")
+		case "QUIT":
+			tp.PrintfLine("221 Bye")
+			return
+		default:
+			tp.PrintfLine("502 Not implemented")
+		}
+	}
+}
+
+func TestEmail(t *testing.T) {
+	port, messages := newSMTPStub(t)
+	d := newTestDispatcher(domain.NotificationChannel{
+		Name:     "mail",
+		Type:     "email",
+		SMTPHost: "127.0.0.1",
+		SMTPPort: port,
+		From:     "bucardo@example.com",
+		To:       []string{"ops@example.com", "dba@example.com"},
+	})
+	d.Notify(context.Background(), domain.Event{
+		Type:     domain.EventKidDied,
+		Severity: domain.SeverityError,
+		Time:     time.Date(2026, 10, 18, 10, 0, 0, 0, time.UTC),
+		SyncName: "orders",
+		Message:  "kid died",
+		Details:  map[string]any{"pid": 2801},
+	})
+	flush(t, d)
+
+	var msg message
+	select {
+	case msg = <-messages:
+	default:
+		t.Fatal("no message was delivered")
+	}
+	if msg.from != "bucardo@example.com" {
+		t.Errorf("from = %q", msg.from)
+	}
+	if strings.Join(msg.to, ",") != "ops@example.com,dba@example.com" {
+		t.Errorf("to = %q", msg.to)
+	}
+	for _, want := range []string{
+		"To: ops@example.com, dba@example.com\n",
+		"Subject: [bucardo][error] kid_died orders\n",
+		"\nkid died\n",
+		"Time: 2026-10-18T10:00:00Z\n",
+		"Sync: orders\n",
+		"pid: 2801\n",
+	} {
+		if !strings.Contains(msg.data, want) {
+			t.Errorf("message does not contain %q:\n%s", want, msg.data)
+		}
+	}
+}
+
+func TestEmailRequiresAddresses(t *testing.T) {
+	channel := domain.NotificationChannel{Name: "mail", Type: "email", SMTPHost: "127.0.0.1", From: "bucardo@example.com"}
+	if err := sendEmail(channel, domain.Event{Type: domain.EventKidDied}); err == nil || !strings.Contains(err.Error(), "requires smtp_host, from and to") {
+		t.Errorf("sendEmail() = %v, want a configuration error", err)
+	}
+}
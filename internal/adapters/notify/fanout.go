@@ -0,0 +1,25 @@
+package notify
+
+import (
+	"context"
+
+	"replication-service/internal/core/domain"
+	"replication-service/internal/core/ports"
+)
+
+// Fanout implements the Notifier port by passing every event to several notifiers.
+type Fanout struct {
+	notifiers []ports.Notifier
+}
+
+// NewFanout creates a new Fanout.
+func NewFanout(notifiers ...ports.Notifier) *Fanout {
+	return &Fanout{notifiers: notifiers}
+}
+
+// Notify passes the event to each notifier in turn.
+func (f *Fanout) Notify(ctx context.Context, event domain.Event) {
+	for _, n := range f.notifiers {
+		n.Notify(ctx, event)
+	}
+}
@@ -0,0 +1,86 @@
+package notify
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"replication-service/internal/core/domain"
+)
+
+// sendWebhook posts the event as JSON. When the channel has a secret, the body is signed
+// with HMAC-SHA256 over "<timestamp>.<body>" and sent in the X-Bucardo-Signature header.
+func (d *Dispatcher) sendWebhook(channel domain.NotificationChannel, event domain.Event) error {
+	body, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to marshal event: %w", err)
+	}
+
+	req, err := http.NewRequest(http.MethodPost, channel.URL, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("invalid webhook URL: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Bucardo-Event", event.Type)
+
+	secret, err := resolveSecret(channel, channel.Secret)
+	if err != nil {
+		return err
+	}
+	if secret != "" {
+		timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+		req.Header.Set("X-Bucardo-Timestamp", timestamp)
+		req.Header.Set("X-Bucardo-Signature", "sha256="+Sign(secret, timestamp, body))
+	}
+	return d.post(req)
+}
+
+// Sign returns the hex encoded HMAC-SHA256 of "<timestamp>.<body>" used for webhook signatures.
+// Receivers recompute it with the shared secret to authenticate a delivery.
+func Sign(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// sendSlack posts the event as a Slack incoming-webhook message.
+func (d *Dispatcher) sendSlack(channel domain.NotificationChannel, event domain.Event) error {
+	text := fmt.Sprintf("*[%s]* `%s` %s", event.Severity, event.Type, event.Message)
+	if event.SyncName != "" {
+		text += fmt.Sprintf(" (sync `%s`)", event.SyncName)
+	}
+	body, err := json.Marshal(map[string]string{"text": text})
+	if err != nil {
+		return fmt.Errorf("failed to marshal slack payload: %w", err)
+	}
+
+	req, err := http.NewRequest(http.MethodPost, channel.URL, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("invalid slack URL: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	return d.post(req)
+}
+
+func (d *Dispatcher) post(req *http.Request) error {
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("unexpected response status %s", resp.Status)
+	}
+	return nil
+}
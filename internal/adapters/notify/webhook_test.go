@@ -0,0 +1,147 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"replication-service/internal/core/domain"
+)
+
+// request is what a stub endpoint received.
+type request struct {
+	header http.Header
+	body   []byte
+}
+
+// newEndpoint starts a server that records the requests it receives.
+func newEndpoint(t *testing.T) (*httptest.Server, <-chan request) {
+	t.Helper()
+	requests := make(chan request, 10)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		requests <- request{header: r.Header.Clone(), body: body}
+	}))
+	t.Cleanup(srv.Close)
+	return srv, requests
+}
+
+func TestWebhook(t *testing.T) {
+	t.Setenv("BUCARDO_NOTIFY_OPS_HOOK", "env-secret")
+
+	event := domain.Event{
+		Type:     domain.EventKidDied,
+		Severity: domain.SeverityError,
+		Time:     time.Date(2026, 10, 18, 10, 0, 0, 0, time.UTC),
+		SyncName: "orders",
+		Message:  "kid died",
+		Details:  map[string]any{"pid": 2801},
+	}
+	tests := []struct {
+		name       string
+		secret     string
+		wantSecret string
+	}{
+		{"unsigned", "", ""},
+		{"signed", "s3cret", "s3cret"},
+		{"signed with a secret from the environment", "env", "env-secret"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv, requests := newEndpoint(t)
+			d := newTestDispatcher(domain.NotificationChannel{Name: "ops-hook", Type: "webhook", URL: srv.URL, Secret: tt.secret})
+			d.Notify(context.Background(), event)
+			flush(t, d)
+			req := <-requests
+
+			if got := req.header.Get("Content-Type"); got != "application/json" {
+				t.Errorf("Content-Type = %q", got)
+			}
+			if got := req.header.Get("X-Bucardo-Event"); got != domain.EventKidDied {
+				t.Errorf("X-Bucardo-Event = %q", got)
+			}
+			var got domain.Event
+			if err := json.Unmarshal(req.body, &got); err != nil {
+				t.Fatalf("body is not an event: %v", err)
+			}
+			if got.Type != event.Type || got.SyncName != event.SyncName || got.Message != event.Message || !got.Time.Equal(event.Time) || got.Details["pid"] != 2801.0 {
+				t.Errorf("body = %+v, want %+v", got, event)
+			}
+
+			timestamp := req.header.Get("X-Bucardo-Timestamp")
+			signature := req.header.Get("X-Bucardo-Signature")
+			if tt.wantSecret == "" {
+				if timestamp != "" || signature != "" {
+					t.Errorf("unsigned delivery has timestamp %q and signature %q", timestamp, signature)
+				}
+				return
+			}
+			if timestamp == "" {
+				t.Fatal("signed delivery has no timestamp")
+			}
+			if want := "sha256=" + Sign(tt.wantSecret, timestamp, req.body); signature != want {
+				t.Errorf("X-Bucardo-Signature = %q, want %q", signature, want)
+			}
+		})
+	}
+}
+
+func TestWebhookMissingSecret(t *testing.T) {
+	srv, requests := newEndpoint(t)
+	d := newTestDispatcher()
+	channel := domain.NotificationChannel{Name: "unset", Type: "webhook", URL: srv.URL, Secret: "env"}
+	if err := d.send(channel, domain.Event{Type: domain.EventKidDied}); err == nil {
+		t.Fatal("send() succeeded without BUCARDO_NOTIFY_UNSET")
+	}
+	if len(requests) != 0 {
+		t.Error("an unsigned delivery was made")
+	}
+}
+
+func TestSign(t *testing.T) {
+	// Computed with: printf '1760781600.{"a":1}' | openssl dgst -sha256 -hmac s3cret
+	want := "60233268b9efc4fa4b7427463e93456ee01df1dfe0969b2eb1c9af0b75c75a35"
+	if got := Sign("s3cret", "1760781600", []byte(`{"a":1}`)); got != want {
+		t.Errorf("Sign() = %s, want %s", got, want)
+	}
+}
+
+func TestSlack(t *testing.T) {
+	tests := []struct {
+		name  string
+		event domain.Event
+		want  string
+	}{
+		{
+			name:  "with sync",
+			event: domain.Event{Type: domain.EventKidDied, Severity: domain.SeverityError, SyncName: "orders", Message: "kid died"},
+			want:  "*[error]* `kid_died` kid died (sync `orders`)",
+		},
+		{
+			name:  "without sync",
+			event: domain.Event{Type: domain.EventReconcileStarted, Message: "started"},
+			want:  "*[info]* `reconcile_started` started",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv, requests := newEndpoint(t)
+			d := newTestDispatcher(domain.NotificationChannel{Name: "slack", Type: "slack", URL: srv.URL})
+			d.Notify(context.Background(), tt.event)
+			flush(t, d)
+			req := <-requests
+
+			var payload map[string]string
+			if err := json.Unmarshal(req.body, &payload); err != nil {
+				t.Fatalf("body is not a Slack message: %v", err)
+			}
+			if payload["text"] != tt.want {
+				t.Errorf("text = %q, want %q", payload["text"], tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,121 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"replication-service/internal/core/domain"
+)
+
+// deltaTable is one entry of bucardo.bucardo_delta_names on a source database.
+type deltaTable struct {
+	table     string
+	deltaName string
+	trackName string
+	targets   int
+}
+
+// listDeltaTables returns the delta and track tables Bucardo maintains on a source database,
+// together with the number of targets each table is replicated to.
+func (i *PsqlInspector) listDeltaTables(ctx context.Context, conn domain.ConnInfo) ([]deltaTable, error) {
+	rows, err := i.query(ctx, conn, `
+		SELECT DISTINCT n.tablename, n.deltaname, n.trackname,
+		       (SELECT count(DISTINCT t.target) FROM bucardo.bucardo_delta_targets t
+		         WHERE t.tablename = to_regclass(n.tablename))
+		  FROM bucardo.bucardo_delta_names n
+		 ORDER BY n.tablename`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list Bucardo delta tables: %w", err)
+	}
+
+	tables := make([]deltaTable, 0, len(rows))
+	for _, row := range rows {
+		targets, _ := strconv.Atoi(row[3])
+		tables = append(tables, deltaTable{table: row[0], deltaName: row[1], trackName: row[2], targets: targets})
+	}
+	return tables, nil
+}
+
+// replicatedCondition matches delta rows whose transaction has been tracked by every target.
+func replicatedCondition(t deltaTable) string {
+	return fmt.Sprintf("(SELECT count(DISTINCT k.target) FROM bucardo.%s k WHERE k.txntime = d.txntime) >= %d",
+		quoteIdent(t.trackName), t.targets)
+}
+
+// DeltaBacklog measures the delta and track tables of every replicated table on a source database.
+func (i *PsqlInspector) DeltaBacklog(ctx context.Context, conn domain.ConnInfo) ([]domain.DeltaTableStats, error) {
+	tables, err := i.listDeltaTables(ctx, conn)
+	if err != nil {
+		return nil, err
+	}
+
+	stats := make([]domain.DeltaTableStats, 0, len(tables))
+	for _, t := range tables {
+		rows, err := i.query(ctx, conn, fmt.Sprintf(`
+			SELECT count(*),
+			       count(*) FILTER (WHERE NOT %[1]s),
+			       coalesce(extract(epoch FROM now() - min(d.txntime) FILTER (WHERE NOT %[1]s)), 0),
+			       (SELECT count(*) FROM bucardo.%[2]s)
+			  FROM bucardo.%[3]s d`,
+			replicatedCondition(t), quoteIdent(t.trackName), quoteIdent(t.deltaName)))
+		if err != nil {
+			return nil, fmt.Errorf("failed to measure delta table %s: %w", t.deltaName, err)
+		}
+
+		s := domain.DeltaTableStats{Table: t.table, DeltaTable: t.deltaName, Targets: t.targets}
+		s.DeltaRows, _ = strconv.ParseInt(rows[0][0], 10, 64)
+		s.PendingRows, _ = strconv.ParseInt(rows[0][1], 10, 64)
+		s.OldestPendingAgeSeconds, _ = strconv.ParseFloat(rows[0][2], 64)
+		s.TrackRows, _ = strconv.ParseInt(rows[0][3], 10, 64)
+		stats = append(stats, s)
+	}
+	return stats, nil
+}
+
+// PurgeDeltas removes delta rows, and their track rows, that every target has already
+// replicated and that are older than minAgeSeconds. This mirrors what Bucardo's own
+// purge does for replicated rows but never touches changes still pending for a target.
+// With dryRun set nothing is deleted and the counts of rows that would be purged are returned.
+func (i *PsqlInspector) PurgeDeltas(ctx context.Context, conn domain.ConnInfo, minAgeSeconds int, dryRun bool) ([]domain.DeltaPurgeResult, error) {
+	tables, err := i.listDeltaTables(ctx, conn)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]domain.DeltaPurgeResult, 0, len(tables))
+	for _, t := range tables {
+		result := domain.DeltaPurgeResult{Table: t.table, DeltaTable: t.deltaName}
+		if t.targets == 0 {
+			// Without known targets nothing can be proven replicated.
+			results = append(results, result)
+			continue
+		}
+
+		purgeable := fmt.Sprintf("d.txntime < now() - interval '%d seconds' AND %s", minAgeSeconds, replicatedCondition(t))
+		// Both variants select the same rows; the dry run just does not delete them.
+		var sql string
+		if dryRun {
+			sql = fmt.Sprintf(`
+				WITH purged AS (SELECT d.txntime FROM bucardo.%[1]s d WHERE %[2]s)
+				SELECT (SELECT count(*) FROM purged),
+				       (SELECT count(*) FROM bucardo.%[3]s k WHERE k.txntime IN (SELECT txntime FROM purged))`,
+				quoteIdent(t.deltaName), purgeable, quoteIdent(t.trackName))
+		} else {
+			sql = fmt.Sprintf(`
+				WITH purged AS (DELETE FROM bucardo.%[1]s d WHERE %[2]s RETURNING d.txntime),
+				     tracked AS (DELETE FROM bucardo.%[3]s k WHERE k.txntime IN (SELECT txntime FROM purged) RETURNING 1)
+				SELECT (SELECT count(*) FROM purged), (SELECT count(*) FROM tracked)`,
+				quoteIdent(t.deltaName), purgeable, quoteIdent(t.trackName))
+		}
+
+		rows, err := i.query(ctx, conn, sql)
+		if err != nil {
+			return results, fmt.Errorf("failed to purge delta table %s: %w", t.deltaName, err)
+		}
+		result.DeltaRows, _ = strconv.ParseInt(rows[0][0], 10, 64)
+		result.TrackRows, _ = strconv.ParseInt(rows[0][1], 10, 64)
+		results = append(results, result)
+	}
+	return results, nil
+}
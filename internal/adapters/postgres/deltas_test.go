@@ -0,0 +1,85 @@
+package postgres
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+
+	"replication-service/internal/core/domain"
+)
+
+// deltaNames is the reply to the listing of delta tables: orders is replicated to two
+// targets, items to none yet.
+var deltaNames = [][]string{
+	{"public.items", "delta_public_items", "track_public_items", "0"},
+	{"public.orders", "delta_public_orders", "track_public_orders", "2"},
+}
+
+func TestPurgeDeltas(t *testing.T) {
+	// Delta rows older than the minimum age whose transaction every target has tracked.
+	purgeable := `d.txntime < now() - interval '3600 seconds' AND ` +
+		`(SELECT count(DISTINCT k.target) FROM bucardo."track_public_orders" k WHERE k.txntime = d.txntime) >= 2`
+	tests := []struct {
+		name   string
+		dryRun bool
+		want   string
+	}{
+		{
+			name:   "dry run",
+			dryRun: true,
+			want: `WITH purged AS (SELECT d.txntime FROM bucardo."delta_public_orders" d WHERE ` + purgeable + `) ` +
+				`SELECT (SELECT count(*) FROM purged), ` +
+				`(SELECT count(*) FROM bucardo."track_public_orders" k WHERE k.txntime IN (SELECT txntime FROM purged))`,
+		},
+		{
+			name: "purge",
+			want: `WITH purged AS (DELETE FROM bucardo."delta_public_orders" d WHERE ` + purgeable + ` RETURNING d.txntime), ` +
+				`tracked AS (DELETE FROM bucardo."track_public_orders" k WHERE k.txntime IN (SELECT txntime FROM purged) RETURNING 1) ` +
+				`SELECT (SELECT count(*) FROM purged), (SELECT count(*) FROM tracked)`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			inspector, psql := newPsqlStandin(t, deltaNames, [][]string{{"5", "9"}})
+			results, err := inspector.PurgeDeltas(context.Background(), domain.ConnInfo{Host: "pg1"}, 3600, tt.dryRun)
+			if err != nil {
+				t.Fatal(err)
+			}
+			// Without targets nothing is proven replicated, so items is not queried.
+			want := []domain.DeltaPurgeResult{
+				{Table: "public.items", DeltaTable: "delta_public_items"},
+				{Table: "public.orders", DeltaTable: "delta_public_orders", DeltaRows: 5, TrackRows: 9},
+			}
+			if !reflect.DeepEqual(results, want) {
+				t.Errorf("PurgeDeltas() = %+v, want %+v", results, want)
+			}
+
+			queries := psql.queries(t)
+			if len(queries) != 2 {
+				t.Fatalf("queries = %q, want the listing and one purge", queries)
+			}
+			if !strings.Contains(normalize(queries[0]), "FROM bucardo.bucardo_delta_names n") {
+				t.Errorf("first query = %q, want the listing of delta tables", queries[0])
+			}
+			if got := normalize(queries[1]); got != tt.want {
+				t.Errorf("purge query =\n%s\nwant\n%s", got, tt.want)
+			}
+			if tt.dryRun && strings.Contains(queries[1], "DELETE") {
+				t.Error("the dry run deletes rows")
+			}
+		})
+	}
+}
+
+func TestPurgeDeltasMinAge(t *testing.T) {
+	for minAge, want := range map[int]string{0: "interval '0 seconds'", 86400: "interval '86400 seconds'"} {
+		inspector, psql := newPsqlStandin(t, deltaNames[1:], [][]string{{"0", "0"}})
+		if _, err := inspector.PurgeDeltas(context.Background(), domain.ConnInfo{Host: "pg1"}, minAge, false); err != nil {
+			t.Fatal(err)
+		}
+		if queries := psql.queries(t); len(queries) != 2 || !strings.Contains(queries[1], "d.txntime < now() - "+want) {
+			t.Errorf("min age %d: queries = %q, want %s", minAge, queries, want)
+		}
+	}
+}
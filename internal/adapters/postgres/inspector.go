@@ -0,0 +1,231 @@
+package postgres
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+
+	"replication-service/internal/core/domain"
+	"replication-service/internal/core/ports"
+)
+
+const (
+	fieldSeparator  = "\x1f"
+	recordSeparator = "\x1e"
+)
+
+// PsqlInspector implements the DatabaseInspector port by running read-only queries through psql.
+type PsqlInspector struct {
+	logger  ports.Logger
+	psqlCmd string
+}
+
+// NewPsqlInspector creates a new PsqlInspector.
+func NewPsqlInspector(logger ports.Logger, psqlCmd string) *PsqlInspector {
+	return &PsqlInspector{
+		logger:  logger,
+		psqlCmd: psqlCmd,
+	}
+}
+
+// DescribeTable resolves the qualified name, primary key and columns of a table.
+func (i *PsqlInspector) DescribeTable(ctx context.Context, conn domain.ConnInfo, table string) (*domain.TableInfo, error) {
+	rel := quoteLiteral(table) + "::regclass"
+	rows, err := i.query(ctx, conn, fmt.Sprintf(
+		`SELECT %s::text, a.attname
+		   FROM pg_index x
+		   JOIN pg_attribute a ON a.attrelid = x.indrelid AND a.attnum = ANY (x.indkey)
+		  WHERE x.indrelid = %s AND x.indisprimary
+		  ORDER BY array_position(x.indkey::int2[], a.attnum)`, rel, rel))
+	if err != nil {
+		return nil, fmt.Errorf("failed to describe table %s: %w", table, err)
+	}
+	if len(rows) == 0 {
+		return nil, fmt.Errorf("table %s has no primary key", table)
+	}
+
+	info := &domain.TableInfo{Name: table, QualifiedName: rows[0][0]}
+	for _, row := range rows {
+		info.PrimaryKey = append(info.PrimaryKey, row[1])
+	}
+
+	rows, err = i.query(ctx, conn, fmt.Sprintf(
+		`SELECT attname FROM pg_attribute
+		  WHERE attrelid = %s AND attnum > 0 AND NOT attisdropped
+		  ORDER BY attname`, rel))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list the columns of %s: %w", table, err)
+	}
+	for _, row := range rows {
+		info.Columns = append(info.Columns, row[0])
+	}
+	return info, nil
+}
+
+// CountRows returns the number of rows in a table.
+func (i *PsqlInspector) CountRows(ctx context.Context, conn domain.ConnInfo, table *domain.TableInfo) (int64, error) {
+	rows, err := i.query(ctx, conn, fmt.Sprintf("SELECT count(*) FROM %s", table.QualifiedName))
+	if err != nil {
+		return 0, fmt.Errorf("failed to count rows of %s: %w", table.Name, err)
+	}
+	var count int64
+	if _, err := fmt.Sscanf(rows[0][0], "%d", &count); err != nil {
+		return 0, fmt.Errorf("unexpected row count %q for %s", rows[0][0], table.Name)
+	}
+	return count, nil
+}
+
+// KeyRanges splits a table into consecutive primary key ranges of roughly chunkSize rows.
+// The first range has no lower bound and the last has no upper bound, so rows that exist
+// only on another database still fall into a range.
+func (i *PsqlInspector) KeyRanges(ctx context.Context, conn domain.ConnInfo, table *domain.TableInfo, chunkSize int) ([]domain.KeyRange, error) {
+	cols := quoteIdents(table.PrimaryKey)
+	textCols := make([]string, len(cols))
+	for n, c := range cols {
+		textCols[n] = c + "::text"
+	}
+	rows, err := i.query(ctx, conn, fmt.Sprintf(
+		`SELECT %s FROM (
+		   SELECT %s, row_number() OVER (ORDER BY %s) AS rn FROM %s
+		 ) k WHERE (rn - 1) %% %d = 0 AND rn > 1 ORDER BY rn`,
+		strings.Join(keyAliases(len(cols)), ", "),
+		aliasColumns(textCols),
+		strings.Join(cols, ", "),
+		table.QualifiedName,
+		chunkSize))
+	if err != nil {
+		return nil, fmt.Errorf("failed to compute key ranges of %s: %w", table.Name, err)
+	}
+
+	ranges := make([]domain.KeyRange, 0, len(rows)+1)
+	var lower []string
+	for _, boundary := range rows {
+		ranges = append(ranges, domain.KeyRange{Lower: lower, Upper: boundary})
+		lower = boundary
+	}
+	return append(ranges, domain.KeyRange{Lower: lower}), nil
+}
+
+// RangeChecksum returns the row count and a checksum of the rows in a primary key range.
+// Rows are hashed in primary key order using the text form of table.Columns, so neither
+// the physical row order nor the column order of the table matters.
+func (i *PsqlInspector) RangeChecksum(ctx context.Context, conn domain.ConnInfo, table *domain.TableInfo, keyRange domain.KeyRange) (int64, string, error) {
+	if len(table.Columns) == 0 {
+		return 0, "", fmt.Errorf("no columns to checksum for %s", table.Name)
+	}
+	cols := quoteIdents(table.PrimaryKey)
+	rowExpr := "ROW(" + strings.Join(cols, ", ") + ")"
+
+	var conds []string
+	if keyRange.Lower != nil {
+		conds = append(conds, fmt.Sprintf("%s >= %s", rowExpr, rowLiteral(keyRange.Lower)))
+	}
+	if keyRange.Upper != nil {
+		conds = append(conds, fmt.Sprintf("%s < %s", rowExpr, rowLiteral(keyRange.Upper)))
+	}
+	where := ""
+	if len(conds) > 0 {
+		where = " WHERE " + strings.Join(conds, " AND ")
+	}
+
+	rows, err := i.query(ctx, conn, fmt.Sprintf(
+		`SELECT count(*), coalesce(md5(string_agg(md5(ROW(%s)::text), '' ORDER BY %s)), '') FROM %s t%s`,
+		strings.Join(quoteIdents(table.Columns), ", "), strings.Join(cols, ", "), table.QualifiedName, where))
+	if err != nil {
+		return 0, "", fmt.Errorf("failed to checksum %s: %w", table.Name, err)
+	}
+	var count int64
+	if _, err := fmt.Sscanf(rows[0][0], "%d", &count); err != nil {
+		return 0, "", fmt.Errorf("unexpected row count %q for %s", rows[0][0], table.Name)
+	}
+	return count, rows[0][1], nil
+}
+
+// query runs a single SQL statement and returns its rows as text fields.
+func (i *PsqlInspector) query(ctx context.Context, conn domain.ConnInfo, sql string) ([][]string, error) {
+	port := conn.Port
+	if port == 0 {
+		port = 5432
+	}
+	cmd := exec.CommandContext(ctx, i.psqlCmd,
+		"-X", "-q", "-A", "-t",
+		"-v", "ON_ERROR_STOP=1",
+		"-F", fieldSeparator,
+		"-R", recordSeparator,
+		"-h", conn.Host,
+		"-p", fmt.Sprintf("%d", port),
+		"-U", conn.User,
+		"-d", conn.DBName,
+		"-c", sql,
+	)
+	// The password is passed through the environment so it never shows up in the process list.
+	cmd.Env = append(os.Environ(), "PGPASSWORD="+conn.Password, "PGCONNECT_TIMEOUT=10")
+
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	i.logger.Debug("Running inspection query", "component", "db_inspector", "host", conn.Host, "dbname", conn.DBName, "sql", sql)
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("psql failed on %s/%s: %w. Output: %s", conn.Host, conn.DBName, err, strings.TrimSpace(stderr.String()))
+	}
+
+	out := strings.TrimRight(stdout.String(), "\n")
+	if out == "" {
+		return nil, nil
+	}
+	var rows [][]string
+	for _, record := range strings.Split(out, recordSeparator) {
+		record = strings.TrimPrefix(record, "\n")
+		if record == "" {
+			continue
+		}
+		rows = append(rows, strings.Split(record, fieldSeparator))
+	}
+	return rows, nil
+}
+
+func quoteLiteral(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+}
+
+func quoteIdent(s string) string {
+	return `"` + strings.ReplaceAll(s, `"`, `""`) + `"`
+}
+
+func quoteIdents(names []string) []string {
+	quoted := make([]string, len(names))
+	for n, name := range names {
+		quoted[n] = quoteIdent(name)
+	}
+	return quoted
+}
+
+// rowLiteral renders key values as a row of untyped literals; PostgreSQL resolves each
+// literal to the type of the column it is compared with.
+func rowLiteral(values []string) string {
+	literals := make([]string, len(values))
+	for n, v := range values {
+		literals[n] = quoteLiteral(v)
+	}
+	return "ROW(" + strings.Join(literals, ", ") + ")"
+}
+
+func keyAliases(n int) []string {
+	aliases := make([]string, n)
+	for i := range aliases {
+		aliases[i] = fmt.Sprintf("k%d", i)
+	}
+	return aliases
+}
+
+func aliasColumns(exprs []string) string {
+	aliased := make([]string, len(exprs))
+	for n, e := range exprs {
+		aliased[n] = fmt.Sprintf("%s AS k%d", e, n)
+	}
+	return strings.Join(aliased, ", ")
+}
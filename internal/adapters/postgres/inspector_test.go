@@ -0,0 +1,137 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"replication-service/internal/adapters/logger"
+	"replication-service/internal/core/domain"
+)
+
+// psqlStandin is a psql executable that records the statement of each call and answers
+// with the rows given for that call, in order; calls beyond them return no rows.
+type psqlStandin struct {
+	dir string
+}
+
+const psqlStandinScript = `#!/bin/sh
+dir=$(dirname "$0")
+n=$(($(cat "$dir/calls" 2>/dev/null || echo 0) + 1))
+echo "$n" > "$dir/calls"
+while [ $# -gt 0 ]; do
+	if [ "$1" = "-c" ]; then
+		printf '%s' "$2" > "$dir/query.$n"
+	fi
+	shift
+done
+if [ -e "$dir/reply.$n" ]; then
+	cat "$dir/reply.$n"
+fi
+`
+
+func newPsqlStandin(t *testing.T, replies ...[][]string) (*PsqlInspector, *psqlStandin) {
+	t.Helper()
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("the psql stand-in needs a POSIX shell")
+	}
+	p := &psqlStandin{dir: t.TempDir()}
+	if err := os.WriteFile(filepath.Join(p.dir, "psql"), []byte(psqlStandinScript), 0755); err != nil {
+		t.Fatal(err)
+	}
+	for n, rows := range replies {
+		records := make([]string, len(rows))
+		for r, row := range rows {
+			records[r] = strings.Join(row, fieldSeparator)
+		}
+		reply := strings.Join(records, recordSeparator) + "\n"
+		if err := os.WriteFile(filepath.Join(p.dir, fmt.Sprintf("reply.%d", n+1)), []byte(reply), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	testLogger := logger.NewSlogAdapter(slog.New(slog.NewTextHandler(io.Discard, nil)))
+	return NewPsqlInspector(testLogger, filepath.Join(p.dir, "psql")), p
+}
+
+// queries returns the statements psql was run with, oldest first.
+func (p *psqlStandin) queries(t *testing.T) []string {
+	t.Helper()
+	var queries []string
+	for n := 1; ; n++ {
+		data, err := os.ReadFile(filepath.Join(p.dir, fmt.Sprintf("query.%d", n)))
+		if os.IsNotExist(err) {
+			return queries
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		queries = append(queries, string(data))
+	}
+}
+
+// normalize collapses the whitespace of a statement.
+func normalize(sql string) string {
+	return strings.Join(strings.Fields(sql), " ")
+}
+
+func TestDescribeTable(t *testing.T) {
+	inspector, psql := newPsqlStandin(t,
+		[][]string{{"shop.orders", "region"}, {"shop.orders", "id"}},
+		[][]string{{"customer"}, {"id"}, {"region"}, {"total"}},
+	)
+	info, err := inspector.DescribeTable(context.Background(), domain.ConnInfo{Host: "pg1"}, "shop.orders")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := &domain.TableInfo{
+		Name:          "shop.orders",
+		QualifiedName: "shop.orders",
+		PrimaryKey:    []string{"region", "id"},
+		Columns:       []string{"customer", "id", "region", "total"},
+	}
+	if !reflect.DeepEqual(info, want) {
+		t.Errorf("DescribeTable() = %+v, want %+v", info, want)
+	}
+	queries := psql.queries(t)
+	if len(queries) != 2 || !strings.Contains(normalize(queries[1]), "WHERE attrelid = 'shop.orders'::regclass AND attnum > 0 AND NOT attisdropped ORDER BY attname") {
+		t.Errorf("queries = %q, want the primary key and then the columns sorted by name", queries)
+	}
+}
+
+func TestRangeChecksum(t *testing.T) {
+	inspector, psql := newPsqlStandin(t, [][]string{{"10", "5d41402abc4b2a76b9719d911017c592"}})
+	table := &domain.TableInfo{
+		Name:          "shop.orders",
+		QualifiedName: "shop.orders",
+		PrimaryKey:    []string{"id"},
+		Columns:       []string{"customer", "id", "total"},
+	}
+	keyRange := domain.KeyRange{Lower: []string{"11"}, Upper: []string{"21"}}
+	count, sum, err := inspector.RangeChecksum(context.Background(), domain.ConnInfo{Host: "pg1"}, table, keyRange)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if count != 10 || sum != "5d41402abc4b2a76b9719d911017c592" {
+		t.Errorf("RangeChecksum() = %d, %q", count, sum)
+	}
+
+	// The listed columns are hashed, not the whole row, whose column order may differ
+	// between databases.
+	want := `SELECT count(*), coalesce(md5(string_agg(md5(ROW("customer", "id", "total")::text), '' ORDER BY "id")), '')` +
+		` FROM shop.orders t WHERE ROW("id") >= ROW('11') AND ROW("id") < ROW('21')`
+	if queries := psql.queries(t); len(queries) != 1 || normalize(queries[0]) != want {
+		t.Errorf("queries = %q, want %q", queries, want)
+	}
+
+	table.Columns = nil
+	if _, _, err := inspector.RangeChecksum(context.Background(), domain.ConnInfo{Host: "pg1"}, table, keyRange); err == nil {
+		t.Error("RangeChecksum() without columns succeeded")
+	}
+}
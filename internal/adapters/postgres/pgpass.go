@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"os"
 	"os/exec"
+	"strings"
 
 	"replication-service/internal/core/domain"
 	"replication-service/internal/core/ports"
@@ -34,10 +35,10 @@ func (m *PgpassManager) SetupPgpass(ctx context.Context, dbs []domain.Database)
 	for _, db := range dbs {
 		password, err := m.getDbPassword(db)
 		if err != nil {
-			return fmt.Errorf("failed to get password for .pgpass setup for db %d: %w", db.ID, err)
+			return fmt.Errorf("failed to get password for .pgpass setup for db %s: %w", db.BucardoName(), err)
 		}
 		if err := m.appendPgpassEntry(db, password); err != nil {
-			return fmt.Errorf("failed to write entry to .pgpass file for db %d: %w", db.ID, err)
+			return fmt.Errorf("failed to write entry to .pgpass file for db %s: %w", db.BucardoName(), err)
 		}
 	}
 	return nil
@@ -79,12 +80,13 @@ func (m *PgpassManager) appendPgpassEntry(db domain.Database, password string) e
 
 func (m *PgpassManager) getDbPassword(db domain.Database) (string, error) {
 	if db.Pass == "env" {
-		envVar := fmt.Sprintf("BUCARDO_DB%d", db.ID)
-		password := os.Getenv(envVar)
-		if password == "" {
-			return "", fmt.Errorf("environment variable %s not set for db id %d", envVar, db.ID)
+		envVars := db.PasswordEnvVars()
+		for _, envVar := range envVars {
+			if password := os.Getenv(envVar); password != "" {
+				return password, nil
+			}
 		}
-		return password, nil
+		return "", fmt.Errorf("environment variable %s not set for db %s", strings.Join(envVars, " or "), db.BucardoName())
 	}
 	return db.Pass, nil
 }
@@ -0,0 +1,120 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"time"
+
+	"replication-service/internal/core/domain"
+	"replication-service/internal/core/ports"
+	"replication-service/internal/core/reqctx"
+	"replication-service/internal/core/services/orchestrator"
+)
+
+// statusRecorder remembers the status code a handler wrote.
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (r *statusRecorder) WriteHeader(status int) {
+	r.status = status
+	r.ResponseWriter.WriteHeader(status)
+}
+
+// auditMiddleware gives every request a correlation ID, taken from the X-Request-ID header
+// or generated, and returns it in X-Request-ID. Requests that may change something are
+// recorded in the audit trail; the commands they cause carry the same correlation ID.
+// Requests to configRoutes, keyed by mux pattern, are recorded with the configuration
+// changes they made.
+func (h *HTTPServer) auditMiddleware(mux *http.ServeMux, configRoutes map[string]bool) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := r.Header.Get("X-Request-ID")
+		if id == "" {
+			id = reqctx.NewCorrelationID()
+		}
+		w.Header().Set("X-Request-ID", id)
+		ctx := reqctx.WithCorrelationID(r.Context(), id)
+		r = r.WithContext(ctx)
+
+		if r.Method == http.MethodGet || r.Method == http.MethodHead || r.Method == http.MethodOptions {
+			mux.ServeHTTP(w, r)
+			return
+		}
+
+		_, pattern := mux.Handler(r)
+		AuditCall(ctx, h.service, h.audit, r.Method, r.URL.RequestURI(), configRoutes[pattern], func() (int, string) {
+			recorder := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+			mux.ServeHTTP(recorder, r)
+			return recorder.status, ""
+		})
+	})
+}
+
+// AuditCall runs call, an API call that may change something, and records it in the audit
+// trail under method and endpoint. call returns the HTTP status of the call and, if it
+// failed, its error message. With changesConfig, the record lists the configuration changes
+// the call made. The REST and gRPC servers both record their calls with it.
+func AuditCall(ctx context.Context, service *orchestrator.Service, log ports.AuditLog, method, endpoint string, changesConfig bool, call func() (status int, errMsg string)) {
+	// A change made concurrently by another request can show up in both diffs; the config
+	// history has the exact revision of each save.
+	var before *domain.BucardoConfig
+	var beforeErr error
+	if changesConfig {
+		before, beforeErr = service.GetConfig(ctx)
+	}
+	started := time.Now()
+	status, errMsg := call()
+
+	record := domain.AuditRecord{
+		Kind:       domain.AuditKindAPI,
+		Method:     method,
+		Endpoint:   endpoint,
+		Status:     status,
+		DurationMs: time.Since(started).Milliseconds(),
+		Error:      errMsg,
+	}
+	if changesConfig && beforeErr == nil {
+		if after, err := service.GetConfig(ctx); err == nil {
+			if changes, err := service.DiffConfigs(before, after); err == nil && len(changes) > 0 {
+				record.Changes = changes
+			}
+		}
+	}
+	log.Record(ctx, record)
+}
+
+func (h *HTTPServer) handleQueryAudit(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	q := domain.AuditQuery{
+		Actor:         query.Get("actor"),
+		Kind:          query.Get("kind"),
+		CorrelationID: query.Get("correlation_id"),
+	}
+	for name, t := range map[string]*time.Time{"since": &q.Since, "until": &q.Until} {
+		if v := query.Get(name); v != "" {
+			parsed, err := time.Parse(time.RFC3339, v)
+			if err != nil {
+				writeError(w, http.StatusBadRequest, "Invalid "+name+" value, expected an RFC 3339 timestamp")
+				return
+			}
+			*t = parsed
+		}
+	}
+	if v := query.Get("limit"); v != "" {
+		limit, err := strconv.Atoi(v)
+		if err != nil || limit <= 0 {
+			writeError(w, http.StatusBadRequest, "Invalid limit value, expected a positive number")
+			return
+		}
+		q.Limit = limit
+	}
+
+	records, err := h.audit.Query(r.Context(), q)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, records)
+}
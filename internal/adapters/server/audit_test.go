@@ -0,0 +1,62 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"replication-service/internal/core/domain"
+)
+
+func TestAuditMiddleware(t *testing.T) {
+	tests := []struct {
+		name        string
+		method      string
+		target      string
+		wantStatus  int
+		wantChanges bool
+		wantDiff    bool // Whether the configuration is loaded, to compare it or by the handler.
+	}{
+		{"configuration change", "POST", "/syncs/orders/pause", http.StatusOK, true, true},
+		{"failed configuration change", "POST", "/syncs/missing/pause", http.StatusNotFound, false, true},
+		{"lifecycle", "POST", "/stop", http.StatusOK, false, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ts := newTestServer(t, testConfig())
+			loads := ts.config.Loads()
+			rec := ts.do(tt.method, tt.target, "")
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d: %s", rec.Code, tt.wantStatus, rec.Body)
+			}
+			if rec.Header().Get("X-Request-ID") == "" {
+				t.Error("no X-Request-ID in the response")
+			}
+
+			records, err := ts.audit.Query(context.Background(), domain.AuditQuery{Kind: domain.AuditKindAPI})
+			if err != nil || len(records) != 1 {
+				t.Fatalf("audit records = %v, %v, want one", records, err)
+			}
+			record := records[0]
+			if record.Method != tt.method || record.Endpoint != tt.target || record.Status != tt.wantStatus {
+				t.Errorf("record = %+v", record)
+			}
+			if record.CorrelationID != rec.Header().Get("X-Request-ID") {
+				t.Errorf("correlation ID = %q, want the X-Request-ID %q", record.CorrelationID, rec.Header().Get("X-Request-ID"))
+			}
+			if got := len(record.Changes) > 0; got != tt.wantChanges {
+				t.Errorf("changes = %+v, want some: %t", record.Changes, tt.wantChanges)
+			}
+			if diffed := ts.config.Loads() != loads; diffed != tt.wantDiff {
+				t.Errorf("configuration loaded %d times, want a diff: %t", ts.config.Loads()-loads, tt.wantDiff)
+			}
+		})
+	}
+
+	// Reads are not audited.
+	ts := newTestServer(t, testConfig())
+	ts.do("GET", "/syncs", "")
+	if records, _ := ts.audit.Query(context.Background(), domain.AuditQuery{}); len(records) != 0 {
+		t.Errorf("audit records = %+v, want none for a read", records)
+	}
+}
@@ -1,69 +1,181 @@
 package server
 
 import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
 	"net/http"
+	"sort"
+	"strconv"
 	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/gorilla/websocket"
+
+	"replication-service/internal/core/domain"
 )
 
 var upgrader = websocket.Upgrader{
 	CheckOrigin: func(r *http.Request) bool {
 		return true // Allow all origins for now
 	},
+	Error: func(w http.ResponseWriter, r *http.Request, status int, reason error) {
+		writeError(w, status, reason.Error())
+	},
 }
 
-// LogBroadcaster manages WebSocket clients and broadcasts log messages.
+// LogBroadcaster manages WebSocket and Server-Sent Events clients and broadcasts log
+// messages and replication events to them. Every message gets a sequence number, and
+// recent messages are kept so clients can replay them when they connect.
 type LogBroadcaster struct {
-	clients   map[*websocket.Conn]bool
-	broadcast chan []byte
-	mutex     sync.Mutex
+	clients          map[*streamClient]bool
+	broadcast        chan logEntry
+	history          *logHistory
+	slowClientPolicy string
+	nextClientID     atomic.Uint64
+	dropped          atomic.Uint64
+	mutex            sync.Mutex
 }
 
-// NewLogBroadcaster creates a new LogBroadcaster.
-func NewLogBroadcaster() *LogBroadcaster {
+// NewLogBroadcaster creates a new LogBroadcaster that remembers the last historySize
+// messages. When spoolPath is set, messages are also appended to that file so history
+// survives restarts. slowClientPolicy decides what happens when a client's send queue is
+// full: SlowClientDropOldest (the default) or SlowClientDisconnect.
+func NewLogBroadcaster(historySize int, spoolPath, slowClientPolicy string) *LogBroadcaster {
+	if slowClientPolicy != SlowClientDisconnect {
+		slowClientPolicy = SlowClientDropOldest
+	}
 	return &LogBroadcaster{
-		clients:   make(map[*websocket.Conn]bool),
-		broadcast: make(chan []byte, 256), // Buffer to prevent blocking
+		clients:          make(map[*streamClient]bool),
+		broadcast:        make(chan logEntry, 256), // Buffer to prevent blocking
+		history:          newLogHistory(historySize, spoolPath),
+		slowClientPolicy: slowClientPolicy,
 	}
 }
 
-// Start begins the broadcasting loop. Run this in a goroutine.
+// Start begins the broadcasting loop. Run this in a goroutine. Entries are numbered and
+// handed to clients under the lock, and spooled after it is released, so a slow disk
+// never holds up clients connecting or disconnecting.
 func (b *LogBroadcaster) Start() {
-	for message := range b.broadcast {
+	for entry := range b.broadcast {
+		if entry.event == "" {
+			entry = parseLogEntry(entry.raw)
+		}
 		b.mutex.Lock()
+		entry = b.history.record(entry)
 		for client := range b.clients {
-			if err := client.WriteMessage(websocket.TextMessage, message); err != nil {
-				client.Close()
+			if !client.filter.matches(entry) {
+				continue
+			}
+			if !client.enqueue(entry, b.slowClientPolicy) {
 				delete(b.clients, client)
+				client.evicted.Store(true)
+				client.close()
 			}
 		}
 		b.mutex.Unlock()
+		b.history.write(entry)
+	}
+}
+
+// register adds a client to the broadcast set and returns the history matching its filter.
+// The history is snapshotted under the same lock, so messages after the snapshot reach the
+// client live and it neither misses nor duplicates one. Reading the spool for older history
+// happens outside the lock, without holding up the broadcast.
+func (b *LogBroadcaster) register(kind, remoteAddr string, filter logFilter) (*streamClient, []logEntry) {
+	client := newStreamClient(strconv.FormatUint(b.nextClientID.Add(1), 10), kind, remoteAddr, filter)
+	b.mutex.Lock()
+	var entries []logEntry
+	var last uint64
+	if filter.wantsHistory() {
+		entries, last = b.history.snapshot()
+	}
+	b.clients[client] = true
+	b.mutex.Unlock()
+
+	if !filter.wantsHistory() {
+		return client, nil
 	}
+	return client, b.history.replay(filter, entries, last)
+}
+
+func (b *LogBroadcaster) unregister(client *streamClient) {
+	b.mutex.Lock()
+	delete(b.clients, client)
+	b.mutex.Unlock()
+	client.close()
 }
 
-// HandleWebsocket handles incoming WebSocket requests.
+// HandleWebsocket handles incoming WebSocket requests. The tail and since query parameters
+// replay past messages; level, component, sync_name and db_name filter both replayed and
+// live messages.
 func (b *LogBroadcaster) HandleWebsocket(w http.ResponseWriter, r *http.Request) {
+	filter, err := parseLogFilter(r.URL.Query())
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
 	conn, err := upgrader.Upgrade(w, r, nil)
 	if err != nil {
 		return
 	}
+	client, backlog := b.register("websocket", r.RemoteAddr, filter)
 
-	b.mutex.Lock()
-	b.clients[conn] = true
-	b.mutex.Unlock()
+	// Reader: answers pings, watches for pongs and notices when the peer goes away.
+	go func() {
+		defer b.unregister(client)
+		conn.SetReadDeadline(time.Now().Add(pongWait))
+		conn.SetPongHandler(func(string) error {
+			return conn.SetReadDeadline(time.Now().Add(pongWait))
+		})
+		for {
+			if _, _, err := conn.ReadMessage(); err != nil {
+				return
+			}
+		}
+	}()
 
-	// Keep connection open and handle close
+	// Writer: the only goroutine that writes to the connection.
 	go func() {
+		ping := time.NewTicker(pingPeriod)
 		defer func() {
-			b.mutex.Lock()
-			delete(b.clients, conn)
-			b.mutex.Unlock()
+			ping.Stop()
+			b.unregister(client)
 			conn.Close()
 		}()
+
+		write := func(entry logEntry) bool {
+			conn.SetWriteDeadline(time.Now().Add(writeWait))
+			if err := conn.WriteMessage(websocket.TextMessage, entry.raw); err != nil {
+				return false
+			}
+			client.sent.Add(1)
+			return true
+		}
+		for _, entry := range backlog {
+			if !write(entry) {
+				return
+			}
+		}
 		for {
-			if _, _, err := conn.ReadMessage(); err != nil {
-				break
+			select {
+			case <-client.done:
+				if client.evicted.Load() {
+					conn.WriteControl(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseTryAgainLater, "client too slow"), time.Now().Add(writeWait))
+				}
+				return
+			case entry := <-client.queue:
+				if !write(entry) {
+					return
+				}
+			case <-ping.C:
+				if err := conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(writeWait)); err != nil {
+					return
+				}
 			}
 		}
 	}()
@@ -76,11 +188,135 @@ func (b *LogBroadcaster) Write(p []byte) (n int, err error) {
 	// or reused by the logger buffer before the channel consumes it.
 	msg := make([]byte, len(p))
 	copy(msg, p)
-	
+
 	select {
-	case b.broadcast <- msg:
+	case b.broadcast <- logEntry{raw: msg}:
 	default:
 		// Drop message if buffer is full to avoid blocking the application
+		b.dropped.Add(1)
 	}
 	return len(p), nil
 }
+
+// Notify implements the Notifier port by publishing replication events to SSE clients.
+func (b *LogBroadcaster) Notify(_ context.Context, event domain.Event) {
+	if event.Time.IsZero() {
+		event.Time = time.Now()
+	}
+	if event.Severity == "" {
+		event.Severity = domain.SeverityInfo
+	}
+	data, err := json.Marshal(event)
+	if err != nil {
+		return
+	}
+	entry := logEntry{event: event.Type, raw: data, time: event.Time, syncName: event.SyncName}
+	select {
+	case b.broadcast <- entry:
+	default:
+		b.dropped.Add(1)
+	}
+}
+
+// HandleEvents streams log lines and replication events as Server-Sent Events. Each
+// message carries its sequence number as the event ID, so a reconnecting client that sends
+// Last-Event-ID receives what it missed while that is still buffered. Log lines use the
+// event name "log"; replication events use their type. The /logs query parameters apply.
+func (b *LogBroadcaster) HandleEvents(w http.ResponseWriter, r *http.Request) {
+	filter, err := parseLogFilter(r.URL.Query())
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	filter.events = true
+	if id := r.Header.Get("Last-Event-ID"); id != "" {
+		seq, err := strconv.ParseUint(id, 10, 64)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "Invalid Last-Event-ID")
+			return
+		}
+		filter.afterSeq, filter.hasAfter = seq, true
+	}
+
+	rc := http.NewResponseController(w)
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("X-Accel-Buffering", "no")
+
+	client, backlog := b.register("sse", r.RemoteAddr, filter)
+	defer b.unregister(client)
+
+	write := func(entries ...logEntry) bool {
+		rc.SetWriteDeadline(time.Now().Add(writeWait))
+		for _, entry := range entries {
+			if err := writeSSE(w, entry); err != nil {
+				return false
+			}
+			client.sent.Add(1)
+		}
+		return rc.Flush() == nil
+	}
+	if !write(backlog...) {
+		return
+	}
+	// Tells clients that only want the history, like bucardoctl logs without --follow,
+	// that the replay is complete.
+	rc.SetWriteDeadline(time.Now().Add(writeWait))
+	if _, err := fmt.Fprint(w, ": live\n\n"); err != nil || rc.Flush() != nil {
+		return
+	}
+
+	keepalive := time.NewTicker(15 * time.Second)
+	defer keepalive.Stop()
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-client.done:
+			return
+		case entry := <-client.queue:
+			if !write(entry) {
+				return
+			}
+		case <-keepalive.C:
+			rc.SetWriteDeadline(time.Now().Add(writeWait))
+			if _, err := fmt.Fprint(w, ": keepalive\n\n"); err != nil || rc.Flush() != nil {
+				return
+			}
+		}
+	}
+}
+
+// Stats returns the slow-client policy, the number of messages the broadcaster itself
+// dropped and the delivery counters of every connected client.
+func (b *LogBroadcaster) Stats() BroadcasterStats {
+	b.mutex.Lock()
+	clients := make([]ClientStats, 0, len(b.clients))
+	for client := range b.clients {
+		clients = append(clients, client.stats())
+	}
+	b.mutex.Unlock()
+
+	sort.Slice(clients, func(i, j int) bool { return clients[i].ConnectedAt.Before(clients[j].ConnectedAt) })
+	return BroadcasterStats{
+		SlowClientPolicy: b.slowClientPolicy,
+		Dropped:          b.dropped.Load(),
+		Clients:          clients,
+	}
+}
+
+// HandleStats reports delivery statistics of the log stream clients.
+func (b *LogBroadcaster) HandleStats(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, b.Stats())
+}
+
+func writeSSE(w io.Writer, entry logEntry) error {
+	name := entry.event
+	if name == "" {
+		name = "log"
+	}
+	data := bytes.TrimRight(entry.raw, "\n")
+	_, err := fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", entry.seq, name, bytes.ReplaceAll(data, []byte("\n"), []byte("\ndata: ")))
+	return err
+}
@@ -0,0 +1,112 @@
+package server
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Policies for clients whose send queue is full.
+const (
+	SlowClientDropOldest = "drop_oldest" // Discard the oldest queued message to make room.
+	SlowClientDisconnect = "disconnect"  // Close the connection; the client can reconnect and replay.
+)
+
+const (
+	clientQueueSize = 256
+	writeWait       = 10 * time.Second
+	pongWait        = 60 * time.Second
+	pingPeriod      = pongWait * 9 / 10
+)
+
+// streamClient is a connected WebSocket or SSE client with its own send queue. Messages
+// are queued by the broadcast loop and written by a goroutine owned by the connection,
+// so a slow client never delays the others.
+type streamClient struct {
+	id          string
+	kind        string // "websocket", "sse" or the kind given to Subscribe.
+	remoteAddr  string
+	connectedAt time.Time
+	filter      logFilter
+
+	queue     chan logEntry
+	done      chan struct{}
+	closeOnce sync.Once
+
+	sent    atomic.Uint64
+	dropped atomic.Uint64
+	evicted atomic.Bool // Set when disconnected by the slow-client policy.
+}
+
+// ClientStats describes one connected log stream client.
+type ClientStats struct {
+	ID          string    `json:"id"`
+	Type        string    `json:"type"`
+	RemoteAddr  string    `json:"remote_addr"`
+	ConnectedAt time.Time `json:"connected_at"`
+	Queued      int       `json:"queued"`
+	Sent        uint64    `json:"sent"`
+	Dropped     uint64    `json:"dropped"`
+}
+
+// BroadcasterStats describes the state of the log broadcaster.
+type BroadcasterStats struct {
+	SlowClientPolicy string        `json:"slow_client_policy"`
+	Dropped          uint64        `json:"dropped"` // Messages dropped before reaching any client because the broadcaster was saturated.
+	Clients          []ClientStats `json:"clients"`
+}
+
+func newStreamClient(id, kind, remoteAddr string, filter logFilter) *streamClient {
+	return &streamClient{
+		id:          id,
+		kind:        kind,
+		remoteAddr:  remoteAddr,
+		connectedAt: time.Now(),
+		filter:      filter,
+		queue:       make(chan logEntry, clientQueueSize),
+		done:        make(chan struct{}),
+	}
+}
+
+// enqueue queues a message without blocking. It returns false when the client should be
+// disconnected under the given policy.
+func (c *streamClient) enqueue(entry logEntry, policy string) bool {
+	select {
+	case c.queue <- entry:
+		return true
+	default:
+	}
+
+	c.dropped.Add(1)
+	if policy == SlowClientDisconnect {
+		return false
+	}
+	// Drop the oldest queued message. The writer may drain the queue concurrently, so
+	// neither step is allowed to block.
+	select {
+	case <-c.queue:
+	default:
+	}
+	select {
+	case c.queue <- entry:
+	default:
+	}
+	return true
+}
+
+// close signals the client's goroutines to stop. It is safe to call more than once.
+func (c *streamClient) close() {
+	c.closeOnce.Do(func() { close(c.done) })
+}
+
+func (c *streamClient) stats() ClientStats {
+	return ClientStats{
+		ID:          c.id,
+		Type:        c.kind,
+		RemoteAddr:  c.remoteAddr,
+		ConnectedAt: c.connectedAt,
+		Queued:      len(c.queue),
+		Sent:        c.sent.Load(),
+		Dropped:     c.dropped.Load(),
+	}
+}
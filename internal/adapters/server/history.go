@@ -0,0 +1,312 @@
+package server
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const maxSpoolBytes = 20 * 1024 * 1024
+
+// logEntry is a log line or replication event together with the fields clients can filter on.
+type logEntry struct {
+	seq       uint64 // Assigned in order of arrival; used as the SSE event ID.
+	event     string // Event type for replication events; empty for log lines.
+	raw       []byte
+	time      time.Time
+	level     slog.Level
+	component string
+	syncName  string
+	dbName    string
+}
+
+// parseLogEntry extracts the filterable fields from a JSON log line. Lines that are not
+// JSON are kept with only their raw text.
+func parseLogEntry(raw []byte) logEntry {
+	entry := logEntry{raw: raw}
+	var fields struct {
+		Time      time.Time `json:"time"`
+		Level     string    `json:"level"`
+		Component string    `json:"component"`
+		SyncName  string    `json:"sync_name"`
+		DBName    string    `json:"db_name"`
+	}
+	if err := json.Unmarshal(raw, &fields); err != nil {
+		return entry
+	}
+	entry.time = fields.Time
+	entry.level.UnmarshalText([]byte(fields.Level))
+	entry.component = fields.Component
+	entry.syncName = fields.SyncName
+	entry.dbName = fields.DBName
+	return entry
+}
+
+// logFilter selects the log lines a client receives.
+type logFilter struct {
+	tail      int // Number of past lines to replay; 0 replays none unless since is set.
+	since     time.Time
+	afterSeq  uint64 // Replay everything after this sequence number (SSE Last-Event-ID).
+	hasAfter  bool
+	events    bool // Whether replication events are delivered alongside log lines.
+	noLogs    bool // Deliver only replication events.
+	hasLevel  bool
+	level     slog.Level
+	component string
+	syncName  string
+	dbName    string
+}
+
+// parseLogFilter reads the tail, since, level, component, sync_name and db_name query parameters.
+func parseLogFilter(query url.Values) (logFilter, error) {
+	f := logFilter{
+		component: query.Get("component"),
+		syncName:  query.Get("sync_name"),
+		dbName:    query.Get("db_name"),
+	}
+	if v := query.Get("tail"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 {
+			return f, fmt.Errorf("invalid tail %q", v)
+		}
+		f.tail = n
+	}
+	if v := query.Get("since"); v != "" {
+		t, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			return f, fmt.Errorf("invalid since %q: must be an RFC 3339 timestamp", v)
+		}
+		f.since = t
+	}
+	if v := query.Get("level"); v != "" {
+		if err := f.level.UnmarshalText([]byte(v)); err != nil {
+			return f, fmt.Errorf("invalid level %q", v)
+		}
+		f.hasLevel = true
+	}
+	return f, nil
+}
+
+func (f logFilter) wantsHistory() bool {
+	return f.tail > 0 || !f.since.IsZero() || f.hasAfter
+}
+
+// matches reports whether a live or replayed line passes the client's filters. The since
+// bound only applies to replay. Level and field filters only apply to log lines.
+func (f logFilter) matches(e logEntry) bool {
+	if e.event != "" {
+		return f.events
+	}
+	if f.noLogs {
+		return false
+	}
+	if f.hasLevel && e.level < f.level {
+		return false
+	}
+	if f.component != "" && e.component != f.component {
+		return false
+	}
+	if f.syncName != "" && e.syncName != f.syncName {
+		return false
+	}
+	if f.dbName != "" && e.dbName != f.dbName {
+		return false
+	}
+	return true
+}
+
+// spoolRecord is one line of the spool file. The sequence number is kept so that IDs stay
+// stable across restarts. Spools written by older versions hold bare log lines, which are
+// given new numbers when they are loaded.
+type spoolRecord struct {
+	Seq   uint64          `json:"seq"`
+	Event string          `json:"event,omitempty"`
+	Line  json.RawMessage `json:"line"`
+}
+
+func encodeSpoolRecord(e logEntry) []byte {
+	line := bytes.TrimRight(e.raw, "\n")
+	if !json.Valid(line) {
+		line, _ = json.Marshal(string(line))
+	}
+	data, _ := json.Marshal(spoolRecord{Seq: e.seq, Event: e.event, Line: line})
+	return append(data, '\n')
+}
+
+func decodeSpoolRecord(data []byte) logEntry {
+	var record spoolRecord
+	if err := json.Unmarshal(data, &record); err != nil || record.Seq == 0 || len(record.Line) == 0 {
+		return parseLogEntry(data)
+	}
+	raw := []byte(record.Line)
+	var text string
+	if json.Unmarshal(raw, &text) == nil {
+		raw = []byte(text)
+	}
+	entry := parseLogEntry(raw)
+	entry.seq = record.Seq
+	entry.event = record.Event
+	return entry
+}
+
+// logHistory keeps the most recent log lines in a ring buffer and, optionally, appends
+// every line to a spool file so older history survives restarts and the buffer's size.
+// The buffer and the spool have separate locks, so numbering and snapshots never wait
+// for the spool's file I/O.
+type logHistory struct {
+	mu      sync.Mutex
+	entries []logEntry
+	next    int
+	full    bool
+	seq     uint64
+
+	spoolMu   sync.Mutex
+	spoolPath string
+	spool     *os.File
+	spoolSize int64
+}
+
+func newLogHistory(size int, spoolPath string) *logHistory {
+	if size <= 0 {
+		size = 1
+	}
+	h := &logHistory{entries: make([]logEntry, size), spoolPath: spoolPath}
+	if spoolPath == "" {
+		return h
+	}
+
+	// Seed the ring buffer from the spool so a restart keeps recent history and numbering.
+	for _, e := range h.readSpool() {
+		h.push(e)
+	}
+	file, err := os.OpenFile(spoolPath, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "log history: could not open spool %s: %v\n", spoolPath, err)
+		return h
+	}
+	if info, err := file.Stat(); err == nil {
+		h.spoolSize = info.Size()
+	}
+	h.spool = file
+	return h
+}
+
+// record assigns the next sequence number to an entry and records it in the buffer. The
+// caller spools it afterwards with write, in the same order.
+func (h *logHistory) record(e logEntry) logEntry {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.push(e)
+}
+
+// write appends a recorded entry to the spool, rotating it when it reaches its limit.
+func (h *logHistory) write(e logEntry) {
+	h.spoolMu.Lock()
+	defer h.spoolMu.Unlock()
+	if h.spool == nil {
+		return
+	}
+	n, err := h.spool.Write(encodeSpoolRecord(e))
+	h.spoolSize += int64(n)
+	if err != nil || h.spoolSize < maxSpoolBytes {
+		return
+	}
+	// Keep one previous generation so the spool never grows past twice the limit.
+	h.spool.Close()
+	os.Rename(h.spoolPath, h.spoolPath+".1")
+	h.spool, err = os.OpenFile(h.spoolPath, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0644)
+	if err != nil {
+		h.spool = nil
+	}
+	h.spoolSize = 0
+}
+
+// push records an entry, numbering it unless it already has a sequence number.
+func (h *logHistory) push(e logEntry) logEntry {
+	if e.seq == 0 {
+		e.seq = h.seq + 1
+	}
+	h.seq = max(h.seq, e.seq)
+	h.entries[h.next] = e
+	h.next = (h.next + 1) % len(h.entries)
+	if h.next == 0 {
+		h.full = true
+	}
+	return e
+}
+
+// snapshot returns the buffered entries, oldest first, and the last sequence number
+// assigned.
+func (h *logHistory) snapshot() ([]logEntry, uint64) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if !h.full {
+		return append([]logEntry(nil), h.entries[:h.next]...), h.seq
+	}
+	return append(append([]logEntry(nil), h.entries[h.next:]...), h.entries[:h.next]...), h.seq
+}
+
+// replay returns the entries of a snapshot that match f, oldest first. It falls back to the
+// spool when the request reaches further back than the snapshot, leaving out what was
+// spooled after it and adding what the snapshot holds but was not spooled yet.
+func (h *logHistory) replay(f logFilter, entries []logEntry, last uint64) []logEntry {
+	if h.spoolPath != "" && len(entries) > 0 &&
+		(f.tail > len(entries) || (!f.since.IsZero() && f.since.Before(entries[0].time)) || (f.hasAfter && f.afterSeq+1 < entries[0].seq)) {
+		spooled := h.readSpool()
+		var spooledSeq uint64
+		if len(spooled) > 0 {
+			spooledSeq = spooled[len(spooled)-1].seq
+		}
+		for _, e := range entries {
+			if e.seq > spooledSeq {
+				spooled = append(spooled, e)
+			}
+		}
+		entries = spooled
+	}
+
+	var lines []logEntry
+	for _, e := range entries {
+		if e.seq > last {
+			break
+		}
+		if !f.since.IsZero() && e.time.Before(f.since) {
+			continue
+		}
+		if f.hasAfter && e.seq <= f.afterSeq {
+			continue
+		}
+		if f.matches(e) {
+			lines = append(lines, e)
+		}
+	}
+	if f.tail > 0 && len(lines) > f.tail {
+		lines = lines[len(lines)-f.tail:]
+	}
+	return lines
+}
+
+// readSpool returns every entry of the previous and current spool files, oldest first.
+func (h *logHistory) readSpool() []logEntry {
+	var entries []logEntry
+	for _, path := range []string{h.spoolPath + ".1", h.spoolPath} {
+		file, err := os.Open(path)
+		if err != nil {
+			continue
+		}
+		scanner := bufio.NewScanner(file)
+		scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+		for scanner.Scan() {
+			entries = append(entries, decodeSpoolRecord(append([]byte(nil), scanner.Bytes()...)))
+		}
+		file.Close()
+	}
+	return entries
+}
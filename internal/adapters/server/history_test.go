@@ -0,0 +1,123 @@
+package server
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func seqs(entries []logEntry) []uint64 {
+	var s []uint64
+	for _, e := range entries {
+		s = append(s, e.seq)
+	}
+	return s
+}
+
+func buffered(h *logHistory) []logEntry {
+	entries, _ := h.snapshot()
+	return entries
+}
+
+func replay(h *logHistory, f logFilter) []logEntry {
+	entries, last := h.snapshot()
+	return h.replay(f, entries, last)
+}
+
+// add records an entry and spools it, as the broadcast loop does.
+func add(h *logHistory, e logEntry) logEntry {
+	e = h.record(e)
+	h.write(e)
+	return e
+}
+
+func logLine(msg string) logEntry {
+	return parseLogEntry([]byte(fmt.Sprintf(`{"time":"2026-10-18T10:00:00Z","level":"INFO","msg":%q}`, msg)))
+}
+
+func TestHistorySpoolKeepsSequenceNumbers(t *testing.T) {
+	spool := filepath.Join(t.TempDir(), "logs.spool")
+	h := newLogHistory(2, spool)
+	add(h, logLine("one"))
+	add(h, logEntry{event: "kid_died", raw: []byte(`{"type":"kid_died","sync_name":"orders"}`), syncName: "orders"})
+	add(h, parseLogEntry([]byte("not json")))
+	h.spool.Close()
+
+	// After a restart the buffer holds the same numbers and numbering continues.
+	h = newLogHistory(2, spool)
+	if got, want := seqs(buffered(h)), []uint64{2, 3}; !reflect.DeepEqual(got, want) {
+		t.Errorf("buffered seqs = %v, want %v", got, want)
+	}
+	if e := add(h, logLine("four")); e.seq != 4 {
+		t.Errorf("seq after restart = %d, want 4", e.seq)
+	}
+
+	// Resuming from an ID older than the buffer reads the spool, events included.
+	replayed := replay(h, logFilter{afterSeq: 0, hasAfter: true, events: true})
+	if got, want := seqs(replayed), []uint64{1, 2, 3, 4}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("replayed seqs = %v, want %v", got, want)
+	}
+	if replayed[1].event != "kid_died" || replayed[1].syncName != "orders" {
+		t.Errorf("replayed event = %+v", replayed[1])
+	}
+	if string(replayed[2].raw) != "not json" {
+		t.Errorf("replayed line = %q, want %q", replayed[2].raw, "not json")
+	}
+	// Log streams without events do not receive them from the spool either.
+	if got, want := seqs(replay(h, logFilter{tail: 10})), []uint64{1, 3, 4}; !reflect.DeepEqual(got, want) {
+		t.Errorf("replayed log seqs = %v, want %v", got, want)
+	}
+}
+
+func TestHistoryLegacySpool(t *testing.T) {
+	spool := filepath.Join(t.TempDir(), "logs.spool")
+	legacy := `{"time":"2026-10-18T10:00:00Z","level":"INFO","msg":"one"}` + "\n" + `{"time":"2026-10-18T10:00:01Z","level":"INFO","msg":"two"}` + "\n"
+	if err := os.WriteFile(spool, []byte(legacy), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// Bare lines written by older versions are numbered when they are loaded.
+	h := newLogHistory(10, spool)
+	if got, want := seqs(buffered(h)), []uint64{1, 2}; !reflect.DeepEqual(got, want) {
+		t.Errorf("seqs = %v, want %v", got, want)
+	}
+	if e := add(h, logLine("three")); e.seq != 3 {
+		t.Errorf("seq = %d, want 3", e.seq)
+	}
+}
+
+func TestHistoryReplayLeavesOutNewerSpooledEntries(t *testing.T) {
+	h := newLogHistory(2, filepath.Join(t.TempDir(), "logs.spool"))
+	for _, msg := range []string{"one", "two", "three"} {
+		add(h, logLine(msg))
+	}
+	entries, last := h.snapshot()
+
+	// Added after the snapshot, as by a broadcast while a client registers: the client
+	// receives it live, so the replay from the spool leaves it out.
+	add(h, logLine("four"))
+	if got, want := seqs(h.replay(logFilter{tail: 10}, entries, last)), []uint64{1, 2, 3}; !reflect.DeepEqual(got, want) {
+		t.Errorf("replayed seqs = %v, want %v", got, want)
+	}
+}
+
+func TestHistoryReplayIncludesEntriesNotSpooledYet(t *testing.T) {
+	h := newLogHistory(2, filepath.Join(t.TempDir(), "logs.spool"))
+	for _, msg := range []string{"one", "two", "three"} {
+		add(h, logLine(msg))
+	}
+	// Recorded and sent to clients, but the broadcast loop has not spooled it yet.
+	pending := h.record(logLine("four"))
+	entries, last := h.snapshot()
+	if got, want := seqs(h.replay(logFilter{tail: 10}, entries, last)), []uint64{1, 2, 3, 4}; !reflect.DeepEqual(got, want) {
+		t.Errorf("replayed seqs = %v, want %v", got, want)
+	}
+
+	// Once spooled, it is replayed once.
+	h.write(pending)
+	if got, want := seqs(h.replay(logFilter{tail: 10}, entries, last)), []uint64{1, 2, 3, 4}; !reflect.DeepEqual(got, want) {
+		t.Errorf("replayed seqs = %v, want %v", got, want)
+	}
+}
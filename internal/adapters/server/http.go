@@ -2,12 +2,18 @@ package server
 
 import (
 	"context"
+	"crypto/subtle"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"net"
 	"net/http"
+	"strconv"
+	"strings"
 
 	"replication-service/internal/core/domain"
 	"replication-service/internal/core/ports"
+	"replication-service/internal/core/reqctx"
 	"replication-service/internal/core/services/orchestrator"
 )
 
@@ -16,33 +22,40 @@ type HTTPServer struct {
 	service     *orchestrator.Service
 	server      *http.Server
 	broadcaster *LogBroadcaster
+	audit       ports.AuditLog
+	openapi     map[string]any
+	patterns    []string // Every pattern registered on the mux.
 }
 
-func NewHTTPServer(logger ports.Logger, service *orchestrator.Service, broadcaster *LogBroadcaster, port int) *HTTPServer {
+// NewHTTPServer creates the management API server. When apiToken is set, every request
+// must present it as a bearer token.
+func NewHTTPServer(logger ports.Logger, service *orchestrator.Service, broadcaster *LogBroadcaster, audit ports.AuditLog, apiToken string, port int) *HTTPServer {
 	mux := http.NewServeMux()
 	h := &HTTPServer{
 		logger:      logger,
 		service:     service,
 		broadcaster: broadcaster,
+		audit:       audit,
 	}
 
-	// Apply CORS middleware to all routes
-	handler := corsMiddleware(mux)
-
-	mux.HandleFunc("GET /config", h.handleGetConfig)
-	mux.HandleFunc("POST /config", h.handleUpdateConfig)
-
-	mux.HandleFunc("GET /syncs", h.handleListSyncs)
-	mux.HandleFunc("POST /syncs", h.handleCreateSync)
-	mux.HandleFunc("GET /syncs/{name}", h.handleGetSync)
-	mux.HandleFunc("PUT /syncs/{name}", h.handleUpdateSync)
-	mux.HandleFunc("DELETE /syncs/{name}", h.handleDeleteSync)
+	handle := func(pattern string, handler http.Handler) {
+		mux.Handle(pattern, handler)
+		h.patterns = append(h.patterns, pattern)
+	}
 
-	mux.HandleFunc("POST /start", h.handleStart)
-	mux.HandleFunc("POST /stop", h.handleStop)
-	mux.HandleFunc("POST /restart", h.handleRestart)
+	routes := h.routes()
+	configRoutes := make(map[string]bool)
+	for _, rt := range routes {
+		handle(rt.method+" "+rt.pattern, routeSpan(rt))
+		if rt.changesConfig {
+			configRoutes[rt.method+" "+rt.pattern] = true
+		}
+	}
 
-	mux.HandleFunc("/logs", h.broadcaster.HandleWebsocket)
+	// Apply CORS middleware to all routes
+	handler := tracingMiddleware(corsMiddleware(authMiddleware(apiToken, requestMetadataMiddleware(h.auditMiddleware(mux, configRoutes)))))
+	h.openapi = openAPIDocument(routes)
+	handle("GET /ui/", uiHandler())
 
 	h.server = &http.Server{
 		Addr:    fmt.Sprintf(":%d", port),
@@ -59,7 +72,8 @@ func corsMiddleware(next http.Handler) http.Handler {
 		// you should restrict this to your specific frontend origin(s).
 		w.Header().Set("Access-Control-Allow-Origin", "*")
 		w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
-		w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization")
+		w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization, If-Match, X-Actor, X-Change-Message, X-Request-ID")
+		w.Header().Set("Access-Control-Expose-Headers", "ETag, Location, X-Request-ID")
 
 		// Handle preflight requests
 		if r.Method == "OPTIONS" {
@@ -71,6 +85,62 @@ func corsMiddleware(next http.Handler) http.Handler {
 	})
 }
 
+// authMiddleware rejects requests that do not carry the API token, either as an
+// "Authorization: Bearer" header or, for browser WebSocket and EventSource clients that
+// cannot set headers, as an access_token query parameter. An empty token disables it.
+// The dashboard's static files are public; the dashboard asks for the token itself.
+func authMiddleware(token string, next http.Handler) http.Handler {
+	if token == "" {
+		return next
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/ui" || strings.HasPrefix(r.URL.Path, "/ui/") {
+			next.ServeHTTP(w, r)
+			return
+		}
+		presented, found := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !found {
+			presented = r.URL.Query().Get("access_token")
+		}
+		if subtle.ConstantTimeCompare([]byte(presented), []byte(token)) != 1 {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="bucardo"`)
+			writeError(w, http.StatusUnauthorized, "Missing or invalid API token")
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// requestMetadataMiddleware records who makes each request, taken from the X-Actor header
+// or else the client address, and the optional X-Change-Message header, for the config
+// history. An If-Match header holding a configuration revision, as sent in the ETag of
+// GET /config and GET /syncs/{name}, makes configuration saves fail with 412 Precondition
+// Failed once someone else has changed the configuration.
+func requestMetadataMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		actor := r.Header.Get("X-Actor")
+		if actor == "" {
+			actor = r.RemoteAddr
+			if host, _, err := net.SplitHostPort(r.RemoteAddr); err == nil {
+				actor = host
+			}
+		}
+		ctx := reqctx.WithActor(r.Context(), actor)
+		if message := r.Header.Get("X-Change-Message"); message != "" {
+			ctx = reqctx.WithChangeMessage(ctx, message)
+		}
+		if match := r.Header.Get("If-Match"); match != "" && match != "*" {
+			revision, err := strconv.Atoi(strings.Trim(strings.TrimPrefix(match, "W/"), `"`))
+			if err != nil {
+				writeError(w, http.StatusBadRequest, "Invalid If-Match header, expected a configuration revision ETag")
+				return
+			}
+			ctx = reqctx.WithExpectedRevision(ctx, revision)
+		}
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
 func (h *HTTPServer) Start() {
 	h.logger.Info("Starting HTTP server", "address", h.server.Addr)
 	if err := h.server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
@@ -82,111 +152,423 @@ func (h *HTTPServer) Stop(ctx context.Context) error {
 	return h.server.Shutdown(ctx)
 }
 
+// setRevisionETag sets the ETag of a configuration read to the latest revision, for
+// clients to send back in If-Match.
+func (h *HTTPServer) setRevisionETag(w http.ResponseWriter, r *http.Request) {
+	if revision, err := h.service.LatestConfigRevision(r.Context()); err == nil {
+		w.Header().Set("ETag", fmt.Sprintf(`"%d"`, revision))
+	}
+}
+
+// writeSaveError reports a failed configuration change, answering 412 Precondition Failed
+// when an If-Match revision is outdated, 400 Bad Request when the change is invalid and
+// 404 Not Found for an unknown sync.
+func writeSaveError(w http.ResponseWriter, err error, status int) {
+	switch {
+	case errors.Is(err, ports.ErrRevisionConflict):
+		status = http.StatusPreconditionFailed
+	case errors.Is(err, orchestrator.ErrInvalidConfig):
+		status = http.StatusBadRequest
+	case errors.Is(err, orchestrator.ErrSyncNotFound):
+		status = http.StatusNotFound
+	}
+	writeError(w, status, err.Error())
+}
+
 func (h *HTTPServer) handleGetConfig(w http.ResponseWriter, r *http.Request) {
+	h.setRevisionETag(w, r)
 	config, err := h.service.GetConfig(r.Context())
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeError(w, http.StatusInternalServerError, err.Error())
 		return
 	}
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(config)
+	writeJSON(w, http.StatusOK, config)
 }
 
 func (h *HTTPServer) handleUpdateConfig(w http.ResponseWriter, r *http.Request) {
 	var config domain.BucardoConfig
 	if err := json.NewDecoder(r.Body).Decode(&config); err != nil {
-		http.Error(w, "Invalid JSON", http.StatusBadRequest)
+		writeError(w, http.StatusBadRequest, "Invalid JSON")
 		return
 	}
 	if err := h.service.UpdateConfig(r.Context(), &config); err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeSaveError(w, err, http.StatusInternalServerError)
+		return
+	}
+	writeMessage(w, http.StatusOK, "Config updated")
+}
+
+func (h *HTTPServer) handleListConfigHistory(w http.ResponseWriter, r *http.Request) {
+	revisions, err := h.service.ListConfigRevisions(r.Context())
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, revisions)
+}
+
+func (h *HTTPServer) handleGetConfigRevision(w http.ResponseWriter, r *http.Request) {
+	rev, err := strconv.Atoi(r.PathValue("rev"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid revision")
+		return
+	}
+	detail, err := h.service.GetConfigRevision(r.Context(), rev)
+	if err != nil {
+		writeRevisionError(w, err)
 		return
 	}
-	w.WriteHeader(http.StatusOK)
-	w.Write([]byte("Config updated"))
+	writeJSON(w, http.StatusOK, detail)
+}
+
+func (h *HTTPServer) handleDiffConfigRevision(w http.ResponseWriter, r *http.Request) {
+	rev, err := strconv.Atoi(r.PathValue("rev"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid revision")
+		return
+	}
+	against := rev - 1
+	if v := r.URL.Query().Get("against"); v != "" {
+		if against, err = strconv.Atoi(v); err != nil {
+			writeError(w, http.StatusBadRequest, "Invalid against revision")
+			return
+		}
+	}
+	diff, err := h.service.DiffConfigRevisions(r.Context(), against, rev)
+	if err != nil {
+		writeRevisionError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, diff)
+}
+
+func (h *HTTPServer) handleRollbackConfig(w http.ResponseWriter, r *http.Request) {
+	rev, err := strconv.Atoi(r.PathValue("rev"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid revision")
+		return
+	}
+	if err := h.service.RollbackConfig(r.Context(), rev); err != nil {
+		writeRevisionError(w, err)
+		return
+	}
+	if r.URL.Query().Get("apply") != "true" {
+		writeMessage(w, http.StatusOK, fmt.Sprintf("Config rolled back to revision %d", rev))
+		return
+	}
+	job, err := h.service.StartReconcileJob(r.Context())
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	w.Header().Set("Location", "/jobs/"+job.ID)
+	writeJSON(w, http.StatusAccepted, job)
+}
+
+func writeRevisionError(w http.ResponseWriter, err error) {
+	if errors.Is(err, ports.ErrRevisionNotFound) {
+		writeError(w, http.StatusNotFound, err.Error())
+		return
+	}
+	writeSaveError(w, err, http.StatusInternalServerError)
 }
 
 func (h *HTTPServer) handleListSyncs(w http.ResponseWriter, r *http.Request) {
 	syncs, err := h.service.ListSyncs(r.Context())
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeError(w, http.StatusInternalServerError, err.Error())
 		return
 	}
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(syncs)
+	writeJSON(w, http.StatusOK, syncs)
 }
 
 func (h *HTTPServer) handleCreateSync(w http.ResponseWriter, r *http.Request) {
 	var sync domain.Sync
 	if err := json.NewDecoder(r.Body).Decode(&sync); err != nil {
-		http.Error(w, "Invalid JSON", http.StatusBadRequest)
+		writeError(w, http.StatusBadRequest, "Invalid JSON")
 		return
 	}
 	if err := h.service.AddSync(r.Context(), sync); err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeSaveError(w, err, http.StatusInternalServerError)
 		return
 	}
-	w.WriteHeader(http.StatusCreated)
-	w.Write([]byte("Sync created"))
+	writeMessage(w, http.StatusCreated, "Sync created")
 }
 
 func (h *HTTPServer) handleGetSync(w http.ResponseWriter, r *http.Request) {
 	name := r.PathValue("name")
+	h.setRevisionETag(w, r)
 	sync, err := h.service.GetSync(r.Context(), name)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusNotFound)
+		writeError(w, http.StatusNotFound, err.Error())
 		return
 	}
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(sync)
+	writeJSON(w, http.StatusOK, sync)
 }
 
 func (h *HTTPServer) handleUpdateSync(w http.ResponseWriter, r *http.Request) {
 	name := r.PathValue("name")
 	var sync domain.Sync
 	if err := json.NewDecoder(r.Body).Decode(&sync); err != nil {
-		http.Error(w, "Invalid JSON", http.StatusBadRequest)
+		writeError(w, http.StatusBadRequest, "Invalid JSON")
 		return
 	}
 	if err := h.service.UpdateSync(r.Context(), name, sync); err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeSaveError(w, err, http.StatusInternalServerError)
 		return
 	}
-	w.WriteHeader(http.StatusOK)
-	w.Write([]byte("Sync updated"))
+	writeMessage(w, http.StatusOK, "Sync updated")
 }
 
 func (h *HTTPServer) handleDeleteSync(w http.ResponseWriter, r *http.Request) {
 	name := r.PathValue("name")
 	if err := h.service.DeleteSync(r.Context(), name); err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeSaveError(w, err, http.StatusInternalServerError)
+		return
+	}
+	writeMessage(w, http.StatusOK, "Sync deleted")
+}
+
+func (h *HTTPServer) handleKickSync(w http.ResponseWriter, r *http.Request) {
+	name := r.PathValue("name")
+	wait := 0
+	if waitStr := r.URL.Query().Get("wait"); waitStr != "" {
+		var err error
+		wait, err = strconv.Atoi(waitStr)
+		if err != nil || wait < 0 {
+			writeError(w, http.StatusBadRequest, "Invalid wait value, expected a non-negative number of seconds")
+			return
+		}
+	}
+	result, err := h.service.KickSync(r.Context(), name, wait)
+	if err != nil {
+		if errors.Is(err, orchestrator.ErrSyncNotFound) {
+			writeError(w, http.StatusNotFound, err.Error())
+			return
+		}
+		writeError(w, http.StatusInternalServerError, err.Error())
 		return
 	}
-	w.WriteHeader(http.StatusOK)
-	w.Write([]byte("Sync deleted"))
+	writeJSON(w, http.StatusOK, result)
+}
+
+func (h *HTTPServer) handleGetSyncStatus(w http.ResponseWriter, r *http.Request) {
+	status, err := h.service.GetSyncStatus(r.Context(), r.PathValue("name"))
+	if err != nil {
+		if errors.Is(err, orchestrator.ErrSyncNotFound) {
+			writeError(w, http.StatusNotFound, err.Error())
+			return
+		}
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, status)
+}
+
+func (h *HTTPServer) handlePauseSync(w http.ResponseWriter, r *http.Request) {
+	name := r.PathValue("name")
+	if err := h.service.PauseSync(r.Context(), name); err != nil {
+		if errors.Is(err, orchestrator.ErrSyncNotFound) {
+			writeError(w, http.StatusNotFound, err.Error())
+			return
+		}
+		writeSaveError(w, err, http.StatusInternalServerError)
+		return
+	}
+	writeMessage(w, http.StatusOK, "Sync paused")
+}
+
+func (h *HTTPServer) handleResumeSync(w http.ResponseWriter, r *http.Request) {
+	name := r.PathValue("name")
+	if err := h.service.ResumeSync(r.Context(), name); err != nil {
+		if errors.Is(err, orchestrator.ErrSyncNotFound) {
+			writeError(w, http.StatusNotFound, err.Error())
+			return
+		}
+		writeSaveError(w, err, http.StatusInternalServerError)
+		return
+	}
+	writeMessage(w, http.StatusOK, "Sync resumed")
+}
+
+func (h *HTTPServer) handleStartRecopy(w http.ResponseWriter, r *http.Request) {
+	name := r.PathValue("name")
+	var req domain.RecopyRequest
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeError(w, http.StatusBadRequest, "Invalid JSON")
+			return
+		}
+	}
+	status, err := h.service.StartRecopy(r.Context(), name, req)
+	if err != nil {
+		switch {
+		case errors.Is(err, orchestrator.ErrSyncNotFound):
+			writeError(w, http.StatusNotFound, err.Error())
+		case errors.Is(err, orchestrator.ErrRecopyInProgress):
+			writeError(w, http.StatusConflict, err.Error())
+		default:
+			writeError(w, http.StatusBadRequest, err.Error())
+		}
+		return
+	}
+	w.Header().Set("Location", "/jobs/"+status.JobID)
+	writeJSON(w, http.StatusAccepted, status)
+}
+
+func (h *HTTPServer) handleGetRecopy(w http.ResponseWriter, r *http.Request) {
+	name := r.PathValue("name")
+	status, err := h.service.GetRecopyStatus(r.Context(), name)
+	if err != nil {
+		writeError(w, http.StatusNotFound, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, status)
+}
+
+func (h *HTTPServer) handleStartVerify(w http.ResponseWriter, r *http.Request) {
+	name := r.PathValue("name")
+	var req domain.VerifyRequest
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeError(w, http.StatusBadRequest, "Invalid JSON")
+			return
+		}
+	}
+	run, err := h.service.StartVerification(r.Context(), name, req)
+	if err != nil {
+		if errors.Is(err, orchestrator.ErrSyncNotFound) {
+			writeError(w, http.StatusNotFound, err.Error())
+			return
+		}
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	w.Header().Set("Location", "/jobs/"+run.JobID)
+	writeJSON(w, http.StatusAccepted, run)
+}
+
+func (h *HTTPServer) handleGetVerify(w http.ResponseWriter, r *http.Request) {
+	run, err := h.service.GetVerification(r.Context(), r.PathValue("name"), r.PathValue("run"))
+	if err != nil {
+		writeError(w, http.StatusNotFound, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, run)
+}
+
+func (h *HTTPServer) handleListDeltas(w http.ResponseWriter, r *http.Request) {
+	backlogs := h.service.ListDeltaBacklogs(r.Context())
+	if r.URL.Query().Get("refresh") == "true" {
+		var err error
+		if backlogs, err = h.service.CollectDeltaBacklog(r.Context()); err != nil {
+			writeError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+	}
+	writeJSON(w, http.StatusOK, backlogs)
+}
+
+func (h *HTTPServer) handlePurgeDeltas(w http.ResponseWriter, r *http.Request) {
+	ref := domain.DBRef(r.PathValue("id"))
+	query := r.URL.Query()
+	dryRun := query.Get("dry_run") == "true"
+	minAge := -1
+	if minAgeStr := query.Get("min_age"); minAgeStr != "" {
+		var err error
+		if minAge, err = strconv.Atoi(minAgeStr); err != nil || minAge < 0 {
+			writeError(w, http.StatusBadRequest, "Invalid min_age value, expected a non-negative number of seconds")
+			return
+		}
+	}
+
+	report, err := h.service.PurgeDeltas(r.Context(), ref, minAge, dryRun)
+	if err != nil {
+		if errors.Is(err, orchestrator.ErrDatabaseNotFound) {
+			writeError(w, http.StatusNotFound, err.Error())
+			return
+		}
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, report)
 }
 
 func (h *HTTPServer) handleStart(w http.ResponseWriter, r *http.Request) {
 	if err := h.service.StartBucardoProcess(r.Context()); err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeError(w, http.StatusInternalServerError, err.Error())
 		return
 	}
-	w.Write([]byte("Bucardo started"))
+	writeMessage(w, http.StatusOK, "Bucardo started")
 }
 
 func (h *HTTPServer) handleStop(w http.ResponseWriter, r *http.Request) {
 	if err := h.service.StopBucardoProcess(r.Context()); err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeError(w, http.StatusInternalServerError, err.Error())
 		return
 	}
-	w.Write([]byte("Bucardo stopped"))
+	writeMessage(w, http.StatusOK, "Bucardo stopped")
 }
 
 func (h *HTTPServer) handleRestart(w http.ResponseWriter, r *http.Request) {
-	// Restarting involves reloading config and reconciling
-	if err := h.service.ReloadAndRestart(r.Context()); err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+	// Restarting involves reloading config and reconciling, which can take minutes,
+	// so it runs as a job the client can poll.
+	job, err := h.service.StartReconcileJob(r.Context())
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	w.Header().Set("Location", "/jobs/"+job.ID)
+	writeJSON(w, http.StatusAccepted, job)
+}
+
+func (h *HTTPServer) handleGetLogLevel(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, h.service.GetServiceLogLevels())
+}
+
+func (h *HTTPServer) handleSetLogLevel(w http.ResponseWriter, r *http.Request) {
+	var levels domain.ServiceLogConfig
+	if err := json.NewDecoder(r.Body).Decode(&levels); err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid JSON")
+		return
+	}
+	current, err := h.service.SetServiceLogLevels(r.Context(), levels)
+	if err != nil {
+		if errors.Is(err, orchestrator.ErrInvalidLogLevel) {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, current)
+}
+
+func (h *HTTPServer) handleListJobs(w http.ResponseWriter, r *http.Request) {
+	jobs := h.service.ListJobs(r.Context(), r.URL.Query().Get("type"))
+	writeJSON(w, http.StatusOK, jobs)
+}
+
+func (h *HTTPServer) handleGetJob(w http.ResponseWriter, r *http.Request) {
+	job, err := h.service.GetJob(r.Context(), r.PathValue("id"))
+	if err != nil {
+		writeError(w, http.StatusNotFound, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, job)
+}
+
+func (h *HTTPServer) handleCancelJob(w http.ResponseWriter, r *http.Request) {
+	if _, err := h.service.CancelJob(r.Context(), r.PathValue("id")); err != nil {
+		switch {
+		case errors.Is(err, orchestrator.ErrJobNotFound):
+			writeError(w, http.StatusNotFound, err.Error())
+		case errors.Is(err, orchestrator.ErrJobFinished):
+			writeError(w, http.StatusConflict, err.Error())
+		default:
+			writeError(w, http.StatusInternalServerError, err.Error())
+		}
 		return
 	}
-	w.Write([]byte("Application reloaded and restarted"))
+	writeMessage(w, http.StatusAccepted, "Job cancellation requested")
 }
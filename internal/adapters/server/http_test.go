@@ -0,0 +1,147 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"replication-service/internal/adapters/audit"
+	"replication-service/internal/adapters/fake"
+	"replication-service/internal/adapters/logger"
+	"replication-service/internal/adapters/tracing"
+	"replication-service/internal/core/domain"
+	"replication-service/internal/core/services/orchestrator"
+)
+
+// testServer is the management API wired to in-memory adapters.
+type testServer struct {
+	*HTTPServer
+	config  *fake.ConfigProvider
+	bucardo *fake.BucardoExecutor
+	audit   *audit.JSONLLog
+	levels  *logger.LevelController
+}
+
+func newTestServer(t *testing.T, config *domain.BucardoConfig) *testServer {
+	t.Helper()
+	dir := t.TempDir()
+	configPath := filepath.Join(dir, "bucardo.json")
+	if err := os.WriteFile(configPath, []byte("{}"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	appLogger := logger.NewSlogAdapter(slog.New(slog.NewTextHandler(io.Discard, nil)))
+	ts := &testServer{
+		config:  fake.NewConfigProvider(config),
+		bucardo: fake.NewBucardoExecutor(),
+		audit:   audit.NewJSONLLog(appLogger, filepath.Join(dir, "audit.jsonl")),
+		levels:  logger.NewLevelController(slog.LevelInfo),
+	}
+	service := orchestrator.NewService(
+		appLogger,
+		ts.config,
+		fake.NewCredentialManager(),
+		ts.bucardo,
+		fake.NewMonitor(),
+		nil,
+		fake.NewNotifier(),
+		tracing.NewOTelTracer(),
+		ts.levels,
+		logger.NewRedactor(),
+		configPath, "", "bucardo", "bucardo", "",
+	)
+	// Bucardo starts out matching the configuration.
+	if config != nil {
+		if err := service.ReloadAndRestart(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+	}
+	ts.HTTPServer = NewHTTPServer(appLogger, service, NewLogBroadcaster(100, "", ""), ts.audit, "", 0)
+	return ts
+}
+
+// do sends a request through the server's full middleware chain.
+func (ts *testServer) do(method, target, body string) *httptest.ResponseRecorder {
+	var reader io.Reader
+	if body != "" {
+		reader = strings.NewReader(body)
+	}
+	req := httptest.NewRequest(method, target, reader)
+	if body != "" {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	rec := httptest.NewRecorder()
+	ts.server.Handler.ServeHTTP(rec, req)
+	return rec
+}
+
+// testConfig has two databases and one sync between them.
+func testConfig() *domain.BucardoConfig {
+	return &domain.BucardoConfig{
+		Databases: []domain.Database{
+			{ID: 1, DBName: "app", Host: "pg1", User: "replicator", Pass: "s3cret-pass"},
+			{ID: 2, DBName: "app", Host: "pg2", User: "replicator", Pass: "s3cret-pass"},
+		},
+		Syncs: []domain.Sync{
+			{Name: "orders", Sources: []domain.DBRef{"1"}, Targets: []domain.DBRef{"2"}, Tables: "public.orders"},
+		},
+	}
+}
+
+func TestSetLogLevel(t *testing.T) {
+	ts := newTestServer(t, testConfig())
+	var logs bytes.Buffer
+	root := slog.New(ts.levels.Handler(slog.NewTextHandler(&logs, &slog.HandlerOptions{Level: slog.LevelDebug})))
+	jobs := root.With("component", "jobs")
+	shown := func(log func(msg string, args ...any), args ...any) bool {
+		logs.Reset()
+		log("line", args...)
+		return logs.Len() > 0
+	}
+	if shown(root.Debug) || !shown(jobs.Info) {
+		t.Fatal("the initial level is not info")
+	}
+
+	// A change through the API applies to loggers that already exist.
+	rec := ts.do("PUT", "/loglevel", `{"level":"debug","components":{"jobs":"warn"}}`)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("PUT /loglevel = %d: %s", rec.Code, rec.Body)
+	}
+	var levels domain.ServiceLogConfig
+	if err := json.Unmarshal(rec.Body.Bytes(), &levels); err != nil {
+		t.Fatal(err)
+	}
+	if levels.Level != "debug" || levels.Components["jobs"] != "warn" {
+		t.Errorf("levels = %+v", levels)
+	}
+	checks := []struct {
+		name        string
+		shown, want bool
+	}{
+		{"debug line", shown(root.Debug), true},
+		{"info line with the component attribute", shown(root.Info, "component", "jobs"), false},
+		{"info line of the component bound with With", shown(jobs.Info), false},
+		{"warning of the component", shown(jobs.Warn), true},
+		{"debug line of another component", shown(root.Debug, "component", "api"), true},
+	}
+	for _, check := range checks {
+		if check.shown != check.want {
+			t.Errorf("%s: shown = %t, want %t", check.name, check.shown, check.want)
+		}
+	}
+
+	// An empty component level removes the override.
+	if rec := ts.do("PUT", "/loglevel", `{"components":{"jobs":""}}`); rec.Code != http.StatusOK {
+		t.Fatalf("PUT /loglevel = %d: %s", rec.Code, rec.Body)
+	}
+	if !shown(jobs.Debug) {
+		t.Error("the component did not fall back to the global level")
+	}
+}
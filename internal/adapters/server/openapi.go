@@ -0,0 +1,237 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"replication-service/internal/core/domain"
+)
+
+var pathParamRe = regexp.MustCompile(`\{(\w+)\}`)
+
+// openAPIDocument builds an OpenAPI 3 document from the route table. Schemas are derived
+// from the Go types of the request and response bodies, following their JSON tags.
+func openAPIDocument(routes []route) map[string]any {
+	schemas := &schemaBuilder{components: make(map[string]any)}
+	errorSchema := schemas.schema(reflect.TypeOf(domain.ErrorResponse{}))
+
+	paths := make(map[string]any)
+	for _, rt := range routes {
+		op := map[string]any{
+			"operationId": operationID(rt.method, rt.pattern),
+			"summary":     rt.summary,
+			"tags":        []string{rt.tag},
+		}
+
+		declared := make(map[string]param)
+		for _, p := range rt.params {
+			declared[p.in+":"+p.name] = p
+		}
+		var parameters []any
+		for _, m := range pathParamRe.FindAllStringSubmatch(rt.pattern, -1) {
+			p, ok := declared["path:"+m[1]]
+			if !ok {
+				p = pathParam(m[1], "string", "")
+			}
+			parameters = append(parameters, parameterObject(p, true))
+		}
+		for _, p := range rt.params {
+			if p.in != "path" {
+				parameters = append(parameters, parameterObject(p, false))
+			}
+		}
+		if len(parameters) > 0 {
+			op["parameters"] = parameters
+		}
+
+		if rt.body != nil {
+			op["requestBody"] = map[string]any{
+				"required": true,
+				"content":  map[string]any{"application/json": map[string]any{"schema": schemas.schema(reflect.TypeOf(rt.body))}},
+			}
+		}
+
+		responses := make(map[string]any)
+		for _, resp := range rt.responses {
+			description := resp.description
+			if description == "" {
+				description = http.StatusText(resp.status)
+			}
+			object := map[string]any{"description": description}
+			switch {
+			case resp.body != nil:
+				object["content"] = map[string]any{"application/json": map[string]any{"schema": schemas.schema(reflect.TypeOf(resp.body))}}
+			case resp.contentType != "":
+				object["content"] = map[string]any{resp.contentType: map[string]any{"schema": map[string]any{"type": "string"}}}
+			}
+			responses[strconv.Itoa(resp.status)] = object
+		}
+		for _, status := range rt.errors {
+			responses[strconv.Itoa(status)] = map[string]any{
+				"description": http.StatusText(status),
+				"content":     map[string]any{"application/json": map[string]any{"schema": errorSchema}},
+			}
+		}
+		op["responses"] = responses
+
+		item, _ := paths[rt.pattern].(map[string]any)
+		if item == nil {
+			item = make(map[string]any)
+			paths[rt.pattern] = item
+		}
+		item[strings.ToLower(rt.method)] = op
+	}
+
+	return map[string]any{
+		"openapi": "3.0.3",
+		"info": map[string]any{
+			"title":       "Bucardo replication management API",
+			"version":     "1",
+			"description": "Every error response has a JSON body with error and status. Actions without a resource to return answer with a JSON body with message. When the service runs with API_TOKEN, requests without the token are answered with 401.",
+		},
+		"paths": paths,
+		"components": map[string]any{
+			"schemas": schemas.components,
+			"securitySchemes": map[string]any{
+				"bearerAuth": map[string]any{"type": "http", "scheme": "bearer", "description": "Required when the service runs with API_TOKEN."},
+			},
+		},
+		// The empty requirement keeps the token optional for services running without one.
+		"security": []any{map[string]any{"bearerAuth": []string{}}, map[string]any{}},
+	}
+}
+
+func parameterObject(p param, required bool) map[string]any {
+	object := map[string]any{
+		"name":     p.name,
+		"in":       p.in,
+		"required": required,
+		"schema":   map[string]any{"type": p.typ},
+	}
+	if p.description != "" {
+		object["description"] = p.description
+	}
+	return object
+}
+
+// operationID turns "GET /config/history/{rev}" into "getConfigHistoryRev".
+func operationID(method, pattern string) string {
+	var b strings.Builder
+	b.WriteString(strings.ToLower(method))
+	words := strings.FieldsFunc(pattern, func(r rune) bool {
+		return !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9')
+	})
+	for _, word := range words {
+		b.WriteString(strings.ToUpper(word[:1]) + word[1:])
+	}
+	return b.String()
+}
+
+// schemaBuilder derives JSON schemas from Go types. Named structs are placed in components
+// and referenced.
+type schemaBuilder struct {
+	components map[string]any
+}
+
+var (
+	timeType       = reflect.TypeOf(time.Time{})
+	rawMessageType = reflect.TypeOf(json.RawMessage{})
+	dbRefType      = reflect.TypeOf(domain.DBRef(""))
+)
+
+func (b *schemaBuilder) schema(t reflect.Type) map[string]any {
+	switch t {
+	case timeType:
+		return map[string]any{"type": "string", "format": "date-time"}
+	case rawMessageType:
+		return map[string]any{}
+	case dbRefType:
+		return map[string]any{
+			"oneOf":       []any{map[string]any{"type": "string"}, map[string]any{"type": "integer"}},
+			"description": "Database name, or ID for a database referenced by ID.",
+		}
+	}
+
+	switch t.Kind() {
+	case reflect.Pointer:
+		s := b.schema(t.Elem())
+		if _, isRef := s["$ref"]; isRef {
+			return map[string]any{"allOf": []any{s}, "nullable": true}
+		}
+		s["nullable"] = true
+		return s
+	case reflect.Struct:
+		if t.Name() == "" {
+			return b.object(t)
+		}
+		if _, ok := b.components[t.Name()]; !ok {
+			b.components[t.Name()] = map[string]any{} // Placeholder for self-referencing types.
+			b.components[t.Name()] = b.object(t)
+		}
+		return map[string]any{"$ref": "#/components/schemas/" + t.Name()}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return map[string]any{"type": "string", "format": "byte"}
+		}
+		return map[string]any{"type": "array", "items": b.schema(t.Elem())}
+	case reflect.Map:
+		return map[string]any{"type": "object", "additionalProperties": b.schema(t.Elem())}
+	case reflect.String:
+		return map[string]any{"type": "string"}
+	case reflect.Bool:
+		return map[string]any{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32:
+		return map[string]any{"type": "integer"}
+	case reflect.Int64, reflect.Uint64:
+		return map[string]any{"type": "integer", "format": "int64"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]any{"type": "number"}
+	}
+	return map[string]any{} // Interfaces: any JSON value.
+}
+
+// object builds the schema of a struct. Fields without omitempty are always present in
+// responses and are listed as required.
+func (b *schemaBuilder) object(t reflect.Type) map[string]any {
+	properties := make(map[string]any)
+	var required []string
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := field.Tag.Get("json")
+		if !field.IsExported() || tag == "-" {
+			continue
+		}
+		name, options, _ := strings.Cut(tag, ",")
+		if field.Anonymous && name == "" && field.Type.Kind() == reflect.Struct {
+			embedded := b.object(field.Type)
+			for k, v := range embedded["properties"].(map[string]any) {
+				properties[k] = v
+			}
+			if r, ok := embedded["required"].([]string); ok {
+				required = append(required, r...)
+			}
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		properties[name] = b.schema(field.Type)
+		if !strings.Contains(options, "omitempty") {
+			required = append(required, name)
+		}
+	}
+	object := map[string]any{"type": "object", "properties": properties}
+	if len(required) > 0 {
+		object["required"] = required
+	}
+	return object
+}
+
+func (h *HTTPServer) handleOpenAPI(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, h.openapi)
+}
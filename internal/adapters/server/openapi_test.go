@@ -0,0 +1,278 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// undocumentedPatterns are served outside the API and left out of the OpenAPI document.
+var undocumentedPatterns = map[string]bool{
+	"GET /ui/": true, // The dashboard's static files.
+}
+
+// apiCall is a request to one route of the API and the status it is expected to get.
+type apiCall struct {
+	route  string // Method and pattern, as in the OpenAPI document.
+	target string
+	body   string
+	status int
+}
+
+// TestOpenAPIMatchesRoutes calls every route and checks the responses against the OpenAPI
+// document the server publishes, so that the two cannot drift apart.
+func TestOpenAPIMatchesRoutes(t *testing.T) {
+	ts := newTestServer(t, testConfig())
+	rec := ts.do("GET", "/openapi.json", "")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("GET /openapi.json = %d", rec.Code)
+	}
+	var doc map[string]any
+	if err := json.Unmarshal(rec.Body.Bytes(), &doc); err != nil {
+		t.Fatal(err)
+	}
+	operations := make(map[string]map[string]any)
+	for path, item := range doc["paths"].(map[string]any) {
+		for method, op := range item.(map[string]any) {
+			operations[strings.ToUpper(method)+" "+path] = op.(map[string]any)
+		}
+	}
+
+	for _, pattern := range ts.patterns {
+		if _, ok := operations[pattern]; !ok && !undocumentedPatterns[pattern] {
+			t.Errorf("%s is served but missing from the OpenAPI document", pattern)
+		}
+	}
+
+	sync := `{"name":"mesh","sources":[2],"targets":[1],"tables":"public.events"}`
+	calls := []apiCall{
+		{"GET /config", "/config", "", http.StatusOK},
+		{"POST /config", "/config", "{not json", http.StatusBadRequest},
+		{"GET /config/history", "/config/history", "", http.StatusOK},
+		{"POST /syncs", "/syncs", sync, http.StatusCreated},
+		{"POST /syncs", "/syncs", sync, http.StatusBadRequest},
+		{"GET /syncs", "/syncs", "", http.StatusOK},
+		{"GET /syncs/{name}", "/syncs/mesh", "", http.StatusOK},
+		{"GET /syncs/{name}", "/syncs/missing", "", http.StatusNotFound},
+		{"PUT /syncs/{name}", "/syncs/mesh", sync, http.StatusOK},
+		{"PUT /syncs/{name}", "/syncs/missing", sync, http.StatusNotFound},
+		{"POST /syncs/{name}/pause", "/syncs/orders/pause", "", http.StatusOK},
+		{"POST /syncs/{name}/resume", "/syncs/orders/resume", "", http.StatusOK},
+		{"DELETE /syncs/{name}", "/syncs/mesh", "", http.StatusOK},
+		{"DELETE /syncs/{name}", "/syncs/mesh", "", http.StatusNotFound},
+		{"GET /config/history/{rev}", "/config/history/1", "", http.StatusOK},
+		{"GET /config/history/{rev}", "/config/history/99", "", http.StatusNotFound},
+		{"GET /config/history/{rev}/diff", "/config/history/2/diff", "", http.StatusOK},
+		{"POST /config/rollback/{rev}", "/config/rollback/1", "", http.StatusOK},
+		{"POST /config/rollback/{rev}", "/config/rollback/x", "", http.StatusBadRequest},
+		{"POST /config", "/config", `{"databases":[],"syncs":[]}`, http.StatusOK},
+		{"POST /config/rollback/{rev}", "/config/rollback/6?apply=true", "", http.StatusAccepted},
+		{"POST /syncs/{name}/kick", "/syncs/orders/kick", "", http.StatusOK},
+		{"POST /syncs/{name}/kick", "/syncs/orders/kick?wait=x", "", http.StatusBadRequest},
+		{"GET /syncs/{name}/status", "/syncs/orders/status", "", http.StatusOK},
+		{"POST /syncs/{name}/recopy", "/syncs/orders/recopy", `{"tables":["public.missing"]}`, http.StatusBadRequest},
+		{"POST /syncs/{name}/recopy", "/syncs/orders/recopy", "", http.StatusAccepted},
+		{"GET /syncs/{name}/recopy", "/syncs/orders/recopy", "", http.StatusOK},
+		{"POST /syncs/{name}/verify", "/syncs/missing/verify", "", http.StatusNotFound},
+		{"GET /syncs/{name}/verify/{run}", "/syncs/orders/verify/unknown", "", http.StatusNotFound},
+		{"GET /deltas", "/deltas", "", http.StatusOK},
+		{"POST /databases/{id}/purge-deltas", "/databases/1/purge-deltas?min_age=x", "", http.StatusBadRequest},
+		{"POST /start", "/start", "", http.StatusOK},
+		{"POST /stop", "/stop", "", http.StatusOK},
+		{"POST /restart", "/restart", "", http.StatusAccepted},
+		{"GET /loglevel", "/loglevel", "", http.StatusOK},
+		{"PUT /loglevel", "/loglevel", `{"level":"DEBUG","components":{"jobs":"WARN"}}`, http.StatusOK},
+		{"PUT /loglevel", "/loglevel", `{"level":"LOUD"}`, http.StatusBadRequest},
+		{"GET /jobs", "/jobs", "", http.StatusOK},
+		{"GET /jobs/{id}", "/jobs/unknown", "", http.StatusNotFound},
+		{"DELETE /jobs/{id}", "/jobs/unknown", "", http.StatusNotFound},
+		{"GET /audit", "/audit?limit=5", "", http.StatusOK},
+		{"GET /audit", "/audit?since=yesterday", "", http.StatusBadRequest},
+		{"GET /logs", "/logs?tail=x", "", http.StatusBadRequest},
+		{"GET /logs", "/logs", "", http.StatusBadRequest}, // Not a WebSocket handshake.
+		{"GET /logs/clients", "/logs/clients", "", http.StatusOK},
+		{"GET /events", "/events?level=LOUD", "", http.StatusBadRequest},
+		{"GET /openapi.json", "/openapi.json", "", http.StatusOK},
+	}
+
+	called := make(map[string]bool)
+	check := func(call apiCall, rec *httptest.ResponseRecorder) {
+		t.Helper()
+		called[call.route] = true
+		name := fmt.Sprintf("%s %s", strings.Fields(call.route)[0], call.target)
+		if rec.Code != call.status {
+			t.Errorf("%s = %d, want %d: %s", name, rec.Code, call.status, rec.Body)
+			return
+		}
+		op, ok := operations[call.route]
+		if !ok {
+			t.Errorf("%s: %s is not in the OpenAPI document", name, call.route)
+			return
+		}
+		resp, ok := op["responses"].(map[string]any)[strconv.Itoa(rec.Code)].(map[string]any)
+		if !ok {
+			t.Errorf("%s: status %d is not documented", name, rec.Code)
+			return
+		}
+		content, _ := resp["content"].(map[string]any)
+		if len(content) == 0 {
+			if rec.Body.Len() > 0 {
+				t.Errorf("%s: documented without a body, got %s", name, rec.Body)
+			}
+			return
+		}
+		for contentType, media := range content {
+			if got := rec.Header().Get("Content-Type"); !strings.HasPrefix(got, contentType) {
+				t.Errorf("%s: Content-Type = %q, want %q", name, got, contentType)
+				continue
+			}
+			if contentType != "application/json" {
+				continue
+			}
+			var body any
+			if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+				t.Errorf("%s: body is not JSON: %v", name, err)
+				continue
+			}
+			for _, problem := range validateSchema(doc, media.(map[string]any)["schema"].(map[string]any), body, "body") {
+				t.Errorf("%s: %s", name, problem)
+			}
+		}
+	}
+
+	for _, call := range calls {
+		method, _, _ := strings.Cut(call.route, " ")
+		check(call, ts.do(method, call.target, call.body))
+	}
+
+	// Streams: the event stream answers until the client leaves, and the log stream needs a
+	// WebSocket handshake.
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	req := httptest.NewRequest("GET", "/events?tail=5", nil).WithContext(ctx)
+	rec = httptest.NewRecorder()
+	ts.server.Handler.ServeHTTP(rec, req)
+	check(apiCall{route: "GET /events", target: "/events?tail=5", status: http.StatusOK}, rec)
+
+	srv := httptest.NewServer(ts.server.Handler)
+	defer srv.Close()
+	conn, resp, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(srv.URL, "http")+"/logs?tail=5", nil)
+	if err != nil {
+		t.Fatalf("GET /logs: %v", err)
+	}
+	conn.Close()
+	called["GET /logs"] = true
+	if _, ok := operations["GET /logs"]["responses"].(map[string]any)[strconv.Itoa(resp.StatusCode)]; !ok {
+		t.Errorf("GET /logs: status %d is not documented", resp.StatusCode)
+	}
+
+	var missing []string
+	for route := range operations {
+		if !called[route] {
+			missing = append(missing, route)
+		}
+	}
+	sort.Strings(missing)
+	for _, route := range missing {
+		t.Errorf("%s is documented but not called by this test", route)
+	}
+}
+
+// validateSchema checks a decoded JSON value against an OpenAPI schema object and returns
+// the problems found. It covers the subset of OpenAPI that openAPIDocument generates.
+func validateSchema(doc map[string]any, schema map[string]any, value any, path string) []string {
+	if ref, ok := schema["$ref"].(string); ok {
+		name := strings.TrimPrefix(ref, "#/components/schemas/")
+		resolved, ok := doc["components"].(map[string]any)["schemas"].(map[string]any)[name].(map[string]any)
+		if !ok {
+			return []string{fmt.Sprintf("%s: unresolved reference %s", path, ref)}
+		}
+		return validateSchema(doc, resolved, value, path)
+	}
+	if value == nil {
+		if nullable, _ := schema["nullable"].(bool); nullable || len(schema) == 0 {
+			return nil
+		}
+		return []string{fmt.Sprintf("%s: null where the schema does not allow it", path)}
+	}
+	if all, ok := schema["allOf"].([]any); ok {
+		var problems []string
+		for _, s := range all {
+			problems = append(problems, validateSchema(doc, s.(map[string]any), value, path)...)
+		}
+		return problems
+	}
+	if one, ok := schema["oneOf"].([]any); ok {
+		for _, s := range one {
+			if len(validateSchema(doc, s.(map[string]any), value, path)) == 0 {
+				return nil
+			}
+		}
+		return []string{fmt.Sprintf("%s: %v matches none of the oneOf schemas", path, value)}
+	}
+
+	switch schema["type"] {
+	case "object":
+		object, ok := value.(map[string]any)
+		if !ok {
+			return []string{fmt.Sprintf("%s: %T where an object is documented", path, value)}
+		}
+		var problems []string
+		required, _ := schema["required"].([]any)
+		for _, name := range required {
+			if _, ok := object[name.(string)]; !ok {
+				problems = append(problems, fmt.Sprintf("%s: required property %s is missing", path, name))
+			}
+		}
+		properties, _ := schema["properties"].(map[string]any)
+		additional, hasAdditional := schema["additionalProperties"].(map[string]any)
+		for name, v := range object {
+			switch s, ok := properties[name].(map[string]any); {
+			case ok:
+				problems = append(problems, validateSchema(doc, s, v, path+"."+name)...)
+			case hasAdditional:
+				problems = append(problems, validateSchema(doc, additional, v, path+"."+name)...)
+			default:
+				problems = append(problems, fmt.Sprintf("%s: property %s is not documented", path, name))
+			}
+		}
+		return problems
+	case "array":
+		array, ok := value.([]any)
+		if !ok {
+			return []string{fmt.Sprintf("%s: %T where an array is documented", path, value)}
+		}
+		var problems []string
+		for i, v := range array {
+			problems = append(problems, validateSchema(doc, schema["items"].(map[string]any), v, fmt.Sprintf("%s[%d]", path, i))...)
+		}
+		return problems
+	case "string":
+		if _, ok := value.(string); !ok {
+			return []string{fmt.Sprintf("%s: %T where a string is documented", path, value)}
+		}
+	case "integer":
+		if n, ok := value.(float64); !ok || n != math.Trunc(n) {
+			return []string{fmt.Sprintf("%s: %v where an integer is documented", path, value)}
+		}
+	case "number":
+		if _, ok := value.(float64); !ok {
+			return []string{fmt.Sprintf("%s: %T where a number is documented", path, value)}
+		}
+	case "boolean":
+		if _, ok := value.(bool); !ok {
+			return []string{fmt.Sprintf("%s: %T where a boolean is documented", path, value)}
+		}
+	}
+	return nil
+}
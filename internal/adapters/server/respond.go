@@ -0,0 +1,25 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"replication-service/internal/core/domain"
+)
+
+// writeJSON writes v as the JSON body of a response with the given status.
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+// writeMessage confirms an action with a domain.MessageResponse.
+func writeMessage(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, domain.MessageResponse{Message: message})
+}
+
+// writeError reports a failure with a domain.ErrorResponse.
+func writeError(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, domain.ErrorResponse{Error: message, Status: status})
+}
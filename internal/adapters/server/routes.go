@@ -0,0 +1,197 @@
+package server
+
+import (
+	"net/http"
+
+	"replication-service/internal/core/domain"
+)
+
+// route is an entry of the API's route table. The table both registers the handlers and
+// describes them in the OpenAPI document, so the two cannot drift apart.
+type route struct {
+	method    string
+	pattern   string
+	handler   http.HandlerFunc
+	tag       string
+	summary   string
+	params    []param    // Query and header parameters, and path parameters that are not strings.
+	body      any        // Zero value of the request body type, if the route takes one.
+	responses []response // Successful responses.
+	errors    []int      // Error statuses, answered with a domain.ErrorResponse.
+
+	changesConfig bool // Whether the route can change the configuration; audited with the changes.
+}
+
+// param describes a query, header or path parameter. typ is an OpenAPI primitive type.
+type param struct {
+	name        string
+	in          string
+	typ         string
+	description string
+}
+
+// response describes a successful response. A nil body means no JSON body; contentType
+// then names what is sent instead, if anything.
+type response struct {
+	status      int
+	body        any
+	contentType string
+	description string
+}
+
+func queryParam(name, typ, description string) param {
+	return param{name: name, in: "query", typ: typ, description: description}
+}
+
+func headerParam(name, typ, description string) param {
+	return param{name: name, in: "header", typ: typ, description: description}
+}
+
+func pathParam(name, typ, description string) param {
+	return param{name: name, in: "path", typ: typ, description: description}
+}
+
+func ok(body any) response {
+	return response{status: http.StatusOK, body: body}
+}
+
+func accepted(body any) response {
+	return response{status: http.StatusAccepted, body: body}
+}
+
+// routes returns the route table of the management API.
+func (h *HTTPServer) routes() []route {
+	const (
+		badRequest = http.StatusBadRequest
+		notFound   = http.StatusNotFound
+		conflict   = http.StatusConflict
+		stale      = http.StatusPreconditionFailed
+		internal   = http.StatusInternalServerError
+	)
+	revision := pathParam("rev", "integer", "Revision number.")
+	ifMatch := headerParam("If-Match", "string", `Configuration revision the change is based on, as returned in ETag, e.g. "12".`)
+	message := domain.MessageResponse{}
+	job := domain.Job{}
+
+	return []route{
+		{method: "GET", pattern: "/config", handler: h.handleGetConfig, tag: "config",
+			summary: "Get the full configuration", responses: []response{ok(domain.BucardoConfig{})}, errors: []int{internal}},
+		{method: "POST", pattern: "/config", handler: h.handleUpdateConfig, tag: "config", changesConfig: true,
+			summary: "Replace the full configuration", params: []param{ifMatch}, body: domain.BucardoConfig{},
+			responses: []response{ok(message)}, errors: []int{badRequest, stale, internal}},
+		{method: "GET", pattern: "/config/history", handler: h.handleListConfigHistory, tag: "config",
+			summary: "List configuration revisions, newest first", responses: []response{ok([]domain.ConfigRevision{})}, errors: []int{internal}},
+		{method: "GET", pattern: "/config/history/{rev}", handler: h.handleGetConfigRevision, tag: "config",
+			summary: "Get a configuration revision", params: []param{revision},
+			responses: []response{ok(domain.ConfigRevisionDetail{})}, errors: []int{badRequest, notFound, internal}},
+		{method: "GET", pattern: "/config/history/{rev}/diff", handler: h.handleDiffConfigRevision, tag: "config",
+			summary:   "List the changes between two configuration revisions",
+			params:    []param{revision, queryParam("against", "integer", "Revision to compare with. Defaults to rev - 1.")},
+			responses: []response{ok(domain.ConfigDiff{})}, errors: []int{badRequest, notFound, internal}},
+		{method: "POST", pattern: "/config/rollback/{rev}", handler: h.handleRollbackConfig, tag: "config", changesConfig: true,
+			summary:   "Restore an earlier configuration revision",
+			params:    []param{revision, queryParam("apply", "boolean", "Start a reconcile job right away."), ifMatch},
+			responses: []response{ok(message), accepted(job)}, errors: []int{badRequest, notFound, stale, internal}},
+
+		{method: "GET", pattern: "/syncs", handler: h.handleListSyncs, tag: "syncs",
+			summary: "List syncs", responses: []response{ok([]domain.Sync{})}, errors: []int{internal}},
+		{method: "POST", pattern: "/syncs", handler: h.handleCreateSync, tag: "syncs", changesConfig: true,
+			summary: "Add a sync", params: []param{ifMatch}, body: domain.Sync{},
+			responses: []response{{status: http.StatusCreated, body: message}}, errors: []int{badRequest, stale, internal}},
+		{method: "GET", pattern: "/syncs/{name}", handler: h.handleGetSync, tag: "syncs",
+			summary: "Get a sync", responses: []response{ok(domain.Sync{})}, errors: []int{notFound}},
+		{method: "PUT", pattern: "/syncs/{name}", handler: h.handleUpdateSync, tag: "syncs", changesConfig: true,
+			summary: "Replace a sync", params: []param{ifMatch}, body: domain.Sync{},
+			responses: []response{ok(message)}, errors: []int{badRequest, notFound, stale, internal}},
+		{method: "DELETE", pattern: "/syncs/{name}", handler: h.handleDeleteSync, tag: "syncs", changesConfig: true,
+			summary: "Remove a sync", params: []param{ifMatch}, responses: []response{ok(message)}, errors: []int{badRequest, notFound, stale, internal}},
+		{method: "POST", pattern: "/syncs/{name}/kick", handler: h.handleKickSync, tag: "syncs",
+			summary:   "Run a sync now",
+			params:    []param{queryParam("wait", "integer", "Seconds to wait for the run to finish.")},
+			responses: []response{ok(domain.SyncRunResult{})}, errors: []int{badRequest, notFound, internal}},
+		{method: "GET", pattern: "/syncs/{name}/status", handler: h.handleGetSyncStatus, tag: "syncs",
+			summary: "Get the current state of a sync as reported by Bucardo", responses: []response{ok(domain.SyncRunResult{})}, errors: []int{notFound, internal}},
+		{method: "POST", pattern: "/syncs/{name}/pause", handler: h.handlePauseSync, tag: "syncs", changesConfig: true,
+			summary: "Pause a sync", params: []param{ifMatch}, responses: []response{ok(message)}, errors: []int{badRequest, notFound, stale, internal}},
+		{method: "POST", pattern: "/syncs/{name}/resume", handler: h.handleResumeSync, tag: "syncs", changesConfig: true,
+			summary: "Resume a paused sync", params: []param{ifMatch}, responses: []response{ok(message)}, errors: []int{badRequest, notFound, stale, internal}},
+		{method: "POST", pattern: "/syncs/{name}/recopy", handler: h.handleStartRecopy, tag: "syncs",
+			summary: "Start a full copy of a sync or some of its tables", body: domain.RecopyRequest{},
+			responses: []response{accepted(domain.RecopyStatus{})}, errors: []int{badRequest, notFound, conflict}},
+		{method: "GET", pattern: "/syncs/{name}/recopy", handler: h.handleGetRecopy, tag: "syncs",
+			summary: "Get the latest re-copy of a sync", responses: []response{ok(domain.RecopyStatus{})}, errors: []int{notFound}},
+		{method: "POST", pattern: "/syncs/{name}/verify", handler: h.handleStartVerify, tag: "syncs",
+			summary: "Start comparing a sync's source and targets", body: domain.VerifyRequest{},
+			responses: []response{accepted(domain.VerifyRun{})}, errors: []int{badRequest, notFound}},
+		{method: "GET", pattern: "/syncs/{name}/verify/{run}", handler: h.handleGetVerify, tag: "syncs",
+			summary: "Get a verification run", responses: []response{ok(domain.VerifyRun{})}, errors: []int{notFound}},
+
+		{method: "GET", pattern: "/deltas", handler: h.handleListDeltas, tag: "deltas",
+			summary:   "List the delta backlog of source databases",
+			params:    []param{queryParam("refresh", "boolean", "Measure now instead of returning the last measurement.")},
+			responses: []response{ok([]domain.DeltaBacklog{})}, errors: []int{internal}},
+		{method: "POST", pattern: "/databases/{id}/purge-deltas", handler: h.handlePurgeDeltas, tag: "deltas",
+			summary: "Delete delta rows every target has replicated",
+			params: []param{
+				pathParam("id", "string", "Database name, or ID for a database referenced by ID."),
+				queryParam("dry_run", "boolean", "Only count the rows that would be deleted."),
+				queryParam("min_age", "integer", "Only delete rows older than this many seconds. Defaults to 60."),
+			},
+			responses: []response{ok(domain.DeltaPurgeReport{})}, errors: []int{badRequest, notFound, internal}},
+
+		{method: "POST", pattern: "/start", handler: h.handleStart, tag: "lifecycle",
+			summary: "Start Bucardo", responses: []response{ok(message)}, errors: []int{internal}},
+		{method: "POST", pattern: "/stop", handler: h.handleStop, tag: "lifecycle",
+			summary: "Stop Bucardo", responses: []response{ok(message)}, errors: []int{internal}},
+		{method: "POST", pattern: "/restart", handler: h.handleRestart, tag: "lifecycle",
+			summary: "Reconcile Bucardo with the configuration and restart it", responses: []response{accepted(job)}, errors: []int{internal}},
+
+		{method: "GET", pattern: "/loglevel", handler: h.handleGetLogLevel, tag: "lifecycle",
+			summary: "Get the level of the service's own logs", responses: []response{ok(domain.ServiceLogConfig{})}},
+		{method: "PUT", pattern: "/loglevel", handler: h.handleSetLogLevel, tag: "lifecycle",
+			summary: "Change the level of the service's own logs, globally or per component, until the next reconcile",
+			body:    domain.ServiceLogConfig{}, responses: []response{ok(domain.ServiceLogConfig{})}, errors: []int{badRequest, internal}},
+
+		{method: "GET", pattern: "/jobs", handler: h.handleListJobs, tag: "jobs",
+			summary:   "List jobs, newest first",
+			params:    []param{queryParam("type", "string", "Only jobs of this type: reconcile, recopy or verify.")},
+			responses: []response{ok([]domain.Job{})}},
+		{method: "GET", pattern: "/jobs/{id}", handler: h.handleGetJob, tag: "jobs",
+			summary: "Get a job", responses: []response{ok(job)}, errors: []int{notFound}},
+		{method: "DELETE", pattern: "/jobs/{id}", handler: h.handleCancelJob, tag: "jobs",
+			summary: "Cancel a queued or running job", responses: []response{accepted(message)}, errors: []int{notFound, conflict, internal}},
+
+		{method: "GET", pattern: "/audit", handler: h.handleQueryAudit, tag: "audit",
+			summary: "Query the audit trail, newest first",
+			params: []param{
+				queryParam("since", "string", "RFC 3339 timestamp."),
+				queryParam("until", "string", "RFC 3339 timestamp."),
+				queryParam("actor", "string", "Only records of this actor."),
+				queryParam("kind", "string", "api or command."),
+				queryParam("correlation_id", "string", "Only records with this correlation ID."),
+				queryParam("limit", "integer", "Maximum number of records. Defaults to 500."),
+			},
+			responses: []response{ok([]domain.AuditRecord{})}, errors: []int{badRequest, internal}},
+
+		{method: "GET", pattern: "/logs", handler: h.broadcaster.HandleWebsocket, tag: "logs",
+			summary: "Stream log lines over a WebSocket", params: logStreamParams,
+			responses: []response{{status: http.StatusSwitchingProtocols, description: "WebSocket of JSON log lines"}}, errors: []int{badRequest}},
+		{method: "GET", pattern: "/logs/clients", handler: h.broadcaster.HandleStats, tag: "logs",
+			summary: "Get delivery statistics of log stream clients", responses: []response{ok(BroadcasterStats{})}},
+		{method: "GET", pattern: "/events", handler: h.broadcaster.HandleEvents, tag: "logs",
+			summary: "Stream log lines and replication events as Server-Sent Events", params: logStreamParams,
+			responses: []response{{status: http.StatusOK, contentType: "text/event-stream", description: "Server-Sent Events"}}, errors: []int{badRequest}},
+
+		{method: "GET", pattern: "/openapi.json", handler: h.handleOpenAPI, tag: "meta",
+			summary: "Get this API's OpenAPI document", responses: []response{ok(map[string]any{})}},
+	}
+}
+
+var logStreamParams = []param{
+	queryParam("tail", "integer", "Number of past lines to replay."),
+	queryParam("since", "string", "Replay lines since this RFC 3339 timestamp."),
+	queryParam("level", "string", "Lowest level to deliver: DEBUG, INFO, WARN or ERROR."),
+	queryParam("component", "string", "Only lines of this component."),
+	queryParam("sync_name", "string", "Only lines about this sync."),
+	queryParam("db_name", "string", "Only lines about this database."),
+}
@@ -0,0 +1,75 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"net/url"
+)
+
+// ErrSubscriptionEvicted is returned by Subscription.Next when the slow-client policy
+// disconnected the subscriber.
+var ErrSubscriptionEvicted = errors.New("disconnected because messages were not consumed fast enough")
+
+// StreamMessage is a log line or replication event delivered to a Subscription.
+type StreamMessage struct {
+	Seq   uint64
+	Event string // Event type for replication events; empty for log lines.
+	Data  []byte // The JSON log line, or the domain.Event as JSON.
+}
+
+// SubscribeOptions select the messages of a Subscription. Query takes the query parameters
+// of /logs (tail, since, level, component, sync_name, db_name).
+type SubscribeOptions struct {
+	Query  url.Values
+	Logs   bool
+	Events bool
+}
+
+// Subscription receives log lines and replication events for a client that is not served
+// by the HTTP server, such as a gRPC stream. It is subject to the same send queue and
+// slow-client policy as WebSocket and SSE clients, and is listed in the client stats.
+type Subscription struct {
+	broadcaster *LogBroadcaster
+	client      *streamClient
+	backlog     []logEntry
+}
+
+// Subscribe registers a subscriber. kind and remoteAddr identify it in the client stats.
+// The subscription must be closed when no longer used.
+func (b *LogBroadcaster) Subscribe(kind, remoteAddr string, opts SubscribeOptions) (*Subscription, error) {
+	filter, err := parseLogFilter(opts.Query)
+	if err != nil {
+		return nil, err
+	}
+	filter.events = opts.Events
+	filter.noLogs = !opts.Logs
+	client, backlog := b.register(kind, remoteAddr, filter)
+	return &Subscription{broadcaster: b, client: client, backlog: backlog}, nil
+}
+
+// Next returns the next message, replayed history first. It blocks until a message
+// arrives, ctx is done or the subscriber is evicted.
+func (s *Subscription) Next(ctx context.Context) (StreamMessage, error) {
+	var entry logEntry
+	if len(s.backlog) > 0 {
+		entry, s.backlog = s.backlog[0], s.backlog[1:]
+	} else {
+		select {
+		case <-ctx.Done():
+			return StreamMessage{}, ctx.Err()
+		case <-s.client.done:
+			if s.client.evicted.Load() {
+				return StreamMessage{}, ErrSubscriptionEvicted
+			}
+			return StreamMessage{}, context.Canceled
+		case entry = <-s.client.queue:
+		}
+	}
+	s.client.sent.Add(1)
+	return StreamMessage{Seq: entry.seq, Event: entry.event, Data: entry.raw}, nil
+}
+
+// Close unregisters the subscriber.
+func (s *Subscription) Close() {
+	s.broadcaster.unregister(s.client)
+}
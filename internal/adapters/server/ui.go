@@ -0,0 +1,20 @@
+package server
+
+import (
+	"embed"
+	"io/fs"
+	"net/http"
+)
+
+//go:embed ui
+var uiFiles embed.FS
+
+// uiHandler serves the embedded dashboard under /ui/. The dashboard is a static page that
+// only talks to the public API.
+func uiHandler() http.Handler {
+	files, err := fs.Sub(uiFiles, "ui")
+	if err != nil {
+		panic(err) // The directory is embedded at build time.
+	}
+	return http.StripPrefix("/ui/", http.FileServer(http.FS(files)))
+}
@@ -0,0 +1,590 @@
+// Bucardo dashboard. A static page that only uses the public management API; see
+// docs/API_INTEGRATION.md for the endpoints.
+"use strict";
+
+const TOKEN_KEY = "bucardo-api-token";
+
+// Replication events that may change the state shown on the overview.
+const STATE_EVENTS = [
+  "reconcile_started", "reconcile_finished", "reconcile_failed",
+  "sync_status_changed", "kid_died", "run_once_completed", "run_once_timed_out",
+];
+
+const state = {
+  config: { databases: [], syncs: [] },
+  statuses: {},   // Sync name -> SyncRunResult, or {error}.
+  deltas: [],
+  jobs: [],
+  editing: null,  // {name, etag} while the sync dialog edits an existing sync.
+};
+
+// ---------------------------------------------------------------------------------------
+// API access
+
+class APIError extends Error {
+  constructor(status, message) {
+    super(message);
+    this.status = status;
+  }
+}
+
+function token() {
+  return localStorage.getItem(TOKEN_KEY) || "";
+}
+
+function askToken() {
+  const value = prompt("API token (leave empty if the service runs without API_TOKEN):", token());
+  if (value === null) return false;
+  localStorage.setItem(TOKEN_KEY, value.trim());
+  return true;
+}
+
+// api calls the management API and returns {body, headers}. A 401 asks for the token and
+// retries once.
+async function api(method, path, { body, ifMatch, message } = {}, retried = false) {
+  const headers = {};
+  if (token()) headers["Authorization"] = "Bearer " + token();
+  if (body !== undefined) headers["Content-Type"] = "application/json";
+  if (ifMatch) headers["If-Match"] = ifMatch;
+  if (message) headers["X-Change-Message"] = message;
+
+  const resp = await fetch(path, { method, headers, body: body === undefined ? undefined : JSON.stringify(body) });
+  if (resp.status === 401 && !retried && askToken()) {
+    return api(method, path, { body, ifMatch, message }, true);
+  }
+  const data = await resp.json().catch(() => null);
+  if (!resp.ok) {
+    throw new APIError(resp.status, (data && data.error) || resp.statusText);
+  }
+  return { body: data, headers: resp.headers };
+}
+
+// streamURL builds the URL of a WebSocket or EventSource stream. Browsers cannot set
+// headers on those, so the token travels as the access_token query parameter.
+function streamURL(path, params, websocket) {
+  const url = new URL(path, location.href);
+  if (websocket) url.protocol = location.protocol === "https:" ? "wss:" : "ws:";
+  for (const [key, value] of Object.entries(params)) {
+    if (value !== "" && value !== undefined) url.searchParams.set(key, value);
+  }
+  if (token()) url.searchParams.set("access_token", token());
+  return url.toString();
+}
+
+// ---------------------------------------------------------------------------------------
+// Helpers
+
+function el(tag, attrs = {}, ...children) {
+  const node = document.createElement(tag);
+  for (const [key, value] of Object.entries(attrs)) {
+    if (key === "class") node.className = value;
+    else if (key.startsWith("on")) node.addEventListener(key.slice(2), value);
+    else node.setAttribute(key, value);
+  }
+  for (const child of children.flat()) {
+    if (child === null || child === undefined) continue;
+    node.append(child instanceof Node ? child : String(child));
+  }
+  return node;
+}
+
+function svg(tag, attrs = {}, text) {
+  const node = document.createElementNS("http://www.w3.org/2000/svg", tag);
+  for (const [key, value] of Object.entries(attrs)) node.setAttribute(key, value);
+  if (text !== undefined) node.textContent = text;
+  return node;
+}
+
+function formatTime(value) {
+  if (!value) return "";
+  const date = new Date(value);
+  return isNaN(date) ? value : date.toLocaleString();
+}
+
+function badge(text, kind) {
+  return el("span", { class: "badge " + (kind || "") }, text);
+}
+
+function notify(message, isError) {
+  const notice = document.getElementById("notice");
+  notice.textContent = message;
+  notice.className = isError ? "error" : "";
+  notice.hidden = false;
+  clearTimeout(notify.timer);
+  notify.timer = setTimeout(() => { notice.hidden = true; }, isError ? 15000 : 5000);
+}
+
+// run performs an action and reports its outcome.
+async function run(action) {
+  try {
+    const { body } = await action();
+    if (body && body.message) notify(body.message);
+    return body;
+  } catch (err) {
+    notify(err.message, true);
+    return null;
+  }
+}
+
+// Syncs reference databases by name, or by numeric ID for databases without one. The API
+// omits an ID of 0.
+function findDb(ref) {
+  return state.config.databases.find((d) =>
+    typeof ref === "number" ? (d.id || 0) === ref && (ref !== 0 || !d.name) : d.name === ref);
+}
+
+// dbKey is the database's name in Bucardo, which also identifies it in the UI.
+function dbKey(db) {
+  return db.name || `db${db.id || 0}`;
+}
+
+function dbLabel(db) {
+  return db.name || `#${db.id || 0}`;
+}
+
+function dbName(ref) {
+  const db = findDb(ref);
+  return db ? `${db.dbname} (${dbLabel(db)})` : `#${ref}`;
+}
+
+function dbList(refs) {
+  return refs && refs.length ? refs.map(dbName).join(", ") : "-";
+}
+
+// ---------------------------------------------------------------------------------------
+// Overview
+
+async function loadOverview() {
+  try {
+    const [config, deltas, jobs] = await Promise.all([api("GET", "/config"), api("GET", "/deltas"), api("GET", "/jobs")]);
+    state.config = config.body;
+    state.config.databases = state.config.databases || [];
+    state.config.syncs = state.config.syncs || [];
+    state.deltas = deltas.body || [];
+    state.jobs = jobs.body || [];
+  } catch (err) {
+    notify(err.message, true);
+  }
+  renderSyncs();
+  renderDatabases();
+  renderJobs();
+  renderTopology();
+  scheduleJobPolling();
+}
+
+// refreshStatuses asks Bucardo for the state of the given syncs, or of all of them. Each
+// status runs `bucardo status`, so it is fetched on demand and on events, not polled.
+async function refreshStatuses(names) {
+  names = names || state.config.syncs.map((s) => s.name);
+  await Promise.all(names.map(async (name) => {
+    try {
+      state.statuses[name] = (await api("GET", `/syncs/${encodeURIComponent(name)}/status`)).body;
+    } catch (err) {
+      state.statuses[name] = { error: err.message };
+    }
+  }));
+  renderSyncs();
+  renderTopology();
+}
+
+function stateBadge(status) {
+  if (!status) return badge("unknown");
+  if (status.error) return badge("error", "error");
+  const text = status.state || "unknown";
+  const lower = text.toLowerCase();
+  if (lower.startsWith("good") || lower.startsWith("idle")) return badge(text, "ok");
+  if (lower.includes("bad") || lower.includes("fail")) return badge(text, "error");
+  return badge(text, "warn");
+}
+
+function renderSyncs() {
+  const body = document.querySelector("#syncs tbody");
+  body.replaceChildren(...state.config.syncs.map((sync) => {
+    const status = state.statuses[sync.name];
+    const paused = sync.status === "inactive";
+    const rows = status && status.rows_deleted !== undefined ? `${status.rows_deleted} / ${status.rows_inserted}` : "";
+    return el("tr", {},
+      el("td", {}, sync.name),
+      el("td", {}, paused ? badge("paused", "warn") : badge("active", "ok")),
+      el("td", { title: status && status.error ? status.error : "" }, stateBadge(status)),
+      el("td", {}, rows),
+      el("td", {}, dbList(sync.sources)),
+      el("td", {}, dbList(sync.targets)),
+      el("td", {}, dbList(sync.bidirectional)),
+      el("td", {}, sync.herd ? `herd ${sync.herd}` : sync.tables || "-"),
+      el("td", { class: "actions" },
+        el("button", {
+          class: "small",
+          onclick: async () => {
+            const result = await run(() => api("POST", `/syncs/${encodeURIComponent(sync.name)}/kick`));
+            if (result) notify(`Sync ${sync.name} ${result.outcome}`);
+          },
+        }, "Kick"),
+        el("button", {
+          class: "small",
+          onclick: async () => {
+            await run(() => api("POST", `/syncs/${encodeURIComponent(sync.name)}/${paused ? "resume" : "pause"}`));
+            await loadOverview();
+          },
+        }, paused ? "Resume" : "Pause"),
+        el("button", { class: "small", onclick: () => openSyncDialog(sync.name) }, "Edit"),
+        el("button", { class: "small danger", onclick: () => deleteSync(sync.name) }, "Delete")));
+  }));
+}
+
+function renderDatabases() {
+  const pending = {};
+  for (const backlog of state.deltas) {
+    pending[backlog.database] = backlog.error
+      ? "error"
+      : (backlog.tables || []).reduce((sum, t) => sum + t.pending_rows, 0);
+  }
+  const body = document.querySelector("#databases tbody");
+  body.replaceChildren(...state.config.databases.map((db) => el("tr", {},
+    el("td", {}, dbLabel(db)),
+    el("td", {}, db.dbname),
+    el("td", {}, db.host),
+    el("td", {}, db.port || 5432),
+    el("td", {}, db.user),
+    el("td", {}, pending[dbKey(db)] === undefined ? "-" : pending[dbKey(db)]))));
+}
+
+function renderJobs() {
+  const body = document.querySelector("#jobs tbody");
+  body.replaceChildren(...state.jobs.slice(0, 10).map((job) => {
+    const current = (job.steps || []).filter((s) => s.state === "running").map((s) => s.name).join(", ");
+    const kind = { succeeded: "ok", failed: "error", cancelled: "warn" }[job.state];
+    return el("tr", {},
+      el("td", {}, job.id),
+      el("td", {}, job.type),
+      el("td", {}, job.target || "-"),
+      el("td", {}, badge(job.state, kind)),
+      el("td", {}, formatTime(job.created_at)),
+      el("td", {}, current),
+      el("td", { class: "error" }, job.error || ""));
+  }));
+}
+
+// Jobs are cheap to read, so they are polled while one is queued or running.
+function scheduleJobPolling() {
+  clearTimeout(scheduleJobPolling.timer);
+  if (!state.jobs.some((j) => j.state === "queued" || j.state === "running")) return;
+  scheduleJobPolling.timer = setTimeout(async () => {
+    try {
+      state.jobs = (await api("GET", "/jobs")).body || [];
+    } catch (err) {
+      return;
+    }
+    renderJobs();
+    if (!state.jobs.some((j) => j.state === "queued" || j.state === "running")) {
+      await loadOverview();
+      refreshStatuses();
+      return;
+    }
+    scheduleJobPolling();
+  }, 2000);
+}
+
+// ---------------------------------------------------------------------------------------
+// Topology
+
+function renderTopology() {
+  const graph = document.getElementById("topology-graph");
+  graph.replaceChildren();
+  const dbs = state.config.databases;
+  if (!dbs.length) {
+    graph.append(svg("text", { x: 400, y: 280, class: "label" }, "No databases configured"));
+    return;
+  }
+
+  graph.append(svg("defs", {}));
+  graph.firstChild.append(arrowMarker("arrow-end", "auto"), arrowMarker("arrow-start", "auto-start-reverse"));
+
+  const cx = 400, cy = 280, radius = dbs.length === 1 ? 0 : 210;
+  const positions = {};
+  dbs.forEach((db, i) => {
+    const angle = (2 * Math.PI * i) / dbs.length - Math.PI / 2;
+    positions[dbKey(db)] = { x: cx + radius * Math.cos(angle), y: cy + radius * Math.sin(angle) };
+  });
+
+  // Several syncs between the same pair of databases are spread apart by bending them.
+  const pairCount = {};
+  const edge = (fromRef, toRef, sync, bidirectional) => {
+    const fromDb = findDb(fromRef), toDb = findDb(toRef);
+    if (!fromDb || !toDb || fromDb === toDb) return;
+    const from = dbKey(fromDb), to = dbKey(toDb);
+    const a = positions[from], b = positions[to];
+    const key = [from, to].sort().join("|");
+    const n = (pairCount[key] = (pairCount[key] || 0) + 1) - 1;
+    const bend = (n % 2 ? -1 : 1) * Math.ceil(n / 2) * 40;
+    const mx = (a.x + b.x) / 2, my = (a.y + b.y) / 2;
+    const len = Math.hypot(b.x - a.x, b.y - a.y) || 1;
+    const qx = mx + (bend * (a.y - b.y)) / len, qy = my + (bend * (b.x - a.x)) / len;
+
+    // Stop the line at the node's edge so the arrow head stays visible.
+    const shorten = (p, q) => {
+      const d = Math.hypot(q.x - p.x, q.y - p.y) || 1;
+      return { x: p.x + ((q.x - p.x) * 36) / d, y: p.y + ((q.y - p.y) * 36) / d };
+    };
+    const start = shorten(a, { x: qx, y: qy }), end = shorten(b, { x: qx, y: qy });
+
+    const status = state.statuses[sync.name];
+    const classes = ["edge"];
+    if (sync.status === "inactive") classes.push("paused");
+    if (status && (status.error || /bad|fail/i.test(status.state || ""))) classes.push("failed");
+    const path = svg("path", {
+      d: `M${start.x},${start.y} Q${qx},${qy} ${end.x},${end.y}`,
+      class: classes.join(" "),
+      "marker-end": "url(#arrow-end)",
+    });
+    if (bidirectional) path.setAttribute("marker-start", "url(#arrow-start)");
+    path.append(svg("title", {}, `${sync.name}${status && status.state ? ": " + status.state : ""}`));
+    graph.append(path, svg("text", { x: (mx + qx) / 2, y: (my + qy) / 2 - 4, class: "label" }, sync.name));
+  };
+
+  for (const sync of state.config.syncs) {
+    for (const source of sync.sources || []) {
+      for (const target of sync.targets || []) edge(source, target, sync, false);
+    }
+    const group = sync.bidirectional || [];
+    for (let i = 0; i < group.length; i++) {
+      for (let j = i + 1; j < group.length; j++) edge(group[i], group[j], sync, true);
+    }
+  }
+
+  for (const db of dbs) {
+    const p = positions[dbKey(db)];
+    const node = svg("g", { class: "node", transform: `translate(${p.x},${p.y})` });
+    node.append(
+      svg("circle", { r: 34 }),
+      svg("text", { y: -2 }, db.dbname),
+      svg("text", { y: 14, class: "label" }, `${dbLabel(db)} ${db.host}`));
+    graph.append(node);
+  }
+}
+
+function arrowMarker(id, orient) {
+  const marker = svg("marker", { id, viewBox: "0 0 10 10", refX: 9, refY: 5, markerWidth: 7, markerHeight: 7, orient });
+  marker.append(svg("path", { d: "M0,0 L10,5 L0,10 z", fill: "#475569" }));
+  return marker;
+}
+
+// ---------------------------------------------------------------------------------------
+// Sync dialog
+
+const dialog = document.getElementById("sync-dialog");
+const form = document.getElementById("sync-form");
+
+async function openSyncDialog(name) {
+  form.reset();
+  document.getElementById("sync-form-error").hidden = true;
+  let sync = { name: "", onetimecopy: 0, status: "active" };
+  state.editing = null;
+  if (name) {
+    try {
+      const { body, headers } = await api("GET", `/syncs/${encodeURIComponent(name)}`);
+      sync = body;
+      state.editing = { name, etag: headers.get("ETag") };
+    } catch (err) {
+      notify(err.message, true);
+      return;
+    }
+  }
+
+  document.getElementById("sync-form-title").textContent = name ? `Edit sync ${name}` : "New sync";
+  for (const field of ["name", "tables", "herd", "conflict_strategy"]) form.elements[field].value = sync[field] || "";
+  form.elements.onetimecopy.value = String(sync.onetimecopy || 0);
+  form.elements.status.value = sync.status || "active";
+  form.elements.strict_checking.value = sync.strict_checking === undefined || sync.strict_checking === null ? "" : String(sync.strict_checking);
+  for (const container of form.querySelectorAll("[data-dbs]")) {
+    const role = container.dataset.dbs;
+    const selected = new Set((sync[role] || []).map(findDb));
+    container.replaceChildren(...state.config.databases.map((db) => el("label", {},
+      el("input", Object.assign({ type: "checkbox", value: dbKey(db) }, selected.has(db) ? { checked: "" } : {})),
+      `${db.dbname} (${dbLabel(db)})`)));
+  }
+  dialog.showModal();
+}
+
+function formSync() {
+  const f = form.elements;
+  // Named databases are referenced by name, the others by ID.
+  const refs = (role) => [...form.querySelectorAll(`[data-dbs="${role}"] input:checked`)].map((i) => {
+    const db = state.config.databases.find((d) => dbKey(d) === i.value);
+    return db.name || db.id || 0;
+  });
+  const sync = { name: f.name.value.trim(), onetimecopy: Number(f.onetimecopy.value), status: f.status.value };
+  for (const role of ["sources", "targets", "bidirectional"]) {
+    const list = refs(role);
+    if (list.length) sync[role] = list;
+  }
+  for (const field of ["tables", "herd", "conflict_strategy"]) {
+    if (f[field].value.trim()) sync[field] = f[field].value.trim();
+  }
+  if (f.strict_checking.value) sync.strict_checking = f.strict_checking.value === "true";
+  return sync;
+}
+
+form.addEventListener("submit", async (event) => {
+  event.preventDefault();
+  const error = document.getElementById("sync-form-error");
+  const message = form.elements.message.value.trim();
+  try {
+    const sync = formSync();
+    let result;
+    if (state.editing) {
+      result = await api("PUT", `/syncs/${encodeURIComponent(state.editing.name)}`, { body: sync, ifMatch: state.editing.etag, message });
+    } else {
+      result = await api("POST", "/syncs", { body: sync, message });
+    }
+    dialog.close();
+    notify(result.body.message + ". Use \"Apply & restart\" to apply it.");
+    await loadOverview();
+  } catch (err) {
+    error.textContent = err.status === 412
+      ? "Someone else changed the configuration while you were editing. Close this dialog and edit the sync again."
+      : err.message;
+    error.hidden = false;
+  }
+});
+
+document.getElementById("sync-cancel").addEventListener("click", () => dialog.close());
+
+async function deleteSync(name) {
+  if (!confirm(`Remove sync ${name} from the configuration?`)) return;
+  if (await run(() => api("DELETE", `/syncs/${encodeURIComponent(name)}`))) {
+    delete state.statuses[name];
+    await loadOverview();
+  }
+}
+
+// ---------------------------------------------------------------------------------------
+// Events
+
+function connectEvents() {
+  // The level filter keeps routine log lines out; replication events always pass.
+  const source = new EventSource(streamURL("/events", { tail: 0, level: "ERROR" }));
+  const list = document.getElementById("events");
+  let reload = null;
+  for (const type of STATE_EVENTS) {
+    source.addEventListener(type, (message) => {
+      let event;
+      try {
+        event = JSON.parse(message.data);
+      } catch (err) {
+        return;
+      }
+      const kind = { error: "error", warning: "warn" }[event.severity];
+      list.prepend(el("li", {}, el("time", {}, formatTime(event.time)), badge(event.type, kind), " ", event.message));
+      while (list.children.length > 100) list.lastChild.remove();
+
+      // Bursts of events, e.g. during a reconcile, cause a single reload.
+      clearTimeout(reload);
+      reload = setTimeout(async () => {
+        await loadOverview();
+        refreshStatuses(event.sync_name ? [event.sync_name] : undefined);
+      }, 1000);
+    });
+  }
+}
+
+// ---------------------------------------------------------------------------------------
+// Logs
+
+const logs = { socket: null, paused: false, buffered: [] };
+const MAX_LOG_LINES = 2000;
+
+function connectLogs() {
+  if (logs.socket) logs.socket.close();
+  const params = Object.fromEntries(new FormData(document.getElementById("log-filter")));
+  const socket = new WebSocket(streamURL("/logs", params, true));
+  const status = document.getElementById("log-state");
+  logs.socket = socket;
+  socket.onopen = () => { status.textContent = "connected"; };
+  socket.onclose = (event) => {
+    if (logs.socket !== socket) return;
+    status.textContent = `disconnected${event.reason ? ": " + event.reason : ""}, reconnecting...`;
+    setTimeout(() => { if (logs.socket === socket) connectLogs(); }, 3000);
+  };
+  socket.onmessage = (message) => {
+    if (logs.paused) {
+      logs.buffered.push(message.data);
+      return;
+    }
+    appendLog(message.data);
+  };
+}
+
+function appendLog(raw) {
+  const container = document.getElementById("log-lines");
+  const atBottom = container.scrollHeight - container.scrollTop - container.clientHeight < 20;
+  let line;
+  try {
+    const entry = JSON.parse(raw);
+    const { time, level, msg, component, ...attrs } = entry;
+    const extra = Object.entries(attrs).map(([k, v]) => `${k}=${typeof v === "string" ? v : JSON.stringify(v)}`).join(" ");
+    line = el("div", { class: level || "" },
+      el("time", {}, formatTime(time)),
+      `${(level || "").padEnd(5)} `,
+      component ? `[${component}] ` : "",
+      msg,
+      extra ? el("span", { class: "attrs" }, " " + extra) : null);
+  } catch (err) {
+    line = el("div", {}, raw);
+  }
+  container.append(line);
+  while (container.children.length > MAX_LOG_LINES) container.firstChild.remove();
+  if (atBottom) container.scrollTop = container.scrollHeight;
+}
+
+document.getElementById("log-filter").addEventListener("submit", (event) => {
+  event.preventDefault();
+  document.getElementById("log-lines").replaceChildren();
+  connectLogs();
+});
+
+document.getElementById("log-pause").addEventListener("click", (event) => {
+  logs.paused = !logs.paused;
+  event.target.textContent = logs.paused ? "Resume" : "Pause";
+  if (!logs.paused) {
+    logs.buffered.splice(0).forEach(appendLog);
+  }
+});
+
+document.getElementById("log-clear").addEventListener("click", () => {
+  document.getElementById("log-lines").replaceChildren();
+});
+
+// ---------------------------------------------------------------------------------------
+// Navigation and lifecycle
+
+for (const button of document.querySelectorAll("nav button")) {
+  button.addEventListener("click", () => {
+    for (const other of document.querySelectorAll("nav button")) other.classList.toggle("active", other === button);
+    for (const view of document.querySelectorAll(".view")) view.hidden = view.id !== button.dataset.view;
+    if (button.dataset.view === "logs" && !logs.socket) connectLogs();
+  });
+}
+
+document.getElementById("start").addEventListener("click", () => run(() => api("POST", "/start")));
+document.getElementById("stop").addEventListener("click", () => {
+  if (confirm("Stop Bucardo? Replication pauses until it is started again.")) run(() => api("POST", "/stop"));
+});
+document.getElementById("restart").addEventListener("click", async () => {
+  const job = await run(() => api("POST", "/restart"));
+  if (job) {
+    notify(`Reconcile job ${job.id} started`);
+    await loadOverview();
+  }
+});
+document.getElementById("token").addEventListener("click", () => {
+  if (askToken()) location.reload();
+});
+document.getElementById("new-sync").addEventListener("click", () => openSyncDialog(null));
+document.getElementById("refresh-status").addEventListener("click", () => refreshStatuses());
+
+(async function init() {
+  await loadOverview();
+  refreshStatuses();
+  connectEvents();
+})();
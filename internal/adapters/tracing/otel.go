@@ -0,0 +1,125 @@
+// Package tracing exports trace spans with OpenTelemetry. Core code records spans through
+// ports.Tracer; adapters use the OpenTelemetry API directly, and Setup sends both to an
+// OTLP collector.
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+
+	"replication-service/internal/core/ports"
+)
+
+// InstrumentationName names the tracer of this service's spans.
+const InstrumentationName = "replication-service"
+
+// Setup installs the global tracer provider when an OTLP endpoint is configured with the
+// standard OTEL_EXPORTER_OTLP_ENDPOINT or OTEL_EXPORTER_OTLP_TRACES_ENDPOINT environment
+// variables; the other OTEL_EXPORTER_OTLP_* variables (headers, timeout, ...) apply too.
+// Spans are sent over OTLP/HTTP. Without an endpoint, spans are not recorded and shutdown
+// does nothing. The W3C trace context of incoming requests is honored either way.
+func Setup(ctx context.Context, serviceName string) (shutdown func(context.Context) error, enabled bool, err error) {
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	shutdown = func(context.Context) error { return nil }
+	if os.Getenv("OTEL_EXPORTER_OTLP_ENDPOINT") == "" && os.Getenv("OTEL_EXPORTER_OTLP_TRACES_ENDPOINT") == "" {
+		return shutdown, false, nil
+	}
+
+	exporter, err := otlptracehttp.New(ctx)
+	if err != nil {
+		return shutdown, false, fmt.Errorf("failed to create OTLP exporter: %w", err)
+	}
+	// OTEL_SERVICE_NAME and OTEL_RESOURCE_ATTRIBUTES override the defaults.
+	res, err := resource.New(ctx,
+		resource.WithAttributes(attribute.String("service.name", serviceName)),
+		resource.WithFromEnv(),
+		resource.WithTelemetrySDK(),
+		resource.WithHost(),
+	)
+	if err != nil {
+		return shutdown, false, fmt.Errorf("failed to describe the service resource: %w", err)
+	}
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+	)
+	otel.SetTracerProvider(provider)
+	return provider.Shutdown, true, nil
+}
+
+// Tracer returns the OpenTelemetry tracer for adapter spans.
+func Tracer() trace.Tracer {
+	return otel.Tracer(InstrumentationName)
+}
+
+// OTelTracer implements ports.Tracer with the global OpenTelemetry tracer provider.
+type OTelTracer struct {
+	tracer trace.Tracer
+}
+
+// NewOTelTracer creates a tracer. It follows the provider installed by Setup, even when
+// Setup runs later.
+func NewOTelTracer() *OTelTracer {
+	return &OTelTracer{tracer: Tracer()}
+}
+
+func (t *OTelTracer) Start(ctx context.Context, name string, attrs ...any) (context.Context, ports.Span) {
+	ctx, span := t.tracer.Start(ctx, name, trace.WithAttributes(Attributes(attrs...)...))
+	return ctx, &otelSpan{span: span}
+}
+
+type otelSpan struct {
+	span trace.Span
+}
+
+func (s *otelSpan) SetAttributes(attrs ...any) {
+	s.span.SetAttributes(Attributes(attrs...)...)
+}
+
+func (s *otelSpan) End(err error) {
+	EndSpan(s.span, err)
+}
+
+// EndSpan ends span, recording err and an error status when err is not nil.
+func EndSpan(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+// Attributes converts key-value pairs, as passed to ports.Logger, to span attributes.
+func Attributes(kv ...any) []attribute.KeyValue {
+	attrs := make([]attribute.KeyValue, 0, len(kv)/2)
+	for i := 0; i+1 < len(kv); i += 2 {
+		key := fmt.Sprint(kv[i])
+		switch v := kv[i+1].(type) {
+		case string:
+			attrs = append(attrs, attribute.String(key, v))
+		case int:
+			attrs = append(attrs, attribute.Int(key, v))
+		case int64:
+			attrs = append(attrs, attribute.Int64(key, v))
+		case bool:
+			attrs = append(attrs, attribute.Bool(key, v))
+		case float64:
+			attrs = append(attrs, attribute.Float64(key, v))
+		case []string:
+			attrs = append(attrs, attribute.StringSlice(key, v))
+		default:
+			attrs = append(attrs, attribute.String(key, fmt.Sprint(v)))
+		}
+	}
+	return attrs
+}
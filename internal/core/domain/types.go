@@ -1,15 +1,45 @@
 package domain
 
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
 // BucardoConfig represents the top-level structure of the bucardo.json file.
 type BucardoConfig struct {
 	Databases []Database `json:"databases"`
 	Syncs     []Sync     `json:"syncs"`
 	LogLevel  string     `json:"log_level,omitempty"`
+
+	DeltaMonitor  *DeltaMonitorConfig   `json:"delta_monitor,omitempty"`
+	Notifications []NotificationChannel `json:"notifications,omitempty"`
+	ServiceLog    *ServiceLogConfig     `json:"service_log,omitempty"`
+}
+
+// ServiceLogConfig sets the level of the service's own logs; LogLevel is Bucardo's. A
+// component level overrides Level for the lines of that component.
+type ServiceLogConfig struct {
+	Level      string            `json:"level,omitempty"`      // "debug", "info", "warn" or "error".
+	Components map[string]string `json:"components,omitempty"` // Component name to level, e.g. "db_reconciler": "debug".
+}
+
+// ServiceLogLevels are the accepted levels of ServiceLogConfig.
+var ServiceLogLevels = []string{"debug", "info", "warn", "error"}
+
+// DeltaMonitorConfig controls the periodic measurement of Bucardo's delta backlog on source databases.
+type DeltaMonitorConfig struct {
+	IntervalSeconds int   `json:"interval_seconds,omitempty"` // How often to collect. Defaults to 300; a negative value disables collection.
+	WarnRows        int64 `json:"warn_rows,omitempty"`        // Warn when a table has more pending delta rows than this.
+	WarnAgeSeconds  int   `json:"warn_age_seconds,omitempty"` // Warn when a table's oldest pending change is older than this.
 }
 
 // Database defines a PostgreSQL database connection for Bucardo.
 type Database struct {
-	ID     int    `json:"id"`
+	ID     int    `json:"id,omitempty"`   // Numeric ID, from before databases had names. Bucardo knows an unnamed database as "db<ID>".
+	Name   string `json:"name,omitempty"` // Stable name used in syncs, and as the database's name in Bucardo.
 	DBName string `json:"dbname"`
 	Host   string `json:"host"`
 	User   string `json:"user"`
@@ -17,17 +47,480 @@ type Database struct {
 	Port   *int   `json:"port,omitempty"`
 }
 
+// BucardoName returns the name of the database in Bucardo: its name, or "db<ID>" when it
+// has none.
+func (d Database) BucardoName() string {
+	if d.Name != "" {
+		return d.Name
+	}
+	return LegacyBucardoName(d.ID)
+}
+
+// LegacyBucardoName returns the Bucardo name of the database with the given ID before it
+// was given a name.
+func LegacyBucardoName(id int) string {
+	return fmt.Sprintf("db%d", id)
+}
+
+// Ref returns the reference a sync would use for the database: its name, or its ID.
+func (d Database) Ref() DBRef {
+	if d.Name != "" {
+		return DBRef(d.Name)
+	}
+	return DBRefID(d.ID)
+}
+
+// PasswordEnvVars returns the environment variables that hold the password of the database
+// when its pass is "env", by precedence: BUCARDO_DB_<NAME>_PASS for a named database, then
+// BUCARDO_DB<ID> for a database with an ID.
+func (d Database) PasswordEnvVars() []string {
+	var vars []string
+	if d.Name != "" {
+		vars = append(vars, "BUCARDO_DB_"+strings.ToUpper(d.Name)+"_PASS")
+	}
+	if d.Name == "" || d.ID != 0 {
+		vars = append(vars, fmt.Sprintf("BUCARDO_DB%d", d.ID))
+	}
+	return vars
+}
+
+// DBRef references a database from a sync: by its name, or by its numeric ID in
+// configurations written before databases had names. In JSON it is a string, or a number
+// for an ID.
+type DBRef string
+
+// DBRefID returns a reference to the database with the given ID.
+func DBRefID(id int) DBRef {
+	return DBRef(strconv.Itoa(id))
+}
+
+// ID returns the database ID held by a numeric reference.
+func (r DBRef) ID() (int, bool) {
+	id, err := strconv.Atoi(string(r))
+	return id, err == nil
+}
+
+func (r DBRef) MarshalJSON() ([]byte, error) {
+	if id, ok := r.ID(); ok {
+		return []byte(strconv.Itoa(id)), nil
+	}
+	return json.Marshal(string(r))
+}
+
+func (r *DBRef) UnmarshalJSON(data []byte) error {
+	var id int
+	if err := json.Unmarshal(data, &id); err == nil {
+		*r = DBRefID(id)
+		return nil
+	}
+	var name string
+	if err := json.Unmarshal(data, &name); err != nil {
+		return fmt.Errorf("a database reference must be a name or a numeric ID, got %s", data)
+	}
+	*r = DBRef(name)
+	return nil
+}
+
+// FindDatabase returns the database a reference points to. A numeric reference matches
+// the database with that ID, a name matches the database with that name.
+func (c *BucardoConfig) FindDatabase(ref DBRef) (Database, bool) {
+	id, numeric := ref.ID()
+	for _, db := range c.Databases {
+		if numeric {
+			// A named database without an ID cannot be referenced as 0.
+			if db.ID == id && (id != 0 || db.Name == "") {
+				return db, true
+			}
+		} else if ref != "" && db.Name == string(ref) {
+			return db, true
+		}
+	}
+	return Database{}, false
+}
+
 // Sync defines a Bucardo synchronization task, detailing what to replicate from where to where.
 type Sync struct {
-	Name                  string `json:"name"`
-	Sources               []int  `json:"sources,omitempty"`                  // A list of database IDs to use as sources.
-	Targets               []int  `json:"targets,omitempty"`                  // A list of database IDs to use as targets.
-	Bidirectional         []int  `json:"bidirectional,omitempty"`            // A list of database IDs for bidirectional (dbgroup) replication.
-	Herd                  string `json:"herd,omitempty"`                     // The name of a herd (group) to sync all tables from the first source.
-	Tables                string `json:"tables,omitempty"`                   // A comma-separated list of specific tables to sync.
-	Onetimecopy           int    `json:"onetimecopy"`                        // Controls full-copy behavior (0=off, 1=always, 2=if target empty).
-	StrictChecking        *bool  `json:"strict_checking,omitempty"`          // If false, allows schema differences like column order.
-	ExitOnComplete        *bool  `json:"exit_on_complete,omitempty"`         // If true, the container will exit after this sync completes.
-	ExitOnCompleteTimeout *int   `json:"exit_on_complete_timeout,omitempty"` // Timeout in seconds for run-once syncs.
-	ConflictStrategy      string `json:"conflict_strategy,omitempty"`        // Defines how to resolve data conflicts (e.g., "bucardo_source").
+	Name                  string  `json:"name"`
+	Sources               []DBRef `json:"sources,omitempty"`                  // The databases to use as sources, by name or ID.
+	Targets               []DBRef `json:"targets,omitempty"`                  // The databases to use as targets, by name or ID.
+	Bidirectional         []DBRef `json:"bidirectional,omitempty"`            // The databases for bidirectional (dbgroup) replication, by name or ID.
+	Herd                  string  `json:"herd,omitempty"`                     // The name of a herd (group) to sync all tables from the first source.
+	Tables                string  `json:"tables,omitempty"`                   // A comma-separated list of specific tables to sync.
+	Onetimecopy           int     `json:"onetimecopy"`                        // Controls full-copy behavior (0=off, 1=always, 2=if target empty).
+	StrictChecking        *bool   `json:"strict_checking,omitempty"`          // If false, allows schema differences like column order.
+	ExitOnComplete        *bool   `json:"exit_on_complete,omitempty"`         // If true, the container will exit after this sync completes.
+	ExitOnCompleteTimeout *int    `json:"exit_on_complete_timeout,omitempty"` // Timeout in seconds for run-once syncs.
+	ConflictStrategy      string  `json:"conflict_strategy,omitempty"`        // Defines how to resolve data conflicts (e.g., "bucardo_source").
+	Status                string  `json:"status,omitempty"`                   // "active" (default) or "inactive" for a sync paused by an operator.
+}
+
+// Values accepted in Sync.Status. They map directly onto Bucardo's sync status.
+const (
+	SyncStatusActive   = "active"
+	SyncStatusInactive = "inactive"
+)
+
+// IsPaused reports whether the sync has been deactivated by an operator.
+func (s Sync) IsPaused() bool {
+	return s.Status == SyncStatusInactive
+}
+
+// SyncRunResult describes the outcome of a manually kicked sync run.
+type SyncRunResult struct {
+	SyncName     string `json:"sync_name"`
+	Outcome      string `json:"outcome"`                 // One of "kicked", "done", "failed", "timeout" or "unknown".
+	DurationMs   int64  `json:"duration_ms"`             // Time spent in the kick command, including any wait.
+	State        string `json:"state,omitempty"`         // The sync's current state as reported by 'bucardo status'.
+	RowsDeleted  *int   `json:"rows_deleted,omitempty"`  // Rows deleted on targets by the last run.
+	RowsInserted *int   `json:"rows_inserted,omitempty"` // Rows inserted on targets by the last run.
+	Output       string `json:"output,omitempty"`        // Raw output of the kick command.
+}
+
+// Outcomes reported in SyncRunResult.Outcome.
+const (
+	SyncRunKicked  = "kicked"
+	SyncRunDone    = "done"
+	SyncRunFailed  = "failed"
+	SyncRunTimeout = "timeout"
+	SyncRunUnknown = "unknown" // The kick output did not say how the run ended.
+)
+
+// RunOnceSummary reports what happened to the run-once syncs of a container run.
+type RunOnceSummary struct {
+	Outcome    string              `json:"outcome"` // One of the RunOnceOutcome* values.
+	StartedAt  time.Time           `json:"started_at"`
+	FinishedAt time.Time           `json:"finished_at"`
+	DurationMs int64               `json:"duration_ms"`
+	Syncs      []RunOnceSyncResult `json:"syncs"`
+}
+
+// RunOnceSyncResult is the outcome of a single run-once sync.
+type RunOnceSyncResult struct {
+	SyncName       string `json:"sync_name"`
+	State          string `json:"state"`                     // One of the RunOnce* values.
+	TimeoutSeconds int    `json:"timeout_seconds,omitempty"` // The sync's own deadline, if any.
+	DurationMs     int64  `json:"duration_ms"`               // Time until the sync completed, timed out or was cancelled.
+	RowsDeleted    *int   `json:"rows_deleted,omitempty"`    // Rows deleted on targets, as reported by 'bucardo status'.
+	RowsInserted   *int   `json:"rows_inserted,omitempty"`   // Rows inserted on targets, as reported by 'bucardo status'.
+}
+
+// States reported in RunOnceSyncResult.State.
+const (
+	RunOnceCompleted = "completed"
+	RunOnceTimedOut  = "timed_out"
+	RunOnceCancelled = "cancelled"
+)
+
+// Outcomes reported in RunOnceSummary.Outcome.
+const (
+	RunOnceOutcomeSuccess   = "success"
+	RunOnceOutcomePartial   = "partial"
+	RunOnceOutcomeTimeout   = "timeout"
+	RunOnceOutcomeCancelled = "cancelled" // The container was stopped before every sync finished.
+)
+
+// RecopyRequest asks for a full copy of a sync, or of a subset of its tables, on the next run.
+type RecopyRequest struct {
+	Tables  []string `json:"tables,omitempty"`  // Tables to copy. Empty means every table in the sync.
+	Timeout int      `json:"timeout,omitempty"` // Seconds to wait for the copy run to finish. Defaults to one hour.
+}
+
+// RecopyStatus tracks the progress of an on-demand re-copy.
+type RecopyStatus struct {
+	SyncName   string         `json:"sync_name"`
+	JobID      string         `json:"job_id,omitempty"` // The job running the copy; see GET /jobs/{id}.
+	Tables     []string       `json:"tables,omitempty"`
+	State      string         `json:"state"`          // One of the RecopyState* values.
+	Step       string         `json:"step,omitempty"` // Human readable description of the current step.
+	StartedAt  time.Time      `json:"started_at"`
+	FinishedAt *time.Time     `json:"finished_at,omitempty"`
+	Result     *SyncRunResult `json:"result,omitempty"` // Outcome of the copy run once it has finished.
+	Error      string         `json:"error,omitempty"`
+}
+
+// States reported in RecopyStatus.State.
+const (
+	RecopyStatePending   = "pending"
+	RecopyStateCopying   = "copying"
+	RecopyStateResetting = "resetting"
+	RecopyStateCompleted = "completed"
+	RecopyStateFailed    = "failed"
+)
+
+// ConnInfo holds everything needed to open a connection to a configured database,
+// with the password already resolved.
+type ConnInfo struct {
+	Host     string
+	Port     int
+	DBName   string
+	User     string
+	Password string
+}
+
+// TableInfo describes a table as seen in one particular database.
+type TableInfo struct {
+	Name          string   // The table name as written in the sync configuration.
+	QualifiedName string   // The schema-qualified, quoted name resolved by the database.
+	PrimaryKey    []string // Primary key columns in index order.
+	Columns       []string // Columns compared by checksums, sorted by name.
+}
+
+// KeyRange is a half-open primary key range [Lower, Upper). A nil bound is unbounded.
+// Bounds hold the text representation of each primary key column.
+type KeyRange struct {
+	Lower []string `json:"lower,omitempty"`
+	Upper []string `json:"upper,omitempty"`
+}
+
+// VerifyRequest configures a data validation run.
+type VerifyRequest struct {
+	Parallelism int `json:"parallelism,omitempty"` // Number of tables verified concurrently. Defaults to 4.
+	ChunkSize   int `json:"chunk_size,omitempty"`  // Rows per checksummed key range. Defaults to 10000.
+}
+
+// VerifyRun reports the progress and outcome of a data validation run for a sync.
+type VerifyRun struct {
+	ID          string              `json:"id"`
+	JobID       string              `json:"job_id,omitempty"` // The job running the comparison; see GET /jobs/{id}.
+	SyncName    string              `json:"sync_name"`
+	State       string              `json:"state"` // One of the VerifyState* values.
+	Source      string              `json:"source"`
+	Targets     []string            `json:"targets"`
+	Parallelism int                 `json:"parallelism"`
+	ChunkSize   int                 `json:"chunk_size"`
+	StartedAt   time.Time           `json:"started_at"`
+	FinishedAt  *time.Time          `json:"finished_at,omitempty"`
+	Tables      []TableVerification `json:"tables"`
+	Mismatches  int                 `json:"mismatches"` // Total number of mismatching key ranges and row counts.
+	Error       string              `json:"error,omitempty"`
+}
+
+// TableVerification is the result of comparing one table between the source and one target.
+type TableVerification struct {
+	Table         string             `json:"table"`
+	Target        string             `json:"target"`
+	SourceRows    int64              `json:"source_rows"`
+	TargetRows    int64              `json:"target_rows"`
+	RowCountMatch bool               `json:"row_count_match"`
+	ChunksChecked int                `json:"chunks_checked"`
+	Mismatches    []KeyRangeMismatch `json:"mismatches,omitempty"`
+	Error         string             `json:"error,omitempty"`
+}
+
+// KeyRangeMismatch describes a primary key range whose contents differ between source and target.
+type KeyRangeMismatch struct {
+	Range      KeyRange `json:"range"`
+	SourceRows int64    `json:"source_rows"`
+	TargetRows int64    `json:"target_rows"`
+}
+
+// States reported in VerifyRun.State.
+const (
+	VerifyStateRunning   = "running"
+	VerifyStateCompleted = "completed"
+	VerifyStateFailed    = "failed"
+)
+
+// DeltaTableStats describes the Bucardo change-tracking backlog of one table on a source database.
+type DeltaTableStats struct {
+	Table                   string  `json:"table"`
+	DeltaTable              string  `json:"delta_table"`
+	Targets                 int     `json:"targets"`                    // Number of targets Bucardo tracks for the table.
+	DeltaRows               int64   `json:"delta_rows"`                 // All rows currently in the delta table.
+	PendingRows             int64   `json:"pending_rows"`               // Delta rows not yet replicated to every target.
+	TrackRows               int64   `json:"track_rows"`                 // Rows in the matching track table.
+	OldestPendingAgeSeconds float64 `json:"oldest_pending_age_seconds"` // Age of the oldest change not yet replicated everywhere.
+}
+
+// DeltaBacklog is the latest delta measurement of one source database.
+type DeltaBacklog struct {
+	DatabaseID  int               `json:"database_id,omitempty"`
+	Database    string            `json:"database"`
+	CollectedAt time.Time         `json:"collected_at"`
+	Tables      []DeltaTableStats `json:"tables"`
+	Error       string            `json:"error,omitempty"`
+}
+
+// DeltaPurgeResult reports the rows purged, or that would be purged, for one table.
+type DeltaPurgeResult struct {
+	Table      string `json:"table"`
+	DeltaTable string `json:"delta_table"`
+	DeltaRows  int64  `json:"delta_rows"`
+	TrackRows  int64  `json:"track_rows"`
+}
+
+// DeltaPurgeReport summarises a purge of already-replicated delta rows on a source database.
+type DeltaPurgeReport struct {
+	DatabaseID    int                `json:"database_id,omitempty"`
+	Database      string             `json:"database"`
+	DryRun        bool               `json:"dry_run"`
+	MinAgeSeconds int                `json:"min_age_seconds"`
+	Tables        []DeltaPurgeResult `json:"tables"`
+}
+
+// Job is a long-running operation started through the API and run in the background.
+type Job struct {
+	ID         string     `json:"id"`
+	Type       string     `json:"type"`             // One of the JobType* values.
+	Target     string     `json:"target,omitempty"` // The sync the job works on, if any.
+	State      string     `json:"state"`            // One of the JobState* values.
+	Coalesced  int        `json:"coalesced"`        // Later requests merged into this job while it was queued.
+	CreatedAt  time.Time  `json:"created_at"`
+	StartedAt  *time.Time `json:"started_at,omitempty"`
+	FinishedAt *time.Time `json:"finished_at,omitempty"`
+	Steps      []JobStep  `json:"steps"`
+	Result     any        `json:"result,omitempty"` // Operation specific result once the job has finished.
+	Error      string     `json:"error,omitempty"`
+}
+
+// JobStep is one step of a job.
+type JobStep struct {
+	Name       string     `json:"name"`
+	State      string     `json:"state"` // One of the JobState* values except queued.
+	StartedAt  time.Time  `json:"started_at"`
+	FinishedAt *time.Time `json:"finished_at,omitempty"`
+	Error      string     `json:"error,omitempty"`
+}
+
+// Job types reported in Job.Type.
+const (
+	JobTypeReconcile = "reconcile"
+	JobTypeRecopy    = "recopy"
+	JobTypeVerify    = "verify"
+)
+
+// States reported in Job.State and JobStep.State.
+const (
+	JobStateQueued    = "queued"
+	JobStateRunning   = "running"
+	JobStateSucceeded = "succeeded"
+	JobStateFailed    = "failed"
+	JobStateCancelled = "cancelled"
+)
+
+// Event is a replication or lifecycle event raised by the orchestrator or the monitor.
+type Event struct {
+	Type     string         `json:"type"`     // One of the Event* values.
+	Severity string         `json:"severity"` // One of the Severity* values.
+	Time     time.Time      `json:"time"`
+	SyncName string         `json:"sync_name,omitempty"`
+	Message  string         `json:"message"`
+	Details  map[string]any `json:"details,omitempty"`
+}
+
+// Event types.
+const (
+	EventReconcileStarted  = "reconcile_started"
+	EventReconcileFinished = "reconcile_finished"
+	EventReconcileFailed   = "reconcile_failed"
+	EventSyncStatusChanged = "sync_status_changed"
+	EventKidDied           = "kid_died"
+	EventRunOnceCompleted  = "run_once_completed"
+	EventRunOnceTimedOut   = "run_once_timed_out"
+)
+
+// Event severities, in increasing order.
+const (
+	SeverityInfo    = "info"
+	SeverityWarning = "warning"
+	SeverityError   = "error"
+)
+
+// NotificationChannel configures an outbound destination for events.
+type NotificationChannel struct {
+	Name         string   `json:"name"`                    // Unique channel name, used in logs and for "env" secrets.
+	Type         string   `json:"type"`                    // "webhook", "slack" or "email".
+	Events       []string `json:"events,omitempty"`        // Event types to deliver. Empty means all.
+	MinSeverity  string   `json:"min_severity,omitempty"`  // Lowest severity to deliver. Defaults to "info".
+	URL          string   `json:"url,omitempty"`           // Target URL for "webhook" and "slack" channels.
+	Secret       string   `json:"secret,omitempty"`        // HMAC key for "webhook" signatures, or "env".
+	SMTPHost     string   `json:"smtp_host,omitempty"`     // Mail server for "email" channels.
+	SMTPPort     int      `json:"smtp_port,omitempty"`     // Mail server port. Defaults to 25.
+	SMTPUser     string   `json:"smtp_user,omitempty"`     // Optional SMTP username.
+	SMTPPass     string   `json:"smtp_pass,omitempty"`     // Optional SMTP password, or "env".
+	From         string   `json:"from,omitempty"`          // Sender address for "email" channels.
+	To           []string `json:"to,omitempty"`            // Recipient addresses for "email" channels.
+	DedupSeconds int      `json:"dedup_seconds,omitempty"` // Identical events within this window are sent once. Defaults to 300.
+	MaxRetries   int      `json:"max_retries,omitempty"`   // Delivery attempts after the first failure. Defaults to 3.
+}
+
+// ConfigRevision describes one saved version of the configuration.
+type ConfigRevision struct {
+	Revision int       `json:"revision"`
+	Time     time.Time `json:"time"`
+	Actor    string    `json:"actor"`             // The X-Actor header or client address of the caller, or "system".
+	Message  string    `json:"message,omitempty"` // Why the change was made, if the caller said.
+	Checksum string    `json:"checksum"`          // SHA-256 of the saved file.
+}
+
+// ConfigRevisionDetail is a revision together with the configuration it saved.
+type ConfigRevisionDetail struct {
+	ConfigRevision
+	Config *BucardoConfig `json:"config"`
+}
+
+// ConfigChange is one difference between two revisions of the configuration.
+type ConfigChange struct {
+	Path string `json:"path"` // e.g. "syncs[name=orders].status" or "databases[id=2].host".
+	Op   string `json:"op"`   // One of the ConfigChange* values.
+	Old  any    `json:"old,omitempty"`
+	New  any    `json:"new,omitempty"`
+}
+
+// Operations reported in ConfigChange.Op.
+const (
+	ConfigChangeAdded    = "added"
+	ConfigChangeRemoved  = "removed"
+	ConfigChangeModified = "modified"
+)
+
+// ConfigDiff lists the changes between two revisions of the configuration.
+type ConfigDiff struct {
+	From    int            `json:"from"`
+	To      int            `json:"to"`
+	Changes []ConfigChange `json:"changes"`
+}
+
+// AuditRecord is one entry of the audit trail: an API request that changed something, or
+// a command run against Bucardo.
+type AuditRecord struct {
+	Time          time.Time      `json:"time"`
+	Kind          string         `json:"kind"`                     // One of the AuditKind* values.
+	CorrelationID string         `json:"correlation_id,omitempty"` // Shared by a request and the commands it caused.
+	Actor         string         `json:"actor"`
+	Method        string         `json:"method,omitempty"`   // API requests only.
+	Endpoint      string         `json:"endpoint,omitempty"` // API requests only, including the query string.
+	Status        int            `json:"status,omitempty"`   // HTTP status of API requests.
+	Changes       []ConfigChange `json:"changes,omitempty"`  // Configuration changes made by the request, secrets masked.
+	Command       string         `json:"command,omitempty"`  // Commands only, passwords masked.
+	ExitCode      *int           `json:"exit_code,omitempty"`
+	DurationMs    int64          `json:"duration_ms"`
+	Error         string         `json:"error,omitempty"`
+}
+
+// Kinds of audit records.
+const (
+	AuditKindAPI     = "api"
+	AuditKindCommand = "command"
+)
+
+// AuditQuery selects audit records. Zero values do not filter.
+type AuditQuery struct {
+	Since         time.Time
+	Until         time.Time
+	Actor         string
+	Kind          string
+	CorrelationID string
+	Limit         int // Maximum number of records, newest first.
+}
+
+// MessageResponse is the body of API responses that only confirm an action.
+type MessageResponse struct {
+	Message string `json:"message"`
+}
+
+// ErrorResponse is the body of every API error response.
+type ErrorResponse struct {
+	Error  string `json:"error"`
+	Status int    `json:"status"` // Repeats the HTTP status code.
 }
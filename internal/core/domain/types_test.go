@@ -0,0 +1,69 @@
+package domain
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestDBRefJSON(t *testing.T) {
+	var sync Sync
+	if err := json.Unmarshal([]byte(`{"name":"orders","sources":[1,"orders_primary"],"targets":["2"]}`), &sync); err != nil {
+		t.Fatal(err)
+	}
+	if want := []DBRef{"1", "orders_primary"}; !reflect.DeepEqual(sync.Sources, want) {
+		t.Errorf("sources = %q, want %q", sync.Sources, want)
+	}
+	if id, ok := sync.Targets[0].ID(); !ok || id != 2 {
+		t.Errorf("targets[0].ID() = %d, %t, want 2", id, ok)
+	}
+
+	// IDs are written back as numbers, so configurations keep their form.
+	data, err := json.Marshal(sync.Sources)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != `[1,"orders_primary"]` {
+		t.Errorf("Marshal() = %s", data)
+	}
+
+	if err := json.Unmarshal([]byte(`{"sources":[true]}`), &sync); err == nil {
+		t.Error("Unmarshal() accepted a boolean reference")
+	}
+}
+
+func TestFindDatabase(t *testing.T) {
+	config := &BucardoConfig{Databases: []Database{
+		{ID: 1, Name: "orders_primary"},
+		{ID: 2},
+		{Name: "reporting"},
+	}}
+	tests := []struct {
+		ref         DBRef
+		wantBucardo string
+	}{
+		{ref: "1", wantBucardo: "orders_primary"},
+		{ref: "orders_primary", wantBucardo: "orders_primary"},
+		{ref: "2", wantBucardo: "db2"},
+		{ref: "reporting", wantBucardo: "reporting"},
+		{ref: "0"}, // Not the named database without an ID.
+		{ref: "db2"},
+		{ref: ""},
+	}
+	for _, tt := range tests {
+		got := ""
+		if db, ok := config.FindDatabase(tt.ref); ok {
+			got = db.BucardoName()
+		}
+		if got != tt.wantBucardo {
+			t.Errorf("FindDatabase(%q) = %q, want %q", tt.ref, got, tt.wantBucardo)
+		}
+	}
+
+	if got, want := config.Databases[0].PasswordEnvVars(), []string{"BUCARDO_DB_ORDERS_PRIMARY_PASS", "BUCARDO_DB1"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("PasswordEnvVars() = %q, want %q", got, want)
+	}
+	if got, want := config.Databases[2].PasswordEnvVars(), []string{"BUCARDO_DB_REPORTING_PASS"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("PasswordEnvVars() = %q, want %q", got, want)
+	}
+}
@@ -2,6 +2,7 @@ package ports
 
 import (
 	"context"
+	"errors"
 
 	"replication-service/internal/core/domain"
 )
@@ -13,12 +14,64 @@ type Logger interface {
 	Error(msg string, args ...any)
 	Debug(msg string, args ...any)
 	With(args ...any) Logger
+	// WithContext returns a logger that adds the trace and span IDs of ctx to its lines.
+	WithContext(ctx context.Context) Logger
+}
+
+// Tracer records trace spans around units of work. Attributes are key-value pairs, as for
+// Logger.
+type Tracer interface {
+	Start(ctx context.Context, name string, attrs ...any) (context.Context, Span)
+}
+
+// Span is a unit of work started by a Tracer.
+type Span interface {
+	SetAttributes(attrs ...any)
+	// End finishes the span, marking it failed when err is not nil.
+	End(err error)
+}
+
+var (
+	// ErrRevisionNotFound is returned by ConfigProvider.GetRevision for an unknown revision.
+	ErrRevisionNotFound = errors.New("config revision not found")
+	// ErrRevisionConflict is returned by ConfigProvider.SaveConfig when the context expects
+	// a revision (see reqctx.WithExpectedRevision) that is no longer the latest.
+	ErrRevisionConflict = errors.New("configuration was changed by someone else")
+)
+
+// SecretRegistry collects secret values, such as resolved database passwords, that must
+// never appear in logs.
+type SecretRegistry interface {
+	AddSecret(secrets ...string)
+}
+
+// LogLevels changes the level of the service's own logs while it runs.
+type LogLevels interface {
+	// Levels returns the global level and the component overrides.
+	Levels() domain.ServiceLogConfig
+	// SetLevel sets the level of a component, or the global level when component is empty.
+	// An empty level removes the override of a component.
+	SetLevel(component, level string) error
 }
 
 // ConfigProvider defines the interface for loading the application configuration.
+// Every save is recorded as a numbered revision; the actor and change message are taken
+// from the context (see package reqctx).
 type ConfigProvider interface {
 	LoadConfig(ctx context.Context) (*domain.BucardoConfig, error)
 	SaveConfig(ctx context.Context, config *domain.BucardoConfig) error
+	// ListRevisions returns the recorded revisions, oldest first.
+	ListRevisions(ctx context.Context) ([]domain.ConfigRevision, error)
+	GetRevision(ctx context.Context, revision int) (*domain.ConfigRevisionDetail, error)
+	// LatestRevision returns the number of the latest revision, or 0 before the first save.
+	LatestRevision(ctx context.Context) (int, error)
+}
+
+// AuditLog is an append-only trail of API mutations and executed commands. Record fills in
+// the time and, from the context, the actor and correlation ID when they are not set.
+type AuditLog interface {
+	Record(ctx context.Context, record domain.AuditRecord)
+	Query(ctx context.Context, query domain.AuditQuery) ([]domain.AuditRecord, error)
 }
 
 // CredentialManager defines the interface for managing database credentials.
@@ -35,18 +88,41 @@ type BucardoExecutor interface {
 	ListDatabases(ctx context.Context) ([]string, error)
 	DatabaseExists(ctx context.Context, dbName string) (bool, error)
 	RemoveDatabase(ctx context.Context, dbName string) error
+	RenameDatabase(ctx context.Context, oldName, newName, dbHost, dbUser, dbPass string, dbPort int) error
 	ListSyncs(ctx context.Context) ([]string, error)
 	SyncExists(ctx context.Context, syncName string) (bool, []byte, error)
 	GetSyncRelgroup(ctx context.Context, syncDetailsOutput []byte) (string, error)
 	GetSyncTables(ctx context.Context, relgroupName string) ([]string, error)
 	RemoveSyncAndRelgroup(ctx context.Context, syncName, relgroupName, dbHost, dbUser, dbPass string, dbPort int) error
 	ExecuteBucardoCommand(ctx context.Context, args ...string) error
+	KickSync(ctx context.Context, syncName string, timeout int) (*domain.SyncRunResult, error)
+	GetSyncStatus(ctx context.Context, syncName string) (*domain.SyncRunResult, error)
+	ActivateSync(ctx context.Context, syncName string) error
+	DeactivateSync(ctx context.Context, syncName string) error
+	SetSyncOnetimecopy(ctx context.Context, syncName string, mode int) error
 	StartBucardo(ctx context.Context) error
 	StopBucardo(ctx context.Context) error
 }
 
+// DatabaseInspector defines the port for running read-only queries against replicated databases.
+type DatabaseInspector interface {
+	DescribeTable(ctx context.Context, conn domain.ConnInfo, table string) (*domain.TableInfo, error)
+	CountRows(ctx context.Context, conn domain.ConnInfo, table *domain.TableInfo) (int64, error)
+	KeyRanges(ctx context.Context, conn domain.ConnInfo, table *domain.TableInfo, chunkSize int) ([]domain.KeyRange, error)
+	RangeChecksum(ctx context.Context, conn domain.ConnInfo, table *domain.TableInfo, keyRange domain.KeyRange) (int64, string, error)
+	DeltaBacklog(ctx context.Context, conn domain.ConnInfo) ([]domain.DeltaTableStats, error)
+	PurgeDeltas(ctx context.Context, conn domain.ConnInfo, minAgeSeconds int, dryRun bool) ([]domain.DeltaPurgeResult, error)
+}
+
+// Notifier defines the port for publishing replication events to interested parties.
+type Notifier interface {
+	Notify(ctx context.Context, event domain.Event)
+}
+
 // Monitor defines the port for observing the Bucardo process.
 type Monitor interface {
-	MonitorSyncs(ctx context.Context, config *domain.BucardoConfig, runOnceSyncs map[string]bool, maxTimeout *int, stopBucardoFunc func())
+	// MonitorSyncs waits until every run-once sync has completed, passed its own timeout
+	// (in seconds, 0 for none) or ctx is cancelled, and reports the result of each.
+	MonitorSyncs(ctx context.Context, config *domain.BucardoConfig, timeouts map[string]int) *domain.RunOnceSummary
 	MonitorBucardo(ctx context.Context, stopFunc func())
 }
@@ -0,0 +1,77 @@
+// Package reqctx carries request metadata, such as who asked for a change, from the API
+// adapters through the core to the adapters that record it.
+package reqctx
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/binary"
+	"encoding/hex"
+	"time"
+)
+
+// SystemActor is reported for changes that were not made on behalf of an API caller.
+const SystemActor = "system"
+
+type actorKey struct{}
+type changeMessageKey struct{}
+type correlationIDKey struct{}
+type expectedRevisionKey struct{}
+
+// WithActor returns a context that records who is making the request.
+func WithActor(ctx context.Context, actor string) context.Context {
+	return context.WithValue(ctx, actorKey{}, actor)
+}
+
+// Actor returns the caller recorded by WithActor, or SystemActor.
+func Actor(ctx context.Context) string {
+	if actor, ok := ctx.Value(actorKey{}).(string); ok && actor != "" {
+		return actor
+	}
+	return SystemActor
+}
+
+// WithChangeMessage returns a context that records why a change is being made.
+func WithChangeMessage(ctx context.Context, message string) context.Context {
+	return context.WithValue(ctx, changeMessageKey{}, message)
+}
+
+// ChangeMessage returns the message recorded by WithChangeMessage, if any.
+func ChangeMessage(ctx context.Context) string {
+	message, _ := ctx.Value(changeMessageKey{}).(string)
+	return message
+}
+
+// WithCorrelationID returns a context whose work is recorded under the given ID, such as
+// the ID of an API request or of the startup reconcile.
+func WithCorrelationID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, correlationIDKey{}, id)
+}
+
+// NewCorrelationID returns a random ID for a request that did not bring one. Should the
+// system's random source fail, the ID is derived from the current time instead.
+func NewCorrelationID() string {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		binary.BigEndian.PutUint64(b, uint64(time.Now().UnixNano()))
+	}
+	return hex.EncodeToString(b)
+}
+
+// CorrelationID returns the ID recorded by WithCorrelationID, if any.
+func CorrelationID(ctx context.Context) string {
+	id, _ := ctx.Value(correlationIDKey{}).(string)
+	return id
+}
+
+// WithExpectedRevision returns a context whose configuration saves only succeed while the
+// latest configuration revision is still revision, e.g. from an If-Match header.
+func WithExpectedRevision(ctx context.Context, revision int) context.Context {
+	return context.WithValue(ctx, expectedRevisionKey{}, revision)
+}
+
+// ExpectedRevision returns the revision recorded by WithExpectedRevision, if any.
+func ExpectedRevision(ctx context.Context) (int, bool) {
+	revision, ok := ctx.Value(expectedRevisionKey{}).(int)
+	return revision, ok
+}
@@ -0,0 +1,233 @@
+package orchestrator
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+
+	"replication-service/internal/core/domain"
+	"replication-service/internal/core/reqctx"
+)
+
+// secretConfigKeys are configuration fields whose values are masked in diffs.
+var secretConfigKeys = map[string]bool{"pass": true, "secret": true, "smtp_pass": true}
+
+const redactedValue = "********"
+
+// ListConfigRevisions returns the recorded configuration revisions, newest first.
+func (s *Service) ListConfigRevisions(ctx context.Context) ([]domain.ConfigRevision, error) {
+	revisions, err := s.config.ListRevisions(ctx)
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(revisions, func(i, j int) bool { return revisions[i].Revision > revisions[j].Revision })
+	return revisions, nil
+}
+
+// GetConfigRevision returns a recorded revision together with the configuration it saved.
+func (s *Service) GetConfigRevision(ctx context.Context, revision int) (*domain.ConfigRevisionDetail, error) {
+	return s.config.GetRevision(ctx, revision)
+}
+
+// LatestConfigRevision returns the number of the latest configuration revision, or 0 if
+// the configuration has not been changed through the service yet.
+func (s *Service) LatestConfigRevision(ctx context.Context) (int, error) {
+	return s.config.LatestRevision(ctx)
+}
+
+// DiffConfigRevisions lists the changes that lead from revision from to revision to.
+// Passwords and secrets are masked.
+func (s *Service) DiffConfigRevisions(ctx context.Context, from, to int) (*domain.ConfigDiff, error) {
+	older, err := s.config.GetRevision(ctx, from)
+	if err != nil {
+		return nil, err
+	}
+	newer, err := s.config.GetRevision(ctx, to)
+	if err != nil {
+		return nil, err
+	}
+	changes, err := diffConfigs(older.Config, newer.Config)
+	if err != nil {
+		return nil, err
+	}
+	return &domain.ConfigDiff{From: from, To: to, Changes: changes}, nil
+}
+
+// DiffConfigs lists the changes that lead from one configuration to another, with
+// passwords and secrets masked.
+func (s *Service) DiffConfigs(older, newer *domain.BucardoConfig) ([]domain.ConfigChange, error) {
+	return diffConfigs(older, newer)
+}
+
+// RollbackConfig saves the configuration of an earlier revision as a new revision. Like
+// any other configuration change it only takes effect on the next reconcile.
+func (s *Service) RollbackConfig(ctx context.Context, revision int) error {
+	detail, err := s.config.GetRevision(ctx, revision)
+	if err != nil {
+		return err
+	}
+	message := fmt.Sprintf("Rollback to revision %d", revision)
+	if m := reqctx.ChangeMessage(ctx); m != "" {
+		message += ": " + m
+	}
+	s.logger.Info("Rolling back configuration", "component", "config", "revision", revision, "actor", reqctx.Actor(ctx))
+	return s.UpdateConfig(reqctx.WithChangeMessage(ctx, message), detail.Config)
+}
+
+// diffConfigs compares two configurations field by field. Lists of objects that carry a
+// name or id are matched on it, so reordering syncs or databases is not reported.
+func diffConfigs(older, newer *domain.BucardoConfig) ([]domain.ConfigChange, error) {
+	a, err := toGeneric(older)
+	if err != nil {
+		return nil, err
+	}
+	b, err := toGeneric(newer)
+	if err != nil {
+		return nil, err
+	}
+	changes := []domain.ConfigChange{}
+	diffValues("", "", a, b, &changes)
+	return changes, nil
+}
+
+func toGeneric(v any) (any, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal config: %w", err)
+	}
+	var generic any
+	if err := json.Unmarshal(data, &generic); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal config: %w", err)
+	}
+	return generic, nil
+}
+
+func diffValues(path, key string, a, b any, changes *[]domain.ConfigChange) {
+	if reflect.DeepEqual(a, b) {
+		return
+	}
+	switch {
+	case a == nil:
+		*changes = append(*changes, domain.ConfigChange{Path: path, Op: domain.ConfigChangeAdded, New: redact(key, b)})
+		return
+	case b == nil:
+		*changes = append(*changes, domain.ConfigChange{Path: path, Op: domain.ConfigChangeRemoved, Old: redact(key, a)})
+		return
+	}
+
+	mapA, okA := a.(map[string]any)
+	mapB, okB := b.(map[string]any)
+	if okA && okB {
+		keys := make(map[string]bool)
+		for k := range mapA {
+			keys[k] = true
+		}
+		for k := range mapB {
+			keys[k] = true
+		}
+		sorted := make([]string, 0, len(keys))
+		for k := range keys {
+			sorted = append(sorted, k)
+		}
+		sort.Strings(sorted)
+		for _, k := range sorted {
+			childPath := k
+			if path != "" {
+				childPath = path + "." + k
+			}
+			diffValues(childPath, k, mapA[k], mapB[k], changes)
+		}
+		return
+	}
+
+	listA, okA := a.([]any)
+	listB, okB := b.([]any)
+	if okA && okB {
+		diffLists(path, key, listA, listB, changes)
+		return
+	}
+
+	*changes = append(*changes, domain.ConfigChange{Path: path, Op: domain.ConfigChangeModified, Old: redact(key, a), New: redact(key, b)})
+}
+
+func diffLists(path, key string, a, b []any, changes *[]domain.ConfigChange) {
+	idKey := listIdentity(a, b)
+	if idKey == "" {
+		for i := 0; i < len(a) || i < len(b); i++ {
+			var x, y any
+			if i < len(a) {
+				x = a[i]
+			}
+			if i < len(b) {
+				y = b[i]
+			}
+			diffValues(fmt.Sprintf("%s[%d]", path, i), key, x, y, changes)
+		}
+		return
+	}
+
+	elementPath := func(item any) string {
+		return fmt.Sprintf("%s[%s=%v]", path, idKey, item.(map[string]any)[idKey])
+	}
+	inB := make(map[string]any, len(b))
+	for _, item := range b {
+		inB[elementPath(item)] = item
+	}
+	seen := make(map[string]bool, len(a))
+	for _, item := range a {
+		p := elementPath(item)
+		seen[p] = true
+		diffValues(p, key, item, inB[p], changes)
+	}
+	for _, item := range b {
+		if p := elementPath(item); !seen[p] {
+			diffValues(p, key, nil, item, changes)
+		}
+	}
+}
+
+// listIdentity returns "name" or "id" when every element of both lists is an object
+// carrying that field, and "" otherwise.
+func listIdentity(a, b []any) string {
+	for _, idKey := range []string{"name", "id"} {
+		ok := len(a)+len(b) > 0
+		for _, list := range [][]any{a, b} {
+			for _, item := range list {
+				m, isMap := item.(map[string]any)
+				if !isMap || m[idKey] == nil {
+					ok = false
+				}
+			}
+		}
+		if ok {
+			return idKey
+		}
+	}
+	return ""
+}
+
+// redact masks secret values, including those nested in added or removed objects. The
+// "env" placeholder is not a secret and is kept.
+func redact(key string, v any) any {
+	switch value := v.(type) {
+	case map[string]any:
+		masked := make(map[string]any, len(value))
+		for k, child := range value {
+			masked[k] = redact(k, child)
+		}
+		return masked
+	case []any:
+		masked := make([]any, len(value))
+		for i, child := range value {
+			masked[i] = redact(key, child)
+		}
+		return masked
+	case string:
+		if secretConfigKeys[key] && value != "" && value != "env" {
+			return redactedValue
+		}
+	}
+	return v
+}
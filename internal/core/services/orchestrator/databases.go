@@ -0,0 +1,113 @@
+package orchestrator
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+
+	"replication-service/internal/core/domain"
+)
+
+var (
+	// databaseNameRe is the form of a database name. Names are used as-is as Bucardo object
+	// names and, upper-cased, in environment variable names.
+	databaseNameRe = regexp.MustCompile(`^[a-z][a-z0-9_]{0,62}$`)
+	// legacyNameRe matches the Bucardo names of databases referenced by ID.
+	legacyNameRe = regexp.MustCompile(`^db[0-9]+$`)
+)
+
+// validateDatabases checks that every database can be told apart: IDs and names are unique,
+// and names are valid Bucardo names that cannot be mistaken for the "db<ID>" name of another
+// database.
+func validateDatabases(databases []domain.Database) []error {
+	var errs []error
+	ids := make(map[int]bool)
+	names := make(map[string]bool)
+	for _, db := range databases {
+		if db.Name == "" || db.ID != 0 {
+			if ids[db.ID] {
+				errs = append(errs, fmt.Errorf("database ID %d is duplicated", db.ID))
+			}
+			ids[db.ID] = true
+		}
+		if db.Name == "" {
+			continue
+		}
+		switch {
+		case !databaseNameRe.MatchString(db.Name):
+			errs = append(errs, fmt.Errorf("database name '%s' is invalid: use lowercase letters, digits and underscores, starting with a letter", db.Name))
+		case legacyNameRe.MatchString(db.Name):
+			errs = append(errs, fmt.Errorf("database name '%s' is reserved: names of the form db<number> are those of databases referenced by ID", db.Name))
+		}
+		if names[db.Name] {
+			errs = append(errs, fmt.Errorf("database name '%s' is duplicated", db.Name))
+		}
+		names[db.Name] = true
+	}
+	return errs
+}
+
+// describeRef names the database a reference points to in messages, e.g. "database ID 3"
+// or "database 'orders'".
+func describeRef(ref domain.DBRef) string {
+	if id, ok := ref.ID(); ok {
+		return fmt.Sprintf("database ID %d", id)
+	}
+	return fmt.Sprintf("database '%s'", ref)
+}
+
+// bucardoDbName returns the Bucardo name of the database a reference points to. Validated
+// configurations only hold defined references; others are named the way Bucardo would know
+// them.
+func bucardoDbName(config *domain.BucardoConfig, ref domain.DBRef) string {
+	if db, ok := config.FindDatabase(ref); ok {
+		return db.BucardoName()
+	}
+	if id, ok := ref.ID(); ok {
+		return domain.LegacyBucardoName(id)
+	}
+	return string(ref)
+}
+
+// renameDatabases gives databases that gained a name that name in Bucardo. A database added
+// while it only had an ID is known to Bucardo as "db<ID>"; renaming it in place keeps the
+// dbgroups and syncs that use it, where removing it as an orphan and adding it under the new
+// name would drop them.
+func (s *Service) renameDatabases(ctx context.Context, config *domain.BucardoConfig, dbHost, dbUser, dbPass string, dbPort int) error {
+	appLogger := s.logger.WithContext(ctx).With("component", "db_reconciler")
+
+	var named []domain.Database
+	for _, db := range config.Databases {
+		if db.Name != "" && db.ID != 0 {
+			named = append(named, db)
+		}
+	}
+	if len(named) == 0 {
+		return nil
+	}
+
+	bucardoDbs, err := s.bucardo.ListDatabases(ctx)
+	if err != nil {
+		return fmt.Errorf("could not list existing Bucardo databases for renaming: %w", err)
+	}
+	existing := make(map[string]bool, len(bucardoDbs))
+	for _, name := range bucardoDbs {
+		existing[name] = true
+	}
+
+	for _, db := range named {
+		oldName := domain.LegacyBucardoName(db.ID)
+		if !existing[oldName] {
+			continue
+		}
+		if existing[db.Name] {
+			appLogger.Warn("Database exists in Bucardo under both its old and its new name; the old one will be removed as an orphan", "old_name", oldName, "db_name", db.Name)
+			continue
+		}
+		appLogger.Info("Renaming database to its configured name", "old_name", oldName, "db_name", db.Name)
+		if err := s.bucardo.RenameDatabase(ctx, oldName, db.Name, dbHost, dbUser, dbPass, dbPort); err != nil {
+			return fmt.Errorf("failed to rename database %s to %s: %w", oldName, db.Name, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,194 @@
+package orchestrator
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"replication-service/internal/core/domain"
+)
+
+// ErrDatabaseNotFound is returned when a database reference is not present in the configuration.
+var ErrDatabaseNotFound = errors.New("database not found")
+
+const (
+	defaultDeltaInterval = 300 * time.Second
+	defaultPurgeMinAge   = 60
+)
+
+// deltaStore keeps the latest delta backlog measurement of each source database.
+type deltaStore struct {
+	mu       sync.Mutex
+	backlogs map[string]domain.DeltaBacklog // By Bucardo database name.
+}
+
+func newDeltaStore() *deltaStore {
+	return &deltaStore{backlogs: make(map[string]domain.DeltaBacklog)}
+}
+
+func (d *deltaStore) set(backlog domain.DeltaBacklog) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.backlogs[backlog.Database] = backlog
+}
+
+func (d *deltaStore) list() []domain.DeltaBacklog {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	backlogs := make([]domain.DeltaBacklog, 0, len(d.backlogs))
+	for _, b := range d.backlogs {
+		backlogs = append(backlogs, b)
+	}
+	sort.Slice(backlogs, func(i, j int) bool {
+		if backlogs[i].DatabaseID != backlogs[j].DatabaseID {
+			return backlogs[i].DatabaseID < backlogs[j].DatabaseID
+		}
+		return backlogs[i].Database < backlogs[j].Database
+	})
+	return backlogs
+}
+
+// runDeltaCollector measures the delta backlog of every source database right away and
+// then periodically, until ctx is cancelled. The interval is re-read from the configuration
+// on every cycle; while collection is disabled the configuration is re-checked at the
+// default interval, so enabling it again takes effect without a restart.
+func (s *Service) runDeltaCollector(ctx context.Context) {
+	disabled := false
+	for {
+		interval := defaultDeltaInterval
+		enabled := true
+		if config, err := s.config.LoadConfig(ctx); err == nil && config.DeltaMonitor != nil {
+			if config.DeltaMonitor.IntervalSeconds < 0 {
+				enabled = false
+			} else if config.DeltaMonitor.IntervalSeconds > 0 {
+				interval = time.Duration(config.DeltaMonitor.IntervalSeconds) * time.Second
+			}
+		}
+		if enabled == disabled { // Switched since the last cycle.
+			disabled = !enabled
+			if disabled {
+				s.logger.Info("Delta backlog collection is disabled", "component", "delta_monitor")
+			} else {
+				s.logger.Info("Delta backlog collection is enabled", "component", "delta_monitor")
+			}
+		}
+
+		if enabled {
+			if _, err := s.CollectDeltaBacklog(ctx); err != nil {
+				s.logger.Warn("Failed to collect delta backlog", "component", "delta_monitor", "error", err)
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(interval):
+		}
+	}
+}
+
+// CollectDeltaBacklog measures the delta backlog of every database used as a source,
+// stores the result and logs a warning for tables above the configured thresholds.
+func (s *Service) CollectDeltaBacklog(ctx context.Context) ([]domain.DeltaBacklog, error) {
+	config, err := s.config.LoadConfig(ctx)
+	if err != nil {
+		return nil, err
+	}
+	thresholds := domain.DeltaMonitorConfig{}
+	if config.DeltaMonitor != nil {
+		thresholds = *config.DeltaMonitor
+	}
+
+	appLogger := s.logger.With("component", "delta_monitor")
+	for _, db := range sourceDatabases(config) {
+		backlog := domain.DeltaBacklog{DatabaseID: db.ID, Database: db.BucardoName(), CollectedAt: time.Now()}
+		dbLogger := appLogger.With("db_name", backlog.Database, "db_id", db.ID)
+
+		conn, err := s.databaseConn(config, db.Ref())
+		if err == nil {
+			backlog.Tables, err = s.inspector.DeltaBacklog(ctx, conn.conn)
+		}
+		if err != nil {
+			backlog.Error = err.Error()
+			dbLogger.Warn("Could not measure delta backlog", "error", err)
+			s.deltas.set(backlog)
+			continue
+		}
+
+		for _, t := range backlog.Tables {
+			rowsExceeded := thresholds.WarnRows > 0 && t.PendingRows > thresholds.WarnRows
+			ageExceeded := thresholds.WarnAgeSeconds > 0 && t.OldestPendingAgeSeconds > float64(thresholds.WarnAgeSeconds)
+			if rowsExceeded || ageExceeded {
+				dbLogger.Warn("Delta backlog above threshold", "table", t.Table, "pending_rows", t.PendingRows,
+					"oldest_pending_age_seconds", t.OldestPendingAgeSeconds, "delta_rows", t.DeltaRows, "track_rows", t.TrackRows)
+				continue
+			}
+			dbLogger.Debug("Delta backlog", "table", t.Table, "pending_rows", t.PendingRows,
+				"oldest_pending_age_seconds", t.OldestPendingAgeSeconds, "delta_rows", t.DeltaRows, "track_rows", t.TrackRows)
+		}
+		s.deltas.set(backlog)
+	}
+	return s.deltas.list(), nil
+}
+
+// ListDeltaBacklogs returns the latest delta backlog measurements.
+func (s *Service) ListDeltaBacklogs(_ context.Context) []domain.DeltaBacklog {
+	return s.deltas.list()
+}
+
+// PurgeDeltas removes delta and track rows that every target has already replicated on a
+// source database, referenced by name or ID. With dryRun set it only reports what would be
+// removed.
+func (s *Service) PurgeDeltas(ctx context.Context, ref domain.DBRef, minAgeSeconds int, dryRun bool) (*domain.DeltaPurgeReport, error) {
+	config, err := s.config.LoadConfig(ctx)
+	if err != nil {
+		return nil, err
+	}
+	db, found := config.FindDatabase(ref)
+	if !found {
+		return nil, fmt.Errorf("%w: %s", ErrDatabaseNotFound, ref)
+	}
+	if minAgeSeconds < 0 {
+		minAgeSeconds = defaultPurgeMinAge
+	}
+
+	conn, err := s.databaseConn(config, ref)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &domain.DeltaPurgeReport{DatabaseID: db.ID, Database: conn.name, DryRun: dryRun, MinAgeSeconds: minAgeSeconds}
+	s.logger.Info("Purging replicated delta rows", "component", "delta_monitor", "db_name", conn.name, "dry_run", dryRun, "min_age_seconds", minAgeSeconds)
+	report.Tables, err = s.inspector.PurgeDeltas(ctx, conn.conn, minAgeSeconds, dryRun)
+	if err != nil {
+		return report, err
+	}
+
+	var deltaRows, trackRows int64
+	for _, t := range report.Tables {
+		deltaRows += t.DeltaRows
+		trackRows += t.TrackRows
+	}
+	s.logger.Info("Delta purge finished", "component", "delta_monitor", "db_name", conn.name, "dry_run", dryRun, "delta_rows", deltaRows, "track_rows", trackRows)
+	return report, nil
+}
+
+// sourceDatabases returns every database that acts as a source in some sync.
+func sourceDatabases(config *domain.BucardoConfig) []domain.Database {
+	isSource := make(map[string]bool)
+	for _, sync := range config.Syncs {
+		for _, name := range resolveRefs(config, append(append([]domain.DBRef{}, sync.Sources...), sync.Bidirectional...)) {
+			isSource[name] = true
+		}
+	}
+	var sources []domain.Database
+	for _, db := range config.Databases {
+		if isSource[db.BucardoName()] {
+			sources = append(sources, db)
+		}
+	}
+	return sources
+}
@@ -0,0 +1,391 @@
+package orchestrator
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"replication-service/internal/core/domain"
+)
+
+var (
+	// ErrJobNotFound is returned when a job ID is unknown.
+	ErrJobNotFound = errors.New("job not found")
+	// ErrJobFinished is returned when cancelling a job that has already finished.
+	ErrJobFinished = errors.New("job has already finished")
+)
+
+const maxFinishedJobsKept = 100
+
+type jobContextKey struct{}
+
+// job is a background operation together with the means to cancel it.
+type job struct {
+	info        domain.Job
+	cancel      context.CancelFunc
+	currentStep int // Index of the step opened by nextStep, or -1.
+}
+
+// jobManager keeps track of running and recently finished jobs.
+type jobManager struct {
+	mu               sync.Mutex
+	jobs             map[string]*job
+	order            []string
+	pendingReconcile *job // A reconcile job waiting for the running one; new requests join it.
+}
+
+func newJobManager() *jobManager {
+	return &jobManager{jobs: make(map[string]*job)}
+}
+
+// createLocked registers a queued job. The caller holds m.mu.
+func (m *jobManager) createLocked(jobType, target string) *job {
+	j := &job{
+		info: domain.Job{
+			ID:        newRunID(),
+			Type:      jobType,
+			Target:    target,
+			State:     domain.JobStateQueued,
+			CreatedAt: time.Now(),
+			Steps:     []domain.JobStep{},
+		},
+		currentStep: -1,
+	}
+	m.jobs[j.info.ID] = j
+	m.order = append(m.order, j.info.ID)
+	m.pruneLocked()
+	return j
+}
+
+// pruneLocked forgets the oldest finished jobs beyond maxFinishedJobsKept.
+func (m *jobManager) pruneLocked() {
+	finished := 0
+	for _, id := range m.order {
+		if m.jobs[id].info.FinishedAt != nil {
+			finished++
+		}
+	}
+	kept := m.order[:0]
+	for _, id := range m.order {
+		if finished > maxFinishedJobsKept && m.jobs[id].info.FinishedAt != nil {
+			delete(m.jobs, id)
+			finished--
+			continue
+		}
+		kept = append(kept, id)
+	}
+	m.order = kept
+}
+
+// update applies fn to a job while holding the lock.
+func (m *jobManager) update(id string, fn func(*job)) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if j, ok := m.jobs[id]; ok {
+		fn(j)
+	}
+}
+
+// snapshotLocked returns a copy of a job that callers can read without holding the lock.
+func snapshotLocked(j *job) domain.Job {
+	info := j.info
+	info.Steps = append([]domain.JobStep{}, j.info.Steps...)
+	return info
+}
+
+func (m *jobManager) get(id string) (domain.Job, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	j, ok := m.jobs[id]
+	if !ok {
+		return domain.Job{}, false
+	}
+	return snapshotLocked(j), true
+}
+
+// runJob starts fn in the background under a cancellable context that carries the job ID
+// and the request metadata of ctx. When acquire is set, the job stays queued until acquire
+// returns; acquire must give up when its context is cancelled. fn then never runs, and
+// abort, when set, finishes whatever the job had set up and returns the job's result.
+func (s *Service) runJob(ctx context.Context, j *job, acquire func(ctx context.Context) (release func(), err error), abort func(err error) any, fn func(ctx context.Context) (any, error)) {
+	// Jobs outlive the HTTP request that started them, but are audited as part of it.
+	ctx, cancel := context.WithCancel(context.WithValue(context.WithoutCancel(ctx), jobContextKey{}, j.info.ID))
+	s.jobs.mu.Lock()
+	j.cancel = cancel
+	s.jobs.mu.Unlock()
+
+	jobLogger := s.logger.With("component", "jobs", "job_id", j.info.ID, "job_type", j.info.Type)
+	go func() {
+		defer cancel()
+
+		var result any
+		var err error
+		if acquire != nil {
+			var release func()
+			if release, err = acquire(ctx); err == nil {
+				defer release()
+			} else if abort != nil {
+				result = abort(err)
+			}
+		}
+		if err == nil {
+			s.jobs.update(j.info.ID, func(j *job) {
+				now := time.Now()
+				j.info.StartedAt = &now
+				j.info.State = domain.JobStateRunning
+			})
+			jobLogger.Info("Job started", "target", j.info.Target)
+			result, err = fn(ctx)
+		}
+
+		state := domain.JobStateSucceeded
+		switch {
+		case err != nil && ctx.Err() != nil:
+			state = domain.JobStateCancelled
+		case err != nil:
+			state = domain.JobStateFailed
+		}
+		s.jobs.update(j.info.ID, func(j *job) {
+			now := time.Now()
+			j.info.FinishedAt = &now
+			j.info.State = state
+			j.info.Result = result
+			if err != nil {
+				j.info.Error = err.Error()
+			}
+			for i := range j.info.Steps {
+				if j.info.Steps[i].FinishedAt == nil {
+					j.info.Steps[i].FinishedAt = &now
+					j.info.Steps[i].State = state
+					if err != nil {
+						j.info.Steps[i].Error = err.Error()
+					}
+				}
+			}
+		})
+
+		if err != nil {
+			jobLogger.Warn("Job did not succeed", "state", state, "error", err)
+			return
+		}
+		jobLogger.Info("Job finished")
+	}()
+}
+
+func jobIDFromContext(ctx context.Context) (string, bool) {
+	id, ok := ctx.Value(jobContextKey{}).(string)
+	return id, ok
+}
+
+// beginStep records the start of a step of the job running under ctx, if any, and returns
+// its index for endStep. Steps may overlap, e.g. tables verified in parallel.
+func (s *Service) beginStep(ctx context.Context, name string) int {
+	id, ok := jobIDFromContext(ctx)
+	if !ok {
+		return -1
+	}
+	index := -1
+	s.jobs.update(id, func(j *job) {
+		j.info.Steps = append(j.info.Steps, domain.JobStep{Name: name, State: domain.JobStateRunning, StartedAt: time.Now()})
+		index = len(j.info.Steps) - 1
+	})
+	return index
+}
+
+// endStep records the outcome of a step started with beginStep.
+func (s *Service) endStep(ctx context.Context, index int, err error) {
+	id, ok := jobIDFromContext(ctx)
+	if !ok || index < 0 {
+		return
+	}
+	s.jobs.update(id, func(j *job) {
+		if index >= len(j.info.Steps) || j.info.Steps[index].FinishedAt != nil {
+			return
+		}
+		now := time.Now()
+		step := &j.info.Steps[index]
+		step.FinishedAt = &now
+		step.State = domain.JobStateSucceeded
+		if err != nil {
+			step.State = domain.JobStateFailed
+			step.Error = err.Error()
+		}
+	})
+}
+
+// nextStep ends the previous sequential step of the job running under ctx successfully
+// and begins a new one. A failing step is closed with the job's error when the job ends.
+func (s *Service) nextStep(ctx context.Context, name string) {
+	id, ok := jobIDFromContext(ctx)
+	if !ok {
+		return
+	}
+	var previous int
+	s.jobs.update(id, func(j *job) { previous = j.currentStep })
+	s.endStep(ctx, previous, nil)
+	index := s.beginStep(ctx, name)
+	s.jobs.update(id, func(j *job) { j.currentStep = index })
+}
+
+// StartReconcileJob queues a reconcile of Bucardo with the configuration. Only one
+// reconcile runs at a time; a request made while another reconcile is already queued is
+// merged into that queued job, which will pick up the latest configuration when it starts.
+func (s *Service) StartReconcileJob(ctx context.Context) (*domain.Job, error) {
+	s.jobs.mu.Lock()
+	if pending := s.jobs.pendingReconcile; pending != nil {
+		pending.info.Coalesced++
+		snapshot := snapshotLocked(pending)
+		s.jobs.mu.Unlock()
+		return &snapshot, nil
+	}
+	j := s.jobs.createLocked(domain.JobTypeReconcile, "")
+	s.jobs.pendingReconcile = j
+	s.jobs.mu.Unlock()
+
+	acquire := func(ctx context.Context) (func(), error) {
+		release, err := s.acquireReconcile(ctx)
+		// Once the job runs, or is cancelled, later requests need a job of their own.
+		s.jobs.mu.Lock()
+		if s.jobs.pendingReconcile == j {
+			s.jobs.pendingReconcile = nil
+		}
+		s.jobs.mu.Unlock()
+		return release, err
+	}
+	s.runJob(ctx, j, acquire, nil, func(ctx context.Context) (any, error) {
+		return nil, s.reconcile(ctx)
+	})
+
+	snapshot, _ := s.jobs.get(j.info.ID)
+	return &snapshot, nil
+}
+
+// reconcileSlot is held exclusively by a reconcile, which stops Bucardo and rewrites its
+// syncs, and shared by the recopy and verify jobs that rely on those syncs staying as they
+// are. A waiting reconcile goes before shared holders that arrive after it.
+type reconcileSlot struct {
+	mu        sync.Mutex
+	exclusive bool
+	shared    int
+	waiting   int           // Reconciles waiting for the slot.
+	released  chan struct{} // Closed, and replaced, whenever the slot changes hands.
+}
+
+func newReconcileSlot() *reconcileSlot {
+	return &reconcileSlot{released: make(chan struct{})}
+}
+
+// acquire waits until the slot can be held in the requested mode or ctx is done.
+func (r *reconcileSlot) acquire(ctx context.Context, exclusive bool) (func(), error) {
+	r.mu.Lock()
+	if exclusive {
+		r.waiting++
+	}
+	for {
+		free := !r.exclusive && (r.shared == 0 || !exclusive) && (exclusive || r.waiting == 0)
+		if free {
+			break
+		}
+		released := r.released
+		r.mu.Unlock()
+		select {
+		case <-released:
+		case <-ctx.Done():
+			r.mu.Lock()
+			if exclusive {
+				r.waiting--
+				r.signalLocked()
+			}
+			r.mu.Unlock()
+			return nil, ctx.Err()
+		}
+		r.mu.Lock()
+	}
+	if exclusive {
+		r.waiting--
+		r.exclusive = true
+	} else {
+		r.shared++
+	}
+	r.mu.Unlock()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			r.mu.Lock()
+			defer r.mu.Unlock()
+			if exclusive {
+				r.exclusive = false
+			} else {
+				r.shared--
+			}
+			r.signalLocked()
+		})
+	}, nil
+}
+
+// signalLocked wakes every waiter to check the slot again. The caller holds r.mu.
+func (r *reconcileSlot) signalLocked() {
+	close(r.released)
+	r.released = make(chan struct{})
+}
+
+// acquireReconcile waits until no other reconcile, recopy or verify job is running.
+func (s *Service) acquireReconcile(ctx context.Context) (func(), error) {
+	return s.reconcileSlot.acquire(ctx, true)
+}
+
+// acquireSyncJob waits until no reconcile is running or waiting. Recopy and verify jobs run
+// alongside each other.
+func (s *Service) acquireSyncJob(ctx context.Context) (func(), error) {
+	return s.reconcileSlot.acquire(ctx, false)
+}
+
+// GetJob returns a job by ID.
+func (s *Service) GetJob(_ context.Context, id string) (*domain.Job, error) {
+	j, ok := s.jobs.get(id)
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrJobNotFound, id)
+	}
+	return &j, nil
+}
+
+// ListJobs returns the known jobs, newest first, optionally limited to one type.
+func (s *Service) ListJobs(_ context.Context, jobType string) []domain.Job {
+	s.jobs.mu.Lock()
+	defer s.jobs.mu.Unlock()
+	jobs := make([]domain.Job, 0, len(s.jobs.jobs))
+	for _, j := range s.jobs.jobs {
+		if jobType == "" || j.info.Type == jobType {
+			jobs = append(jobs, snapshotLocked(j))
+		}
+	}
+	sort.Slice(jobs, func(i, k int) bool { return jobs[i].CreatedAt.After(jobs[k].CreatedAt) })
+	return jobs
+}
+
+// CancelJob cancels a queued or running job through its context.
+func (s *Service) CancelJob(_ context.Context, id string) (*domain.Job, error) {
+	s.jobs.mu.Lock()
+	j, ok := s.jobs.jobs[id]
+	if !ok {
+		s.jobs.mu.Unlock()
+		return nil, fmt.Errorf("%w: %s", ErrJobNotFound, id)
+	}
+	if j.info.FinishedAt != nil {
+		s.jobs.mu.Unlock()
+		return nil, fmt.Errorf("%w: %s", ErrJobFinished, id)
+	}
+	cancel := j.cancel
+	snapshot := snapshotLocked(j)
+	s.jobs.mu.Unlock()
+
+	s.logger.Info("Cancelling job", "component", "jobs", "job_id", id, "job_type", snapshot.Type)
+	if cancel != nil {
+		cancel()
+	}
+	return &snapshot, nil
+}
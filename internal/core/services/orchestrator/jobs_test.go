@@ -0,0 +1,170 @@
+package orchestrator
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"replication-service/internal/core/domain"
+)
+
+// acquired reports whether acquire returns within a short time, and its release function.
+func acquired(t *testing.T, acquire func(context.Context) (func(), error)) (chan func(), func() bool) {
+	t.Helper()
+	result := make(chan func(), 1)
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	go func() {
+		if release, err := acquire(ctx); err == nil {
+			result <- release
+		}
+	}()
+	wait := func() bool {
+		select {
+		case release := <-result:
+			result <- release
+			return true
+		case <-time.After(50 * time.Millisecond):
+			return false
+		}
+	}
+	return result, wait
+}
+
+func TestReconcileSlot(t *testing.T) {
+	slot := newReconcileSlot()
+	ctx := context.Background()
+	shared := func(ctx context.Context) (func(), error) { return slot.acquire(ctx, false) }
+	exclusive := func(ctx context.Context) (func(), error) { return slot.acquire(ctx, true) }
+
+	// Recopy and verify jobs run alongside each other.
+	releaseA, err := slot.acquire(ctx, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	releaseB, err := slot.acquire(ctx, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// A reconcile waits for them, and jobs requested meanwhile wait for the reconcile.
+	reconcile, reconcileRuns := acquired(t, exclusive)
+	if reconcileRuns() {
+		t.Fatal("reconcile ran alongside recopy and verify jobs")
+	}
+	job, jobRuns := acquired(t, shared)
+	if jobRuns() {
+		t.Fatal("job started ahead of a waiting reconcile")
+	}
+	releaseA()
+	releaseA() // Releasing twice has no effect.
+	if reconcileRuns() {
+		t.Fatal("reconcile ran alongside a verify job")
+	}
+	releaseB()
+	if !reconcileRuns() {
+		t.Fatal("reconcile did not run once the jobs finished")
+	}
+	if jobRuns() {
+		t.Fatal("job ran alongside a reconcile")
+	}
+	(<-reconcile)()
+	if !jobRuns() {
+		t.Fatal("job did not start after the reconcile")
+	}
+	(<-job)()
+
+	// A reconcile that gives up waiting lets the jobs queued behind it start.
+	release, err := slot.acquire(ctx, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cancelCtx, cancel := context.WithCancel(ctx)
+	done := make(chan error)
+	go func() {
+		_, err := slot.acquire(cancelCtx, true)
+		done <- err
+	}()
+	time.Sleep(10 * time.Millisecond)
+	_, jobRuns = acquired(t, shared)
+	cancel()
+	if err := <-done; err == nil {
+		t.Fatal("cancelled reconcile acquired the slot")
+	}
+	if !jobRuns() {
+		t.Fatal("job did not start after the waiting reconcile was cancelled")
+	}
+	release()
+}
+
+// waitForJob polls a job until it finishes.
+func waitForJob(t *testing.T, env *testEnv, id string) *domain.Job {
+	t.Helper()
+	deadline := time.Now().Add(time.Second)
+	for {
+		j, err := env.service.GetJob(context.Background(), id)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if j.FinishedAt != nil {
+			return j
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("job %s did not finish: %+v", id, j)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestCancelQueuedRecopy(t *testing.T) {
+	env := newTestEnv(t, &domain.BucardoConfig{
+		Databases: databases(1, 2),
+		Syncs:     []domain.Sync{{Name: "orders", Sources: refs(1), Targets: refs(2), Tables: "public.orders"}},
+	})
+	ctx := context.Background()
+	if err := env.service.ReloadAndRestart(ctx); err != nil {
+		t.Fatal(err)
+	}
+	env.bucardo.Commands = nil
+
+	// A reconcile holds the slot, so the re-copy stays queued.
+	release, err := env.service.reconcileSlot.acquire(ctx, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	status, err := env.service.StartRecopy(ctx, "orders", domain.RecopyRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := env.service.CancelJob(ctx, status.JobID); err != nil {
+		t.Fatal(err)
+	}
+	j := waitForJob(t, env, status.JobID)
+	if j.State != domain.JobStateCancelled || j.StartedAt != nil {
+		t.Errorf("job = %s, started %v, want cancelled before it started", j.State, j.StartedAt)
+	}
+	got, err := env.service.GetRecopyStatus(ctx, "orders")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.FinishedAt == nil || got.State != domain.RecopyStateFailed || !strings.Contains(got.Error, "cancelled before it started") {
+		t.Errorf("re-copy status = %+v, want it finished as failed", got)
+	}
+	if result, ok := j.Result.(domain.RecopyStatus); !ok || result.FinishedAt == nil {
+		t.Errorf("job result = %#v, want the finished re-copy status", j.Result)
+	}
+	if len(env.bucardo.Commands) != 0 {
+		t.Errorf("cancelled re-copy ran %q", env.bucardo.Commands)
+	}
+
+	// The cancelled re-copy does not block the next one.
+	release()
+	status, err = env.service.StartRecopy(ctx, "orders", domain.RecopyRequest{})
+	if err != nil {
+		t.Fatalf("StartRecopy() after a cancelled one: %v", err)
+	}
+	if j := waitForJob(t, env, status.JobID); j.State != domain.JobStateSucceeded {
+		t.Errorf("second re-copy = %s (%s), want it to succeed", j.State, j.Error)
+	}
+}
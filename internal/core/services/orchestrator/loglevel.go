@@ -0,0 +1,92 @@
+package orchestrator
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"replication-service/internal/core/domain"
+)
+
+// ErrInvalidLogLevel is returned for a level that is not one of domain.ServiceLogLevels.
+var ErrInvalidLogLevel = errors.New("invalid log level")
+
+// GetServiceLogLevels returns the current level of the service's own logs.
+func (s *Service) GetServiceLogLevels() domain.ServiceLogConfig {
+	return s.logLevels.Levels()
+}
+
+// SetServiceLogLevels changes the level of the service's logs until the next reconcile
+// applies the service_log section of the configuration, if it has one. Only the given
+// levels change; an empty component level removes that component's override.
+func (s *Service) SetServiceLogLevels(ctx context.Context, levels domain.ServiceLogConfig) (domain.ServiceLogConfig, error) {
+	if errs := validateServiceLog(&levels, true); len(errs) > 0 {
+		return domain.ServiceLogConfig{}, fmt.Errorf("%w: %v", ErrInvalidLogLevel, errs)
+	}
+	s.logger.Info("Changing service log level", "component", "config", "level", levels.Level, "components", levels.Components)
+	if err := s.applyServiceLog(&levels); err != nil {
+		return domain.ServiceLogConfig{}, err
+	}
+	return s.logLevels.Levels(), nil
+}
+
+// setServiceLogLevel applies the service_log section of the configuration.
+func (s *Service) setServiceLogLevel(config *domain.BucardoConfig) error {
+	if config.ServiceLog == nil {
+		return nil
+	}
+	s.logger.Info("Setting service log level", "component", "config", "level", config.ServiceLog.Level, "components", config.ServiceLog.Components)
+	return s.applyServiceLog(config.ServiceLog)
+}
+
+func (s *Service) applyServiceLog(levels *domain.ServiceLogConfig) error {
+	if levels.Level != "" {
+		if err := s.logLevels.SetLevel("", levels.Level); err != nil {
+			return err
+		}
+	}
+	components := make([]string, 0, len(levels.Components))
+	for component := range levels.Components {
+		components = append(components, component)
+	}
+	sort.Strings(components)
+	for _, component := range components {
+		if err := s.logLevels.SetLevel(component, levels.Components[component]); err != nil {
+			return fmt.Errorf("component %s: %w", component, err)
+		}
+	}
+	return nil
+}
+
+// validateServiceLog checks the levels of a service_log section. allowEmpty accepts empty
+// component levels, which remove an override at runtime.
+func validateServiceLog(levels *domain.ServiceLogConfig, allowEmpty bool) []error {
+	var errs []error
+	if levels.Level != "" && !isServiceLogLevel(levels.Level) {
+		errs = append(errs, fmt.Errorf("service_log: invalid level '%s'. Must be one of: %v", levels.Level, domain.ServiceLogLevels))
+	}
+	for component, level := range levels.Components {
+		if component == "" {
+			errs = append(errs, fmt.Errorf("service_log: component names cannot be empty"))
+			continue
+		}
+		if level == "" && allowEmpty {
+			continue
+		}
+		if !isServiceLogLevel(level) {
+			errs = append(errs, fmt.Errorf("service_log: invalid level '%s' for component '%s'. Must be one of: %v", level, component, domain.ServiceLogLevels))
+		}
+	}
+	return errs
+}
+
+func isServiceLogLevel(level string) bool {
+	for _, l := range domain.ServiceLogLevels {
+		if strings.EqualFold(level, l) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,280 @@
+package orchestrator
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"replication-service/internal/core/domain"
+)
+
+// ErrRecopyInProgress is returned when a re-copy is requested for a sync that is already being copied.
+var ErrRecopyInProgress = errors.New("a re-copy is already in progress for this sync")
+
+const defaultRecopyTimeout = 3600
+
+// recopyTracker keeps the latest re-copy status of each sync.
+type recopyTracker struct {
+	mu       sync.Mutex
+	statuses map[string]*domain.RecopyStatus
+}
+
+func newRecopyTracker() *recopyTracker {
+	return &recopyTracker{statuses: make(map[string]*domain.RecopyStatus)}
+}
+
+// begin registers a new re-copy unless one is already running for the same sync.
+func (t *recopyTracker) begin(status *domain.RecopyStatus) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if current, ok := t.statuses[status.SyncName]; ok && current.FinishedAt == nil {
+		return fmt.Errorf("%w: %s", ErrRecopyInProgress, status.SyncName)
+	}
+	t.statuses[status.SyncName] = status
+	return nil
+}
+
+// update applies fn to the status of a sync while holding the lock.
+func (t *recopyTracker) update(syncName string, fn func(*domain.RecopyStatus)) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if status, ok := t.statuses[syncName]; ok {
+		fn(status)
+	}
+}
+
+// get returns a copy of the latest status of a sync.
+func (t *recopyTracker) get(syncName string) (domain.RecopyStatus, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	status, ok := t.statuses[syncName]
+	if !ok {
+		return domain.RecopyStatus{}, false
+	}
+	return *status, true
+}
+
+// StartRecopy forces a full copy of a sync, or of selected tables, in a background job.
+// Whole-sync copies temporarily switch the sync to onetimecopy=1 and reset it to 0 afterwards.
+// Table subsets are copied through a short-lived sync over the same dbgroup that is removed
+// once the run has finished.
+func (s *Service) StartRecopy(ctx context.Context, name string, req domain.RecopyRequest) (*domain.RecopyStatus, error) {
+	sync, err := s.GetSync(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+
+	if sync.IsPaused() {
+		return nil, fmt.Errorf("sync %s is paused; resume it before requesting a re-copy", name)
+	}
+
+	tables, err := s.recopyTables(ctx, *sync, req.Tables)
+	if err != nil {
+		return nil, err
+	}
+	timeout := req.Timeout
+	if timeout <= 0 {
+		timeout = defaultRecopyTimeout
+	}
+
+	status := &domain.RecopyStatus{
+		SyncName:  name,
+		Tables:    tables,
+		State:     domain.RecopyStatePending,
+		StartedAt: time.Now(),
+	}
+	if err := s.recopies.begin(status); err != nil {
+		return nil, err
+	}
+
+	s.jobs.mu.Lock()
+	j := s.jobs.createLocked(domain.JobTypeRecopy, name)
+	s.jobs.mu.Unlock()
+	s.recopies.update(name, func(st *domain.RecopyStatus) { st.JobID = j.info.ID })
+
+	// A re-copy cancelled while queued must still finish, or it would block every later one.
+	abort := func(err error) any {
+		s.finishRecopy(name, nil, fmt.Errorf("cancelled before it started: %w", err))
+		result, _ := s.recopies.get(name)
+		return result
+	}
+	s.runJob(ctx, j, s.acquireSyncJob, abort, func(ctx context.Context) (any, error) {
+		err := s.runRecopy(ctx, *sync, tables, timeout)
+		result, _ := s.recopies.get(name)
+		return result, err
+	})
+
+	snapshot, _ := s.recopies.get(name)
+	return &snapshot, nil
+}
+
+// GetRecopyStatus returns the status of the latest re-copy of a sync.
+func (s *Service) GetRecopyStatus(_ context.Context, name string) (*domain.RecopyStatus, error) {
+	status, ok := s.recopies.get(name)
+	if !ok {
+		return nil, fmt.Errorf("no re-copy has been requested for sync %s", name)
+	}
+	return &status, nil
+}
+
+func (s *Service) runRecopy(ctx context.Context, sync domain.Sync, tables []string, timeout int) error {
+	recopyLogger := s.logger.With("component", "recopy", "sync_name", sync.Name)
+	recopyLogger.Info("Starting on-demand re-copy", "tables", tables, "timeout", timeout)
+
+	var result *domain.SyncRunResult
+	var err error
+	if len(tables) == 0 {
+		result, err = s.recopyWholeSync(ctx, sync, timeout)
+	} else {
+		result, err = s.recopySelectedTables(ctx, sync, tables, timeout)
+	}
+
+	if err == nil && result.Outcome != domain.SyncRunDone {
+		err = fmt.Errorf("copy run finished with outcome %q", result.Outcome)
+	}
+
+	s.finishRecopy(sync.Name, result, err)
+	if err != nil {
+		recopyLogger.Error("Re-copy failed", "error", err)
+		return err
+	}
+	recopyLogger.Info("Re-copy completed", "rows_inserted", result.RowsInserted, "rows_deleted", result.RowsDeleted)
+	return nil
+}
+
+// finishRecopy records the end of the re-copy of a sync, which lets the next one begin.
+func (s *Service) finishRecopy(syncName string, result *domain.SyncRunResult, err error) {
+	s.recopies.update(syncName, func(st *domain.RecopyStatus) {
+		now := time.Now()
+		st.FinishedAt = &now
+		st.Result = result
+		if err != nil {
+			st.State = domain.RecopyStateFailed
+			st.Error = err.Error()
+			return
+		}
+		st.State = domain.RecopyStateCompleted
+		st.Step = "Copy finished"
+	})
+}
+
+func (s *Service) recopyWholeSync(ctx context.Context, sync domain.Sync, timeout int) (*domain.SyncRunResult, error) {
+	s.setRecopyStep(ctx, sync.Name, domain.RecopyStatePending, "Enabling onetimecopy")
+	if err := s.bucardo.SetSyncOnetimecopy(ctx, sync.Name, 1); err != nil {
+		return nil, err
+	}
+
+	s.setRecopyStep(ctx, sync.Name, domain.RecopyStateCopying, "Copying all tables")
+	result, kickErr := s.bucardo.KickSync(ctx, sync.Name, timeout)
+
+	// Always reset, even if the run failed or the job was cancelled, so a later restart does
+	// not copy everything again.
+	s.setRecopyStep(ctx, sync.Name, domain.RecopyStateResetting, "Resetting onetimecopy")
+	if err := s.bucardo.SetSyncOnetimecopy(context.WithoutCancel(ctx), sync.Name, 0); err != nil {
+		if kickErr != nil {
+			return result, kickErr
+		}
+		return result, fmt.Errorf("copy finished but onetimecopy could not be reset: %w", err)
+	}
+	return result, kickErr
+}
+
+func (s *Service) recopySelectedTables(ctx context.Context, sync domain.Sync, tables []string, timeout int) (*domain.SyncRunResult, error) {
+	config, err := s.config.LoadConfig(ctx)
+	if err != nil {
+		return nil, err
+	}
+	tempSync := recopySyncName(config, sync.Name)
+	dbgroupName, _ := syncDbgroup(config, sync)
+
+	s.setRecopyStep(ctx, sync.Name, domain.RecopyStatePending, "Creating temporary sync "+tempSync)
+	args := []string{
+		"add", "sync", tempSync,
+		fmt.Sprintf("dbs=%s", dbgroupName),
+		fmt.Sprintf("tables=%s", strings.Join(tables, ",")),
+		"onetimecopy=1", "autokick=0", "stayalive=0", "kidsalive=0",
+		fmt.Sprintf("status=%s", domain.SyncStatusInactive),
+	}
+	if sync.StrictChecking != nil {
+		args = append(args, fmt.Sprintf("strict_checking=%t", *sync.StrictChecking))
+	}
+	if sync.ConflictStrategy != "" {
+		args = append(args, fmt.Sprintf("conflict_strategy=%s", sync.ConflictStrategy))
+	}
+	if err := s.bucardo.ExecuteBucardoCommand(ctx, args...); err != nil {
+		return nil, fmt.Errorf("failed to create temporary sync %s: %w", tempSync, err)
+	}
+
+	// Whatever happens, the temporary sync must not survive; an orphan would also be
+	// removed by the next reconcile, but there is no reason to wait for that.
+	defer func() {
+		s.setRecopyStep(ctx, sync.Name, domain.RecopyStateResetting, "Removing temporary sync "+tempSync)
+		_, dbHost, dbUser, dbPass, dbPort := bucardoDBSettings()
+		if err := s.bucardo.RemoveSyncAndRelgroup(context.WithoutCancel(ctx), tempSync, tempSync, dbHost, dbUser, dbPass, dbPort); err != nil {
+			s.logger.Error("Failed to remove temporary re-copy sync", "component", "recopy", "sync_name", tempSync, "error", err)
+		}
+	}()
+
+	if err := s.bucardo.ActivateSync(ctx, tempSync); err != nil {
+		return nil, fmt.Errorf("failed to activate temporary sync %s: %w", tempSync, err)
+	}
+
+	s.setRecopyStep(ctx, sync.Name, domain.RecopyStateCopying, "Copying "+strings.Join(tables, ", "))
+	return s.bucardo.KickSync(ctx, tempSync, timeout)
+}
+
+func (s *Service) setRecopyStep(ctx context.Context, syncName, state, step string) {
+	s.recopies.update(syncName, func(st *domain.RecopyStatus) {
+		st.State = state
+		st.Step = step
+	})
+	s.nextStep(ctx, step)
+}
+
+// recopySyncName returns the name of the temporary sync that copies tables of a sync: its
+// name with a "_recopy" suffix, numbered when the configuration already has a sync of that
+// name, which the temporary one would otherwise replace and then remove.
+func recopySyncName(config *domain.BucardoConfig, syncName string) string {
+	taken := make(map[string]bool, len(config.Syncs))
+	for _, sync := range config.Syncs {
+		taken[sync.Name] = true
+	}
+	name := syncName + "_recopy"
+	for n := 2; taken[name]; n++ {
+		name = fmt.Sprintf("%s_recopy%d", syncName, n)
+	}
+	return name
+}
+
+// recopyTables normalises the requested table list and checks that every requested table
+// belongs to the sync; the tables of a herd sync are listed by Bucardo.
+func (s *Service) recopyTables(ctx context.Context, sync domain.Sync, requested []string) ([]string, error) {
+	if len(requested) == 0 {
+		return nil, nil
+	}
+
+	syncTables, err := s.syncTableList(ctx, sync)
+	if err != nil {
+		return nil, err
+	}
+	known := make(map[string]bool, len(syncTables))
+	for _, t := range syncTables {
+		known[t] = true
+	}
+
+	tables := make([]string, 0, len(requested))
+	for _, t := range requested {
+		t = strings.TrimSpace(t)
+		if t == "" {
+			continue
+		}
+		if !known[t] {
+			return nil, fmt.Errorf("table %s is not part of sync %s", t, sync.Name)
+		}
+		tables = append(tables, t)
+	}
+	return tables, nil
+}
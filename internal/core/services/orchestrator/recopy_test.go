@@ -0,0 +1,88 @@
+package orchestrator
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"replication-service/internal/core/domain"
+)
+
+func TestRecopyTables(t *testing.T) {
+	env := newTestEnv(t, &domain.BucardoConfig{
+		Databases: databases(1, 2),
+		Syncs: []domain.Sync{
+			{Name: "orders", Sources: refs(1), Targets: refs(2), Tables: "public.orders, public.items"},
+			{Name: "shop", Sources: refs(1), Targets: refs(2), Herd: "shop"},
+		},
+	})
+	env.bucardo.SourceTables["db1"] = []string{"public.customers", "public.orders"}
+	ctx := context.Background()
+	if err := env.service.ReloadAndRestart(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name      string
+		sync      string
+		requested []string
+		want      []string
+		wantErr   string
+	}{
+		{name: "whole sync", sync: "orders"},
+		{name: "listed tables", sync: "orders", requested: []string{" public.items", "", "public.orders"}, want: []string{"public.items", "public.orders"}},
+		{name: "table not listed", sync: "orders", requested: []string{"public.customers"}, wantErr: "table public.customers is not part of sync orders"},
+		{name: "herd tables", sync: "shop", requested: []string{"public.customers"}, want: []string{"public.customers"}},
+		{name: "table not in the herd", sync: "shop", requested: []string{"public.items"}, wantErr: "table public.items is not part of sync shop"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sync, err := env.service.GetSync(ctx, tt.sync)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := env.service.recopyTables(ctx, *sync, tt.requested)
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Fatalf("recopyTables() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("recopyTables() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRecopySyncNameAvoidsConfiguredSyncs(t *testing.T) {
+	env := newTestEnv(t, &domain.BucardoConfig{
+		Databases: databases(1, 2),
+		Syncs: []domain.Sync{
+			{Name: "orders", Sources: refs(1), Targets: refs(2), Tables: "public.orders"},
+			{Name: "orders_recopy", Sources: refs(1), Targets: refs(2), Tables: "public.items"},
+		},
+	})
+	ctx := context.Background()
+	if err := env.service.ReloadAndRestart(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	status, err := env.service.StartRecopy(ctx, "orders", domain.RecopyRequest{Tables: []string{"public.orders"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if j := waitForJob(t, env, status.JobID); j.State != domain.JobStateSucceeded {
+		t.Fatalf("re-copy = %s (%s), want it to succeed", j.State, j.Error)
+	}
+	if !env.bucardo.Ran("add sync orders_recopy2") || !env.bucardo.Ran("del sync orders_recopy2") {
+		t.Errorf("commands = %q, want the temporary sync orders_recopy2", env.bucardo.Commands)
+	}
+	// The configured sync of the plain name is left alone.
+	if _, ok := env.bucardo.Syncs["orders_recopy"]; !ok || env.bucardo.Ran("del sync orders_recopy") {
+		t.Errorf("commands = %q, want orders_recopy kept", env.bucardo.Commands)
+	}
+}
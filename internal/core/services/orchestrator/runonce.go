@@ -0,0 +1,116 @@
+package orchestrator
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"replication-service/internal/core/domain"
+)
+
+// Process exit codes reported through ExitError. Batch schedulers key off these.
+const (
+	ExitCodeSuccess         = 0
+	ExitCodeError           = 1 // Unexpected failure.
+	ExitCodeReconcileFailed = 2 // The configuration could not be applied to Bucardo.
+	ExitCodePartial         = 3 // Some run-once syncs completed, others did not.
+	ExitCodeTimeout         = 4 // No run-once sync completed before its timeout.
+	ExitCodeCancelled       = 5 // The container was stopped before every run-once sync finished.
+)
+
+// runSummaryFileEnv names the environment variable holding an optional path the run-once
+// summary is also written to.
+const runSummaryFileEnv = "BUCARDO_RUN_SUMMARY_FILE"
+
+// ExitError carries the process exit code a failed Run should end with.
+type ExitError struct {
+	Code int
+	Err  error
+}
+
+func (e *ExitError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *ExitError) Unwrap() error {
+	return e.Err
+}
+
+// runOnceTimeouts returns the run-once syncs of a configuration with their own timeouts in
+// seconds (0 when a sync has none).
+func runOnceTimeouts(config *domain.BucardoConfig) map[string]int {
+	timeouts := make(map[string]int)
+	for _, sync := range config.Syncs {
+		if sync.ExitOnComplete == nil || !*sync.ExitOnComplete {
+			continue
+		}
+		timeouts[sync.Name] = 0
+		if sync.ExitOnCompleteTimeout != nil {
+			timeouts[sync.Name] = *sync.ExitOnCompleteTimeout
+		}
+	}
+	return timeouts
+}
+
+// runOnce waits for the run-once syncs, publishes the summary and reports whether the
+// remaining syncs should keep running. A non-nil error means Run should end with it.
+func (s *Service) runOnce(ctx context.Context, config *domain.BucardoConfig, timeouts map[string]int, stopBucardoFunc func()) (keepRunning bool, err error) {
+	summary := s.monitor.MonitorSyncs(ctx, config, timeouts)
+
+	for i := range summary.Syncs {
+		result := &summary.Syncs[i]
+		if result.State != domain.RunOnceCompleted {
+			continue
+		}
+		status, err := s.bucardo.GetSyncStatus(context.Background(), result.SyncName)
+		if err != nil {
+			s.logger.Warn("Could not read row counts of run-once sync", "sync_name", result.SyncName, "error", err)
+			continue
+		}
+		result.RowsDeleted = status.RowsDeleted
+		result.RowsInserted = status.RowsInserted
+	}
+	s.writeRunSummary(summary)
+
+	switch summary.Outcome {
+	case domain.RunOnceOutcomeSuccess:
+		if len(timeouts) < len(config.Syncs) && ctx.Err() == nil {
+			s.logger.Info("Other syncs are still running. Switching to standard monitoring mode.")
+			return true, nil
+		}
+		s.logger.Info("All configured syncs were run-once. Shutting down container.")
+		stopBucardoFunc()
+		return false, nil
+	case domain.RunOnceOutcomeTimeout:
+		stopBucardoFunc()
+		return false, &ExitError{Code: ExitCodeTimeout, Err: fmt.Errorf("no run-once sync completed before its timeout")}
+	case domain.RunOnceOutcomeCancelled:
+		stopBucardoFunc()
+		return false, &ExitError{Code: ExitCodeCancelled, Err: fmt.Errorf("stopped before every run-once sync finished")}
+	default:
+		stopBucardoFunc()
+		return false, &ExitError{Code: ExitCodePartial, Err: fmt.Errorf("not every run-once sync completed")}
+	}
+}
+
+// writeRunSummary prints the run-once summary as a single JSON line on stdout and, when
+// BUCARDO_RUN_SUMMARY_FILE is set, writes it to that file as well.
+func (s *Service) writeRunSummary(summary *domain.RunOnceSummary) {
+	data, err := json.Marshal(summary)
+	if err != nil {
+		s.logger.Error("Failed to encode run-once summary", "error", err)
+		return
+	}
+	fmt.Fprintln(os.Stdout, string(data))
+
+	path := os.Getenv(runSummaryFileEnv)
+	if path == "" {
+		return
+	}
+	if err := os.WriteFile(path, append(data, '\n'), 0644); err != nil {
+		s.logger.Error("Failed to write run-once summary file", "path", path, "error", err)
+		return
+	}
+	s.logger.Info("Run-once summary written", "path", path, "outcome", summary.Outcome)
+}
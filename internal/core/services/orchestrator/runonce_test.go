@@ -0,0 +1,73 @@
+package orchestrator
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"replication-service/internal/core/domain"
+)
+
+func TestRunOnceExitCodes(t *testing.T) {
+	tests := []struct {
+		name        string
+		states      map[string]string
+		wantOutcome string
+		wantCode    int
+	}{
+		{"all completed", nil, domain.RunOnceOutcomeSuccess, ExitCodeSuccess},
+		{"one timed out", map[string]string{"mesh": domain.RunOnceTimedOut}, domain.RunOnceOutcomePartial, ExitCodePartial},
+		{"all timed out", map[string]string{"orders": domain.RunOnceTimedOut, "mesh": domain.RunOnceTimedOut}, domain.RunOnceOutcomeTimeout, ExitCodeTimeout},
+		{"stopped", map[string]string{"mesh": domain.RunOnceCancelled}, domain.RunOnceOutcomeCancelled, ExitCodeCancelled},
+		{"stopped after a timeout", map[string]string{"orders": domain.RunOnceTimedOut, "mesh": domain.RunOnceCancelled}, domain.RunOnceOutcomeCancelled, ExitCodeCancelled},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			summaryPath := filepath.Join(t.TempDir(), "summary.json")
+			t.Setenv(runSummaryFileEnv, summaryPath)
+
+			config := &domain.BucardoConfig{
+				Databases: databases(1, 2),
+				Syncs: []domain.Sync{
+					{Name: "orders", Sources: refs(1), Targets: refs(2), ExitOnComplete: boolPtr(true)},
+					{Name: "mesh", Sources: refs(2), Targets: refs(1), ExitOnComplete: boolPtr(true)},
+				},
+			}
+			env := newTestEnv(t, config)
+			for name, state := range tt.states {
+				env.monitor.States[name] = state
+			}
+			stopped := false
+			keepRunning, err := env.service.runOnce(context.Background(), config, runOnceTimeouts(config), func() { stopped = true })
+
+			if keepRunning || !stopped {
+				t.Errorf("keepRunning = %t, stopped = %t, want Bucardo stopped", keepRunning, stopped)
+			}
+			code := ExitCodeSuccess
+			var exitErr *ExitError
+			if errors.As(err, &exitErr) {
+				code = exitErr.Code
+			} else if err != nil {
+				t.Fatalf("runOnce() = %v, want an ExitError", err)
+			}
+			if code != tt.wantCode {
+				t.Errorf("exit code = %d, want %d", code, tt.wantCode)
+			}
+
+			data, err := os.ReadFile(summaryPath)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var summary domain.RunOnceSummary
+			if err := json.Unmarshal(data, &summary); err != nil {
+				t.Fatal(err)
+			}
+			if summary.Outcome != tt.wantOutcome {
+				t.Errorf("outcome = %q, want %q", summary.Outcome, tt.wantOutcome)
+			}
+		})
+	}
+}
@@ -3,6 +3,7 @@ package orchestrator
 import (
 	"context"
 	"crypto/sha1"
+	"errors"
 	"fmt"
 	"os"
 	"sort"
@@ -10,8 +11,15 @@ import (
 
 	"replication-service/internal/core/domain"
 	"replication-service/internal/core/ports"
+	"replication-service/internal/core/reqctx"
 )
 
+// ErrSyncNotFound is returned when a sync name is not present in the configuration.
+var ErrSyncNotFound = errors.New("sync not found")
+
+// ErrInvalidConfig is returned when a change would leave the configuration invalid.
+var ErrInvalidConfig = errors.New("invalid config")
+
 // Service is the core orchestrator for Bucardo replication.
 type Service struct {
 	logger         ports.Logger
@@ -19,11 +27,21 @@ type Service struct {
 	creds          ports.CredentialManager
 	bucardo        ports.BucardoExecutor
 	monitor        ports.Monitor
+	inspector      ports.DatabaseInspector
+	notifier       ports.Notifier
+	tracer         ports.Tracer
+	logLevels      ports.LogLevels
+	secrets        ports.SecretRegistry
 	configPath     string
 	pgpassPath     string
 	bucardoUser    string
 	bucardoCmd     string
 	bucardoLogPath string
+	recopies       *recopyTracker
+	verifications  *verifyTracker
+	deltas         *deltaStore
+	jobs           *jobManager
+	reconcileSlot  *reconcileSlot
 }
 
 // NewService creates a new orchestration service.
@@ -33,6 +51,11 @@ func NewService(
 	creds ports.CredentialManager,
 	bucardo ports.BucardoExecutor,
 	monitor ports.Monitor,
+	inspector ports.DatabaseInspector,
+	notifier ports.Notifier,
+	tracer ports.Tracer,
+	logLevels ports.LogLevels,
+	secrets ports.SecretRegistry,
 	configPath, pgpassPath, bucardoUser, bucardoCmd, bucardoLogPath string,
 ) *Service {
 	return &Service{
@@ -41,52 +64,54 @@ func NewService(
 		creds:          creds,
 		bucardo:        bucardo,
 		monitor:        monitor,
+		inspector:      inspector,
+		notifier:       notifier,
+		tracer:         tracer,
+		logLevels:      logLevels,
+		secrets:        secrets,
 		configPath:     configPath,
 		pgpassPath:     pgpassPath,
 		bucardoUser:    bucardoUser,
 		bucardoCmd:     bucardoCmd,
 		bucardoLogPath: bucardoLogPath,
+		recopies:       newRecopyTracker(),
+		verifications:  newVerifyTracker(),
+		deltas:         newDeltaStore(),
+		jobs:           newJobManager(),
+		reconcileSlot:  newReconcileSlot(),
 	}
 }
 
-// Run starts the main application logic.
+// Run starts the main application logic. Failures that should end the process with a
+// specific exit code are returned as *ExitError.
 func (s *Service) Run(ctx context.Context) error {
+	// Commands run on behalf of the container itself are audited under one startup ID.
+	ctx = reqctx.WithCorrelationID(ctx, "startup-"+newRunID())
 	if err := s.ReloadAndRestart(ctx); err != nil {
-		return err
+		return &ExitError{Code: ExitCodeReconcileFailed, Err: err}
 	}
 
+	go s.runDeltaCollector(ctx)
+
 	// Monitor logic
 	config, err := s.config.LoadConfig(ctx)
 	if err != nil {
 		return err
 	}
 
-	runOnceSyncs := make(map[string]bool)
-	var maxTimeout *int
-
-	for _, sync := range config.Syncs {
-		if sync.ExitOnComplete != nil && *sync.ExitOnComplete {
-			runOnceSyncs[sync.Name] = true
-			if sync.ExitOnCompleteTimeout != nil {
-				if maxTimeout == nil || *sync.ExitOnCompleteTimeout > *maxTimeout {
-					timeoutVal := *sync.ExitOnCompleteTimeout
-					maxTimeout = &timeoutVal
-				}
-			}
-		}
-	}
-
 	stopBucardoFunc := func() {
 		if err := s.bucardo.StopBucardo(context.Background()); err != nil {
 			s.logger.Error("Failed to stop Bucardo", "error", err)
 		}
 	}
 
-	if len(runOnceSyncs) > 0 {
-		s.monitor.MonitorSyncs(ctx, config, runOnceSyncs, maxTimeout, stopBucardoFunc)
-	} else {
-		s.monitor.MonitorBucardo(ctx, stopBucardoFunc)
+	if timeouts := runOnceTimeouts(config); len(timeouts) > 0 {
+		keepRunning, err := s.runOnce(ctx, config, timeouts, stopBucardoFunc)
+		if !keepRunning {
+			return err
+		}
 	}
+	s.monitor.MonitorBucardo(ctx, stopBucardoFunc)
 
 	return nil
 }
@@ -97,9 +122,11 @@ func (s *Service) GetConfig(ctx context.Context) (*domain.BucardoConfig, error)
 
 func (s *Service) UpdateConfig(ctx context.Context, config *domain.BucardoConfig) error {
 	// Validate before saving
-	if errs := s.validateConfig(config); len(errs) > 0 {
-		return fmt.Errorf("invalid config: %v", errs)
+	if errs := s.validateConfigChange(config); len(errs) > 0 {
+		return fmt.Errorf("%w: %v", ErrInvalidConfig, errs)
 	}
+	s.logConfigWarnings(ctx, config)
+	s.registerSecrets(config)
 	return s.config.SaveConfig(ctx, config)
 }
 
@@ -111,39 +138,79 @@ func (s *Service) StopBucardoProcess(ctx context.Context) error {
 	return s.bucardo.StopBucardo(ctx)
 }
 
+// ReloadAndRestart reconciles Bucardo with the configuration and restarts it, waiting for
+// any reconcile already in progress to finish first.
 func (s *Service) ReloadAndRestart(ctx context.Context) error {
-	s.logger.Info("Reloading and restarting application...")
+	release, err := s.acquireReconcile(ctx)
+	if err != nil {
+		return err
+	}
+	defer release()
+	return s.reconcile(ctx)
+}
 
+// reconcile does the work of ReloadAndRestart. Callers must hold the reconcile slot.
+func (s *Service) reconcile(ctx context.Context) (err error) {
+	ctx, endTrace := s.startPhases(ctx, "reconcile", "correlation_id", reqctx.CorrelationID(ctx))
+	defer func() { endTrace(err) }()
+	// logger binds the current ctx, so lines carry the span of the phase they were logged in.
+	logger := func() ports.Logger { return s.logger.WithContext(ctx) }
+
+	logger().Info("Reloading and restarting application...", "correlation_id", reqctx.CorrelationID(ctx))
+	s.notifier.Notify(ctx, domain.Event{
+		Type:     domain.EventReconcileStarted,
+		Severity: domain.SeverityInfo,
+		Message:  "Reconciling Bucardo with the configuration",
+	})
+	defer func() {
+		if err != nil {
+			s.notifier.Notify(ctx, domain.Event{
+				Type:     domain.EventReconcileFailed,
+				Severity: domain.SeverityError,
+				Message:  "Reconcile failed: " + err.Error(),
+			})
+			return
+		}
+		s.notifier.Notify(ctx, domain.Event{
+			Type:     domain.EventReconcileFinished,
+			Severity: domain.SeverityInfo,
+			Message:  "Reconcile finished and Bucardo started",
+		})
+	}()
+
+	ctx = s.nextPhase(ctx, "Load configuration")
 	if _, err := os.Stat(s.configPath); os.IsNotExist(err) {
-		s.logger.Error("Configuration file not found.", "path", s.configPath)
+		logger().Error("Configuration file not found.", "path", s.configPath)
 		return err
 	}
 
 	config, err := s.config.LoadConfig(ctx)
 	if err != nil {
-		s.logger.Error("Failed to load configuration", "error", err)
+		logger().Error("Failed to load configuration", "error", err)
 		return err
 	}
 
 	if validationErrors := s.validateConfig(config); len(validationErrors) > 0 {
-		s.logger.Error("Invalid configuration found in bucardo.json")
+		logger().Error("Invalid configuration found in bucardo.json")
 		for _, e := range validationErrors {
-			s.logger.Error(e.Error())
+			logger().Error(e.Error())
 		}
 		return fmt.Errorf("configuration validation failed")
 	}
+	s.logConfigWarnings(ctx, config)
+
+	s.registerSecrets(config)
+	if err := s.setServiceLogLevel(config); err != nil {
+		logger().Warn("Failed to set service_log", "error", err)
+	}
 
 	// Stop Bucardo before making changes (safe mode)
+	ctx = s.nextPhase(ctx, "Stop Bucardo")
 	s.bucardo.StopBucardo(ctx)
 
 	// Load Env Vars
-	dbName := getEnv("BUCARDO_DB_NAME", "bucardo")
-	dbHost := getEnv("BUCARDO_DB_HOST", "postgres")
-	dbUser := getEnv("BUCARDO_DB_USER", "postgres")
-	dbPass := getEnv("BUCARDO_DB_PASS", "changeme")
-	dbPortStr := getEnv("BUCARDO_DB_PORT", "5432")
-	var dbPort int
-	fmt.Sscanf(dbPortStr, "%d", &dbPort)
+	dbName, dbHost, dbUser, dbPass, dbPort := bucardoDBSettings()
+	s.secrets.AddSecret(dbPass)
 
 	// Setup .pgpass
 	systemDB := domain.Database{
@@ -164,51 +231,64 @@ func (s *Service) ReloadAndRestart(ctx context.Context) error {
 	}
 	allDBsForPass := append([]domain.Database{systemDB, superuserDB}, config.Databases...)
 
+	ctx = s.nextPhase(ctx, "Set up credentials")
 	if err := s.creds.SetupPgpass(ctx, allDBsForPass); err != nil {
-		s.logger.Error("Failed to setup .pgpass file", "error", err)
+		logger().Error("Failed to setup .pgpass file", "error", err)
 		return err
 	}
 	defer s.creds.CleanupPgpass(ctx)
 
 	// Ensure Bucardo User Password
 	if err := s.bucardo.EnsureBucardoUserPassword(ctx, dbHost, dbUser, dbPass, dbName, dbPass, dbPort); err != nil {
-		s.logger.Warn("Failed to ensure bucardo user password", "error", err)
+		logger().Warn("Failed to ensure bucardo user password", "error", err)
 	}
 
 	// Install/Ensure Bucardo
+	ctx = s.nextPhase(ctx, "Install Bucardo")
 	if err := s.bucardo.InstallBucardo(ctx, dbName, dbHost, dbUser, dbPass); err != nil {
-		s.logger.Error("Failed to install Bucardo schema", "error", err)
+		logger().Error("Failed to install Bucardo schema", "error", err)
 		return err
 	}
 
 	if err := s.setLogLevel(ctx, config); err != nil {
-		s.logger.Warn("Failed to set log_level", "error", err)
+		logger().Warn("Failed to set log_level", "error", err)
 	}
 
+	ctx = s.nextPhase(ctx, "Rename databases")
+	if err := s.renameDatabases(ctx, config, dbHost, dbUser, dbPass, dbPort); err != nil {
+		// Removing the old names as orphans would drop the syncs that use them.
+		logger().Error("Failed to rename databases", "error", err)
+		return err
+	}
+
+	ctx = s.nextPhase(ctx, "Remove orphaned databases and syncs")
 	if err := s.removeOrphanedDbs(ctx, config); err != nil {
-		s.logger.Error("Failed to remove orphaned databases", "error", err)
+		logger().Error("Failed to remove orphaned databases", "error", err)
 	}
 
 	if err := s.removeOrphanedSyncs(ctx, config, dbHost, dbUser, dbPass, dbPort); err != nil {
-		s.logger.Error("Failed to remove orphaned syncs", "error", err)
+		logger().Error("Failed to remove orphaned syncs", "error", err)
 	}
 
+	ctx = s.nextPhase(ctx, "Reconcile databases")
 	if err := s.addDatabasesToBucardo(ctx, config); err != nil {
-		s.logger.Error("Failed to reconcile databases", "error", err)
+		logger().Error("Failed to reconcile databases", "error", err)
 		return err
 	}
 
+	ctx = s.nextPhase(ctx, "Reconcile syncs")
 	if err := s.addSyncsToBucardo(ctx, config, dbHost, dbUser, dbPass, dbPort); err != nil {
-		s.logger.Error("Failed to reconcile syncs", "error", err)
+		logger().Error("Failed to reconcile syncs", "error", err)
 		return err
 	}
 
+	ctx = s.nextPhase(ctx, "Start Bucardo")
 	if err := s.bucardo.StartBucardo(ctx); err != nil {
-		s.logger.Error("Failed to start bucardo", "error", err)
+		logger().Error("Failed to start bucardo", "error", err)
 		return err
 	}
 
-	s.logger.Info("Reload and restart complete.")
+	logger().Info("Reload and restart complete.")
 	return nil
 }
 
@@ -230,7 +310,7 @@ func (s *Service) GetSync(ctx context.Context, name string) (*domain.Sync, error
 			return &sync, nil
 		}
 	}
-	return nil, fmt.Errorf("sync not found: %s", name)
+	return nil, fmt.Errorf("%w: %s", ErrSyncNotFound, name)
 }
 
 func (s *Service) AddSync(ctx context.Context, sync domain.Sync) error {
@@ -240,7 +320,7 @@ func (s *Service) AddSync(ctx context.Context, sync domain.Sync) error {
 	}
 	for _, existing := range config.Syncs {
 		if existing.Name == sync.Name {
-			return fmt.Errorf("sync already exists: %s", sync.Name)
+			return fmt.Errorf("%w: sync already exists: %s", ErrInvalidConfig, sync.Name)
 		}
 	}
 	config.Syncs = append(config.Syncs, sync)
@@ -257,13 +337,18 @@ func (s *Service) UpdateSync(ctx context.Context, name string, updated domain.Sy
 		if sync.Name == name {
 			// Enforce the name from the path/identifier to ensure consistency
 			updated.Name = name
+			// A body without a status keeps the current one, so that editing a paused sync
+			// does not resume it.
+			if updated.Status == "" {
+				updated.Status = sync.Status
+			}
 			config.Syncs[i] = updated
 			found = true
 			break
 		}
 	}
 	if !found {
-		return fmt.Errorf("sync not found: %s", name)
+		return fmt.Errorf("%w: %s", ErrSyncNotFound, name)
 	}
 	return s.UpdateConfig(ctx, config)
 }
@@ -283,25 +368,82 @@ func (s *Service) DeleteSync(ctx context.Context, name string) error {
 		newSyncs = append(newSyncs, sync)
 	}
 	if !found {
-		return fmt.Errorf("sync not found: %s", name)
+		return fmt.Errorf("%w: %s", ErrSyncNotFound, name)
 	}
 	config.Syncs = newSyncs
 	return s.UpdateConfig(ctx, config)
 }
 
+// KickSync triggers an immediate run of a configured sync. When wait is greater than zero
+// the call blocks for up to wait seconds and reports the run's outcome and row counts.
+func (s *Service) KickSync(ctx context.Context, name string, wait int) (*domain.SyncRunResult, error) {
+	if _, err := s.GetSync(ctx, name); err != nil {
+		return nil, err
+	}
+	return s.bucardo.KickSync(ctx, name, wait)
+}
+
+// GetSyncStatus reports the current state of a configured sync and the row counts of its
+// last run, as shown by `bucardo status`.
+func (s *Service) GetSyncStatus(ctx context.Context, name string) (*domain.SyncRunResult, error) {
+	if _, err := s.GetSync(ctx, name); err != nil {
+		return nil, err
+	}
+	return s.bucardo.GetSyncStatus(ctx, name)
+}
+
+// PauseSync deactivates a sync in Bucardo and records it as inactive in the configuration
+// so that a later reconcile keeps it paused.
+func (s *Service) PauseSync(ctx context.Context, name string) error {
+	return s.setSyncStatus(ctx, name, domain.SyncStatusInactive)
+}
+
+// ResumeSync reactivates a previously paused sync.
+func (s *Service) ResumeSync(ctx context.Context, name string) error {
+	return s.setSyncStatus(ctx, name, domain.SyncStatusActive)
+}
+
+func (s *Service) setSyncStatus(ctx context.Context, name, status string) error {
+	sync, err := s.GetSync(ctx, name)
+	if err != nil {
+		return err
+	}
+
+	// Persist first: if Bucardo is unreachable the desired state still survives a restart.
+	sync.Status = status
+	if err := s.UpdateSync(ctx, name, *sync); err != nil {
+		return err
+	}
+
+	s.logger.Info("Changing sync status", "component", "sync_control", "sync_name", name, "status", status)
+	if status == domain.SyncStatusInactive {
+		err = s.bucardo.DeactivateSync(ctx, name)
+	} else {
+		err = s.bucardo.ActivateSync(ctx, name)
+	}
+	if err != nil {
+		return fmt.Errorf("sync %s saved as %s but Bucardo could not apply it (it will be applied on the next restart): %w", name, status, err)
+	}
+	// A paused sync stops replicating, which is worth a warning; resuming it is not.
+	severity := domain.SeverityInfo
+	if status == domain.SyncStatusInactive {
+		severity = domain.SeverityWarning
+	}
+	s.notifier.Notify(ctx, domain.Event{
+		Type:     domain.EventSyncStatusChanged,
+		Severity: severity,
+		SyncName: name,
+		Message:  fmt.Sprintf("Sync %s is now %s", name, status),
+		Details:  map[string]any{"status": status},
+	})
+	return nil
+}
+
 // validateConfig performs a pre-check of the configuration to catch common errors.
 func (s *Service) validateConfig(config *domain.BucardoConfig) []error {
-	var errors []error
-	dbIDs := make(map[int]bool)
+	errors := validateDatabases(config.Databases)
 	syncNames := make(map[string]bool)
 
-	for _, db := range config.Databases {
-		if dbIDs[db.ID] {
-			errors = append(errors, fmt.Errorf("database ID %d is duplicated", db.ID))
-		}
-		dbIDs[db.ID] = true
-	}
-
 	for _, sync := range config.Syncs {
 		if sync.Name == "" {
 			errors = append(errors, fmt.Errorf("a sync is missing the required 'name' property"))
@@ -314,11 +456,11 @@ func (s *Service) validateConfig(config *domain.BucardoConfig) []error {
 
 		if len(sync.Bidirectional) > 0 {
 			if len(sync.Bidirectional) < 2 {
-				errors = append(errors, fmt.Errorf("sync '%s': 'bidirectional' requires at least two database IDs", sync.Name))
+				errors = append(errors, fmt.Errorf("sync '%s': 'bidirectional' requires at least two databases", sync.Name))
 			}
-			for _, id := range sync.Bidirectional {
-				if !dbIDs[id] {
-					errors = append(errors, fmt.Errorf("sync '%s': 'bidirectional' database ID %d is not defined in the 'databases' list", sync.Name, id))
+			for _, ref := range sync.Bidirectional {
+				if _, ok := config.FindDatabase(ref); !ok {
+					errors = append(errors, fmt.Errorf("sync '%s': 'bidirectional' %s is not defined in the 'databases' list", sync.Name, describeRef(ref)))
 				}
 			}
 			if sync.ConflictStrategy == "bucardo_source" || sync.ConflictStrategy == "bucardo_target" {
@@ -331,11 +473,25 @@ func (s *Service) validateConfig(config *domain.BucardoConfig) []error {
 			if len(sync.Targets) == 0 {
 				errors = append(errors, fmt.Errorf("sync '%s': must have at least one target", sync.Name))
 			}
+			for _, role := range []struct {
+				name string
+				refs []domain.DBRef
+			}{{"source", sync.Sources}, {"target", sync.Targets}} {
+				for _, ref := range role.refs {
+					if _, ok := config.FindDatabase(ref); !ok {
+						errors = append(errors, fmt.Errorf("sync '%s': %s %s is not defined in the 'databases' list", sync.Name, role.name, describeRef(ref)))
+					}
+				}
+			}
 			if sync.Herd == "" && sync.Tables == "" {
 				errors = append(errors, fmt.Errorf("sync '%s': must define either 'herd' or 'tables'", sync.Name))
 			}
 		}
 
+		if sync.Status != "" && sync.Status != domain.SyncStatusActive && sync.Status != domain.SyncStatusInactive {
+			errors = append(errors, fmt.Errorf("sync '%s': invalid status '%s'. Must be '%s' or '%s'", sync.Name, sync.Status, domain.SyncStatusActive, domain.SyncStatusInactive))
+		}
+
 		if sync.ConflictStrategy != "" {
 			validStrategies := map[string]bool{
 				"bucardo_source": true, "bucardo_target": true, "bucardo_skip": true,
@@ -350,9 +506,52 @@ func (s *Service) validateConfig(config *domain.BucardoConfig) []error {
 			}
 		}
 	}
+
+	channelNames := make(map[string]bool)
+	for _, channel := range config.Notifications {
+		if channel.Name == "" {
+			errors = append(errors, fmt.Errorf("a notification channel is missing the required 'name' property"))
+			continue
+		}
+		if channelNames[channel.Name] {
+			errors = append(errors, fmt.Errorf("notification channel name '%s' is duplicated", channel.Name))
+		}
+		channelNames[channel.Name] = true
+
+		switch channel.Type {
+		case "webhook", "slack":
+			if channel.URL == "" {
+				errors = append(errors, fmt.Errorf("notification channel '%s': 'url' is required for type '%s'", channel.Name, channel.Type))
+			}
+		case "email":
+			if channel.SMTPHost == "" || channel.From == "" || len(channel.To) == 0 {
+				errors = append(errors, fmt.Errorf("notification channel '%s': 'smtp_host', 'from' and 'to' are required for type 'email'", channel.Name))
+			}
+		default:
+			errors = append(errors, fmt.Errorf("notification channel '%s': invalid type '%s'. Must be 'webhook', 'slack' or 'email'", channel.Name, channel.Type))
+		}
+		switch channel.MinSeverity {
+		case "", domain.SeverityInfo, domain.SeverityWarning, domain.SeverityError:
+		default:
+			errors = append(errors, fmt.Errorf("notification channel '%s': invalid min_severity '%s'", channel.Name, channel.MinSeverity))
+		}
+	}
+
+	if config.ServiceLog != nil {
+		errors = append(errors, validateServiceLog(config.ServiceLog, false)...)
+	}
 	return errors
 }
 
+// validateConfigChange checks a configuration before it is saved. On top of validateConfig
+// it rejects topology errors, which a reconcile only logs so that a configuration written
+// before they were checked still starts.
+func (s *Service) validateConfigChange(config *domain.BucardoConfig) []error {
+	errs := s.validateConfig(config)
+	topologyErrors, _ := validateTopology(config)
+	return append(errs, topologyErrors...)
+}
+
 func (s *Service) setLogLevel(ctx context.Context, config *domain.BucardoConfig) error {
 	if config.LogLevel != "" {
 		s.logger.Info("Setting Bucardo global log level", "component", "config", "level", config.LogLevel)
@@ -362,12 +561,12 @@ func (s *Service) setLogLevel(ctx context.Context, config *domain.BucardoConfig)
 }
 
 func (s *Service) removeOrphanedDbs(ctx context.Context, config *domain.BucardoConfig) error {
-	appLogger := s.logger.With("component", "cleanup")
+	appLogger := s.logger.WithContext(ctx).With("component", "cleanup")
 	appLogger.Info("Checking for orphaned databases to remove")
 
 	configDbs := make(map[string]bool)
 	for _, db := range config.Databases {
-		configDbs[fmt.Sprintf("db%d", db.ID)] = true
+		configDbs[db.BucardoName()] = true
 	}
 
 	bucardoDbs, err := s.bucardo.ListDatabases(ctx)
@@ -387,7 +586,7 @@ func (s *Service) removeOrphanedDbs(ctx context.Context, config *domain.BucardoC
 }
 
 func (s *Service) removeOrphanedSyncs(ctx context.Context, config *domain.BucardoConfig, dbHost, dbUser, dbPass string, dbPort int) error {
-	appLogger := s.logger.With("component", "cleanup")
+	appLogger := s.logger.WithContext(ctx).With("component", "cleanup")
 	appLogger.Info("Checking for orphaned syncs to remove")
 
 	configSyncs := make(map[string]bool)
@@ -421,24 +620,49 @@ func (s *Service) removeOrphanedSyncs(ctx context.Context, config *domain.Bucard
 	return nil
 }
 
-func getDbPassword(db domain.Database) (string, error) {
+// getDbPassword resolves the password of a database and registers it as a secret, so that
+// it is scrubbed from the logs.
+func (s *Service) getDbPassword(db domain.Database) (string, error) {
+	password := db.Pass
 	if db.Pass == "env" {
-		envVar := fmt.Sprintf("BUCARDO_DB%d", db.ID)
-		password := os.Getenv(envVar)
+		envVars := db.PasswordEnvVars()
+		password = ""
+		for _, envVar := range envVars {
+			if password = os.Getenv(envVar); password != "" {
+				break
+			}
+		}
 		if password == "" {
-			return "", fmt.Errorf("environment variable %s not set for db id %d", envVar, db.ID)
+			return "", fmt.Errorf("environment variable %s not set for db %s", strings.Join(envVars, " or "), db.BucardoName())
+		}
+	}
+	s.secrets.AddSecret(password)
+	return password, nil
+}
+
+// registerSecrets registers the passwords and notification secrets written in the
+// configuration. Values read from the environment are registered at startup.
+func (s *Service) registerSecrets(config *domain.BucardoConfig) {
+	for _, db := range config.Databases {
+		if db.Pass != "env" {
+			s.secrets.AddSecret(db.Pass)
+		}
+	}
+	for _, channel := range config.Notifications {
+		for _, secret := range []string{channel.Secret, channel.SMTPPass} {
+			if secret != "env" {
+				s.secrets.AddSecret(secret)
+			}
 		}
-		return password, nil
 	}
-	return db.Pass, nil
 }
 
 func (s *Service) addDatabasesToBucardo(ctx context.Context, config *domain.BucardoConfig) error {
-	appLogger := s.logger.With("component", "db_reconciler")
+	appLogger := s.logger.WithContext(ctx).With("component", "db_reconciler")
 	appLogger.Info("Starting database reconciliation")
 
 	for _, db := range config.Databases {
-		dbName := fmt.Sprintf("db%d", db.ID)
+		dbName := db.BucardoName()
 		dbLogger := appLogger.With("db_name", dbName, "db_id", db.ID, "db_host", db.Host)
 
 		exists, err := s.bucardo.DatabaseExists(ctx, dbName)
@@ -446,9 +670,9 @@ func (s *Service) addDatabasesToBucardo(ctx context.Context, config *domain.Buca
 			return fmt.Errorf("could not check if database exists %s: %w", dbName, err)
 		}
 
-		password, err := getDbPassword(db)
+		password, err := s.getDbPassword(db)
 		if err != nil {
-			return fmt.Errorf("error getting password for db %d: %w", db.ID, err)
+			return fmt.Errorf("error getting password for db %s: %w", dbName, err)
 		}
 
 		var args []string
@@ -484,7 +708,7 @@ func (s *Service) addDatabasesToBucardo(ctx context.Context, config *domain.Buca
 }
 
 func (s *Service) addSyncsToBucardo(ctx context.Context, config *domain.BucardoConfig, dbHost, dbUser, dbPass string, dbPort int) error {
-	appLogger := s.logger.With("component", "sync_reconciler")
+	appLogger := s.logger.WithContext(ctx).With("component", "sync_reconciler")
 	appLogger.Info("Starting sync reconciliation")
 
 	for _, sync := range config.Syncs {
@@ -533,6 +757,7 @@ func (s *Service) addSyncsToBucardo(ctx context.Context, config *domain.BucardoC
 				if sync.ConflictStrategy != "" {
 					updateArgs = append(updateArgs, fmt.Sprintf("conflict_strategy=%s", sync.ConflictStrategy))
 				}
+				updateArgs = append(updateArgs, fmt.Sprintf("status=%s", bucardoSyncStatus(sync)))
 				if err := s.bucardo.ExecuteBucardoCommand(ctx, updateArgs...); err != nil {
 					return fmt.Errorf("failed to update sync %s: %w", sync.Name, err)
 				}
@@ -543,39 +768,13 @@ func (s *Service) addSyncsToBucardo(ctx context.Context, config *domain.BucardoC
 		syncLogger.Info("Preparing to add sync.")
 		args := []string{"add", "sync", sync.Name, fmt.Sprintf("onetimecopy=%d", sync.Onetimecopy)}
 
-		if len(sync.Bidirectional) > 0 {
-			dbgroupName := fmt.Sprintf("bg_%s", sync.Name)
-			dbgroupMembers := make([]string, len(sync.Bidirectional))
-			for i, dbID := range sync.Bidirectional {
-				dbgroupMembers[i] = fmt.Sprintf("db%d:source", dbID)
-			}
-			s.bucardo.ExecuteBucardoCommand(ctx, "del", "dbgroup", dbgroupName)
-			s.bucardo.ExecuteBucardoCommand(ctx, append([]string{"add", "dbgroup", dbgroupName}, dbgroupMembers...)...)
-			args = append(args, fmt.Sprintf("dbs=%s", dbgroupName))
-		} else {
-			var dbStrings []string
-			var memberNames []string
-			for _, sourceID := range sync.Sources {
-				member := fmt.Sprintf("db%d:source", sourceID)
-				dbStrings = append(dbStrings, member)
-				memberNames = append(memberNames, member)
-			}
-			for _, targetID := range sync.Targets {
-				member := fmt.Sprintf("db%d:target", targetID)
-				dbStrings = append(dbStrings, member)
-				memberNames = append(memberNames, member)
-			}
-			sort.Strings(memberNames)
-			hash := sha1.Sum([]byte(strings.Join(memberNames, ",")))
-			dbgroupName := fmt.Sprintf("sg_%s_%x", sync.Name, hash[:4])
-
-			s.bucardo.ExecuteBucardoCommand(ctx, "del", "dbgroup", dbgroupName)
-			s.bucardo.ExecuteBucardoCommand(ctx, append([]string{"add", "dbgroup", dbgroupName}, dbStrings...)...)
-			args = append(args, fmt.Sprintf("dbs=%s", dbgroupName))
-		}
+		dbgroupName, dbgroupMembers := syncDbgroup(config, sync)
+		s.bucardo.ExecuteBucardoCommand(ctx, "del", "dbgroup", dbgroupName)
+		s.bucardo.ExecuteBucardoCommand(ctx, append([]string{"add", "dbgroup", dbgroupName}, dbgroupMembers...)...)
+		args = append(args, fmt.Sprintf("dbs=%s", dbgroupName))
 
 		if sync.Herd != "" {
-			sourceDB := fmt.Sprintf("db%d", sync.Sources[0])
+			sourceDB := bucardoDbName(config, sync.Sources[0])
 			s.bucardo.ExecuteBucardoCommand(ctx, "del", "herd", sync.Herd, "--force")
 			s.bucardo.ExecuteBucardoCommand(ctx, "add", "herd", sync.Herd)
 			s.bucardo.ExecuteBucardoCommand(ctx, "add", "all", "tables", fmt.Sprintf("--herd=%s", sync.Herd), fmt.Sprintf("db=%s", sourceDB))
@@ -593,6 +792,10 @@ func (s *Service) addSyncsToBucardo(ctx context.Context, config *domain.BucardoC
 		if sync.ConflictStrategy != "" {
 			args = append(args, fmt.Sprintf("conflict_strategy=%s", sync.ConflictStrategy))
 		}
+		if sync.IsPaused() {
+			syncLogger.Info("Sync is paused in configuration, adding it as inactive.")
+			args = append(args, fmt.Sprintf("status=%s", domain.SyncStatusInactive))
+		}
 
 		if err := s.bucardo.ExecuteBucardoCommand(ctx, args...); err != nil {
 			return fmt.Errorf("failed to add sync %s: %w", sync.Name, err)
@@ -601,6 +804,62 @@ func (s *Service) addSyncsToBucardo(ctx context.Context, config *domain.BucardoC
 	return nil
 }
 
+// syncDbgroup returns the name and members of the Bucardo dbgroup backing a sync.
+// One-way syncs get a name derived from a hash of their members so that changing
+// sources or targets produces a fresh dbgroup. Members with an ID are hashed as "db<ID>",
+// so giving them a name keeps the dbgroup of existing syncs.
+func syncDbgroup(config *domain.BucardoConfig, sync domain.Sync) (string, []string) {
+	if len(sync.Bidirectional) > 0 {
+		members := make([]string, len(sync.Bidirectional))
+		for i, ref := range sync.Bidirectional {
+			members[i] = bucardoDbName(config, ref) + ":source"
+		}
+		return fmt.Sprintf("bg_%s", sync.Name), members
+	}
+
+	var members, hashed []string
+	for _, role := range []struct {
+		name string
+		refs []domain.DBRef
+	}{{"source", sync.Sources}, {"target", sync.Targets}} {
+		for _, ref := range role.refs {
+			members = append(members, bucardoDbName(config, ref)+":"+role.name)
+			hashed = append(hashed, dbgroupHashName(config, ref)+":"+role.name)
+		}
+	}
+	sort.Strings(hashed)
+	hash := sha1.Sum([]byte(strings.Join(hashed, ",")))
+	return fmt.Sprintf("sg_%s_%x", sync.Name, hash[:4]), members
+}
+
+// dbgroupHashName returns the name a database has in the hash of a dbgroup name: "db<ID>"
+// for a database with an ID, whether or not it has a name, and its name otherwise.
+func dbgroupHashName(config *domain.BucardoConfig, ref domain.DBRef) string {
+	if db, ok := config.FindDatabase(ref); ok && (db.Name == "" || db.ID != 0) {
+		return domain.LegacyBucardoName(db.ID)
+	}
+	return bucardoDbName(config, ref)
+}
+
+// bucardoSyncStatus returns the Bucardo status a sync should have according to the configuration.
+func bucardoSyncStatus(sync domain.Sync) string {
+	if sync.IsPaused() {
+		return domain.SyncStatusInactive
+	}
+	return domain.SyncStatusActive
+}
+
+// bucardoDBSettings reads the connection settings of Bucardo's own database from the environment.
+func bucardoDBSettings() (dbName, dbHost, dbUser, dbPass string, dbPort int) {
+	dbName = getEnv("BUCARDO_DB_NAME", "bucardo")
+	dbHost = getEnv("BUCARDO_DB_HOST", "postgres")
+	dbUser = getEnv("BUCARDO_DB_USER", "postgres")
+	dbPass = getEnv("BUCARDO_DB_PASS", "changeme")
+	dbPortStr := getEnv("BUCARDO_DB_PORT", "5432")
+	fmt.Sscanf(dbPortStr, "%d", &dbPort)
+	return
+}
+
 func getEnv(key, fallback string) string {
 	if value, ok := os.LookupEnv(key); ok {
 		return value
@@ -0,0 +1,586 @@
+package orchestrator
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"replication-service/internal/adapters/fake"
+	"replication-service/internal/adapters/logger"
+	"replication-service/internal/adapters/tracing"
+	"replication-service/internal/core/domain"
+)
+
+// testEnv is a Service wired to in-memory adapters.
+type testEnv struct {
+	service   *Service
+	bucardo   *fake.BucardoExecutor
+	config    *fake.ConfigProvider
+	creds     *fake.CredentialManager
+	monitor   *fake.Monitor
+	inspector *fake.Inspector
+	notifier  *fake.Notifier
+}
+
+func newTestEnv(t *testing.T, config *domain.BucardoConfig) *testEnv {
+	t.Helper()
+	// reconcile checks that the configuration file exists before loading it.
+	configPath := filepath.Join(t.TempDir(), "bucardo.json")
+	if err := os.WriteFile(configPath, []byte("{}"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	env := &testEnv{
+		bucardo:   fake.NewBucardoExecutor(),
+		config:    fake.NewConfigProvider(config),
+		creds:     fake.NewCredentialManager(),
+		monitor:   fake.NewMonitor(),
+		inspector: fake.NewInspector(),
+		notifier:  fake.NewNotifier(),
+	}
+	env.service = NewService(
+		logger.NewSlogAdapter(slog.New(slog.NewTextHandler(io.Discard, nil))),
+		env.config,
+		env.creds,
+		env.bucardo,
+		env.monitor,
+		env.inspector,
+		env.notifier,
+		tracing.NewOTelTracer(),
+		logger.NewLevelController(slog.LevelInfo),
+		logger.NewRedactor(),
+		configPath, "", "bucardo", "bucardo", "",
+	)
+	return env
+}
+
+func databases(ids ...int) []domain.Database {
+	dbs := make([]domain.Database, len(ids))
+	for i, id := range ids {
+		dbs[i] = domain.Database{ID: id, DBName: "app", Host: "pg" + strings.Repeat("x", i), User: "replicator", Pass: "s3cret-pass"}
+	}
+	return dbs
+}
+
+// named gives the databases names, keeping their IDs.
+func named(dbs []domain.Database, names ...string) []domain.Database {
+	for i, name := range names {
+		dbs[i].Name = name
+	}
+	return dbs
+}
+
+// refs references databases by ID.
+func refs(ids ...int) []domain.DBRef {
+	refs := make([]domain.DBRef, len(ids))
+	for i, id := range ids {
+		refs[i] = domain.DBRefID(id)
+	}
+	return refs
+}
+
+func boolPtr(b bool) *bool { return &b }
+
+func TestReconcile(t *testing.T) {
+	ordersSync := domain.Sync{Name: "orders", Sources: refs(1), Targets: refs(2), Tables: "public.orders"}
+
+	tests := []struct {
+		name     string
+		previous *domain.BucardoConfig         // Reconciled first, to set up Bucardo's state.
+		prepare  func(b *fake.BucardoExecutor) // Runs before the reconcile under test.
+		config   *domain.BucardoConfig
+		wantErr  string
+		wantDbs  []string
+		wantSync map[string]fake.Sync             // Expected syncs; nil skips the check.
+		wantRels map[string][]string              // Expected relgroups; nil skips the check.
+		ran      []string                         // Commands that must have run.
+		notRan   []string                         // Commands that must not have run.
+		check    func(t *testing.T, env *testEnv) // Further checks.
+	}{
+		{
+			name:    "fresh install",
+			config:  &domain.BucardoConfig{Databases: databases(1, 2), Syncs: []domain.Sync{ordersSync}, LogLevel: "verbose"},
+			wantDbs: []string{"db1", "db2"},
+			wantSync: map[string]fake.Sync{
+				"orders": {Relgroup: "orders", Dbgroup: mustDbgroup(nil, ordersSync), Status: "active", StayAlive: true},
+			},
+			wantRels: map[string][]string{"orders": {"public.orders"}},
+			ran:      []string{"install", "add db db1", "add db db2", "add sync orders", "set log_level=verbose", "start"},
+			notRan:   []string{"update db", "update sync", "del sync"},
+			check: func(t *testing.T, env *testEnv) {
+				if !env.bucardo.Installed || !env.bucardo.Running {
+					t.Errorf("installed=%t running=%t, want both", env.bucardo.Installed, env.bucardo.Running)
+				}
+				if got := env.bucardo.Dbgroups[mustDbgroup(nil, ordersSync)]; !reflect.DeepEqual(got, []string{"db1:source", "db2:target"}) {
+					t.Errorf("dbgroup members = %v", got)
+				}
+				if env.creds.Installed || env.creds.Cleanups != 1 {
+					t.Errorf(".pgpass not cleaned up: installed=%t cleanups=%d", env.creds.Installed, env.creds.Cleanups)
+				}
+				want := []string{domain.EventReconcileStarted, domain.EventReconcileFinished}
+				if got := env.notifier.EventTypes(); !reflect.DeepEqual(got, want) {
+					t.Errorf("events = %v, want %v", got, want)
+				}
+			},
+		},
+		{
+			name: "existing databases are updated",
+			previous: &domain.BucardoConfig{
+				Databases: databases(1, 2),
+				Syncs:     []domain.Sync{ordersSync},
+			},
+			config: &domain.BucardoConfig{
+				Databases: []domain.Database{
+					{ID: 1, DBName: "app", Host: "new-host", User: "replicator", Pass: "s3cret-pass"},
+					databases(1, 2)[1],
+				},
+				Syncs: []domain.Sync{ordersSync},
+			},
+			wantDbs: []string{"db1", "db2"},
+			ran:     []string{"update db db1", "update db db2"},
+			notRan:  []string{"add db"},
+			check: func(t *testing.T, env *testEnv) {
+				if host := env.bucardo.Databases["db1"].Host; host != "new-host" {
+					t.Errorf("db1 host = %q, want new-host", host)
+				}
+			},
+		},
+		{
+			name: "orphaned databases and syncs are removed",
+			previous: &domain.BucardoConfig{
+				Databases: databases(1, 2, 3),
+				Syncs: []domain.Sync{
+					ordersSync,
+					{Name: "legacy", Sources: refs(1), Targets: refs(3), Tables: "public.legacy"},
+				},
+			},
+			config:  &domain.BucardoConfig{Databases: databases(1, 2), Syncs: []domain.Sync{ordersSync}},
+			wantDbs: []string{"db1", "db2"},
+			wantSync: map[string]fake.Sync{
+				"orders": {Relgroup: "orders", Dbgroup: mustDbgroup(nil, ordersSync), Status: "active", StayAlive: true},
+			},
+			wantRels: map[string][]string{"orders": {"public.orders"}},
+			ran:      []string{"del dbs db3", "del sync legacy --force", "del relgroup legacy"},
+			notRan:   []string{"del dbs db1", "del sync orders"},
+		},
+		{
+			name:     "unchanged tables are updated in place",
+			previous: &domain.BucardoConfig{Databases: databases(1, 2), Syncs: []domain.Sync{ordersSync}},
+			config: &domain.BucardoConfig{Databases: databases(1, 2), Syncs: []domain.Sync{
+				{Name: "orders", Sources: refs(1), Targets: refs(2), Tables: "public.orders", StrictChecking: boolPtr(false)},
+			}},
+			wantSync: map[string]fake.Sync{
+				"orders": {Relgroup: "orders", Dbgroup: mustDbgroup(nil, ordersSync), Status: "active", StrictChecking: "false", StayAlive: true},
+			},
+			ran:    []string{"update sync orders strict_checking=false status=active"},
+			notRan: []string{"add sync", "del sync", "del relgroup"},
+		},
+		{
+			name:     "changed tables re-create the sync",
+			previous: &domain.BucardoConfig{Databases: databases(1, 2), Syncs: []domain.Sync{ordersSync}},
+			config: &domain.BucardoConfig{Databases: databases(1, 2), Syncs: []domain.Sync{
+				{Name: "orders", Sources: refs(1), Targets: refs(2), Tables: "public.orders, public.order_lines"},
+			}},
+			wantRels: map[string][]string{"orders": {"public.order_lines", "public.orders"}},
+			ran:      []string{"del sync orders --force", "del relgroup orders", "add sync orders"},
+			notRan:   []string{"update sync"},
+		},
+		{
+			name: "bidirectional sync",
+			config: &domain.BucardoConfig{Databases: databases(1, 2, 3), Syncs: []domain.Sync{
+				{Name: "mesh", Bidirectional: refs(1, 2, 3), Tables: "public.accounts", ConflictStrategy: "bucardo_latest"},
+			}},
+			wantSync: map[string]fake.Sync{
+				"mesh": {Relgroup: "mesh", Dbgroup: "bg_mesh", Status: "active", ConflictStrategy: "bucardo_latest", StayAlive: true},
+			},
+			check: func(t *testing.T, env *testEnv) {
+				want := []string{"db1:source", "db2:source", "db3:source"}
+				if got := env.bucardo.Dbgroups["bg_mesh"]; !reflect.DeepEqual(got, want) {
+					t.Errorf("dbgroup members = %v, want %v", got, want)
+				}
+			},
+		},
+		{
+			name: "herd sync takes every table of the first source",
+			prepare: func(b *fake.BucardoExecutor) {
+				b.SourceTables["db1"] = []string{"public.customers", "public.orders", "sales.invoices"}
+				b.SourceTables["db2"] = []string{"public.ignored"}
+			},
+			config: &domain.BucardoConfig{Databases: databases(1, 2), Syncs: []domain.Sync{
+				{Name: "everything", Sources: refs(1), Targets: refs(2), Herd: "all_tables", Onetimecopy: 2},
+			}},
+			wantSync: map[string]fake.Sync{
+				"everything": {Relgroup: "all_tables", Dbgroup: mustDbgroup(nil, domain.Sync{Name: "everything", Sources: refs(1), Targets: refs(2)}), Onetimecopy: 2, Status: "active", StayAlive: true},
+			},
+			wantRels: map[string][]string{"all_tables": {"public.customers", "public.orders", "sales.invoices"}},
+			ran:      []string{"add herd all_tables", "add all tables --herd=all_tables db=db1"},
+		},
+		{
+			name: "paused and run-once syncs",
+			config: &domain.BucardoConfig{Databases: databases(1, 2), Syncs: []domain.Sync{
+				{Name: "orders", Sources: refs(1), Targets: refs(2), Tables: "public.orders", Status: domain.SyncStatusInactive},
+				{Name: "backfill", Sources: refs(1), Targets: refs(2), Tables: "public.history", ExitOnComplete: boolPtr(true)},
+			}},
+			check: func(t *testing.T, env *testEnv) {
+				if s := env.bucardo.Syncs["orders"]; s.Status != domain.SyncStatusInactive {
+					t.Errorf("orders status = %q, want inactive", s.Status)
+				}
+				if s := env.bucardo.Syncs["backfill"]; s.StayAlive {
+					t.Errorf("backfill is stayalive, want it to exit on completion")
+				}
+			},
+		},
+		{
+			name: "databases referenced by name",
+			config: &domain.BucardoConfig{
+				Databases: []domain.Database{
+					{Name: "orders_primary", DBName: "app", Host: "pg", User: "replicator", Pass: "s3cret-pass"},
+					{Name: "orders_replica", DBName: "app", Host: "pgx", User: "replicator", Pass: "s3cret-pass"},
+				},
+				Syncs: []domain.Sync{
+					{Name: "orders", Sources: []domain.DBRef{"orders_primary"}, Targets: []domain.DBRef{"orders_replica"}, Tables: "public.orders"},
+					{Name: "everything", Sources: []domain.DBRef{"orders_primary"}, Targets: []domain.DBRef{"orders_replica"}, Herd: "all_tables"},
+				},
+			},
+			wantDbs: []string{"orders_primary", "orders_replica"},
+			ran:     []string{"add db orders_primary", "add sync orders", "add all tables --herd=all_tables db=orders_primary"},
+			notRan:  []string{"psql UPDATE bucardo.db"},
+			check: func(t *testing.T, env *testEnv) {
+				group := env.bucardo.Syncs["orders"].Dbgroup
+				if got := env.bucardo.Dbgroups[group]; !reflect.DeepEqual(got, []string{"orders_primary:source", "orders_replica:target"}) {
+					t.Errorf("dbgroup members = %v", got)
+				}
+			},
+		},
+		{
+			name:     "naming databases renames them in Bucardo and keeps their syncs",
+			previous: &domain.BucardoConfig{Databases: databases(1, 2), Syncs: []domain.Sync{ordersSync}},
+			config: &domain.BucardoConfig{
+				Databases: named(databases(1, 2), "orders_primary", "orders_replica"),
+				Syncs: []domain.Sync{
+					{Name: "orders", Sources: []domain.DBRef{"orders_primary"}, Targets: refs(2), Tables: "public.orders"},
+				},
+			},
+			wantDbs: []string{"orders_primary", "orders_replica"},
+			wantSync: map[string]fake.Sync{
+				"orders": {Relgroup: "orders", Dbgroup: mustDbgroup(nil, ordersSync), Status: "active", StayAlive: true},
+			},
+			ran:    []string{"psql UPDATE bucardo.db db1 orders_primary", "psql UPDATE bucardo.db db2 orders_replica", "update db orders_primary", "update sync orders"},
+			notRan: []string{"del dbs", "add db", "del sync", "add sync", "del dbgroup"},
+			check: func(t *testing.T, env *testEnv) {
+				if got := env.bucardo.Dbgroups[mustDbgroup(nil, ordersSync)]; !reflect.DeepEqual(got, []string{"orders_primary:source", "orders_replica:target"}) {
+					t.Errorf("dbgroup members = %v", got)
+				}
+				// The next reconcile has nothing left to rename.
+				env.bucardo.ResetCommands()
+				if err := env.service.ReloadAndRestart(context.Background()); err != nil {
+					t.Fatal(err)
+				}
+				if env.bucardo.Ran("psql UPDATE bucardo.db") || env.bucardo.Ran("add sync") {
+					t.Errorf("second reconcile changed Bucardo: %q", env.bucardo.Commands)
+				}
+			},
+		},
+		{
+			name:     "a failed rename stops the reconcile before orphans are removed",
+			previous: &domain.BucardoConfig{Databases: databases(1, 2), Syncs: []domain.Sync{ordersSync}},
+			prepare: func(b *fake.BucardoExecutor) {
+				b.FailOn("psql UPDATE bucardo.db", errBucardo("permission denied for table db"))
+			},
+			config:  &domain.BucardoConfig{Databases: named(databases(1, 2), "orders_primary"), Syncs: []domain.Sync{ordersSync}},
+			wantErr: "failed to rename database db1 to orders_primary: permission denied",
+			wantDbs: []string{"db1", "db2"},
+			notRan:  []string{"del dbs", "del sync", "start"},
+		},
+		{
+			name:     "a failing command fails the reconcile",
+			prepare:  func(b *fake.BucardoExecutor) { b.FailOn("add sync orders", errBucardo("DBD::Pg::st execute failed")) },
+			config:   &domain.BucardoConfig{Databases: databases(1, 2), Syncs: []domain.Sync{ordersSync}},
+			wantErr:  "failed to add sync orders: DBD::Pg::st execute failed",
+			wantSync: map[string]fake.Sync{},
+			notRan:   []string{"start"},
+			check: func(t *testing.T, env *testEnv) {
+				want := []string{domain.EventReconcileStarted, domain.EventReconcileFailed}
+				if got := env.notifier.EventTypes(); !reflect.DeepEqual(got, want) {
+					t.Errorf("events = %v, want %v", got, want)
+				}
+			},
+		},
+		{
+			name: "an unset environment password fails before Bucardo is changed",
+			config: &domain.BucardoConfig{Databases: []domain.Database{
+				{ID: 7, DBName: "app", Host: "pg", User: "replicator", Pass: "env"},
+			}},
+			wantErr: "BUCARDO_DB7 not set",
+			notRan:  []string{"install", "add db"},
+		},
+		{
+			name: "an unset environment password of a named database",
+			config: &domain.BucardoConfig{Databases: []domain.Database{
+				{Name: "orders_primary", DBName: "app", Host: "pg", User: "replicator", Pass: "env"},
+			}},
+			wantErr: "BUCARDO_DB_ORDERS_PRIMARY_PASS not set",
+			notRan:  []string{"install", "add db"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("BUCARDO_DB7", "")
+			t.Setenv("BUCARDO_DB_ORDERS_PRIMARY_PASS", "")
+			env := newTestEnv(t, tt.previous)
+			ctx := context.Background()
+			if tt.previous != nil {
+				if err := env.service.ReloadAndRestart(ctx); err != nil {
+					t.Fatalf("reconciling the previous configuration: %v", err)
+				}
+				env.bucardo.ResetCommands()
+				env.notifier = fake.NewNotifier()
+				env.service.notifier = env.notifier
+			}
+			env.config.Set(tt.config)
+			if tt.prepare != nil {
+				tt.prepare(env.bucardo)
+			}
+
+			err := env.service.ReloadAndRestart(ctx)
+			switch {
+			case tt.wantErr == "" && err != nil:
+				t.Fatalf("ReloadAndRestart() error = %v", err)
+			case tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)):
+				t.Fatalf("ReloadAndRestart() error = %v, want %q", err, tt.wantErr)
+			}
+
+			if tt.wantDbs != nil {
+				dbs, _ := env.bucardo.ListDatabases(ctx)
+				if !reflect.DeepEqual(dbs, tt.wantDbs) {
+					t.Errorf("databases = %v, want %v", dbs, tt.wantDbs)
+				}
+			}
+			if tt.wantSync != nil {
+				got := make(map[string]fake.Sync, len(env.bucardo.Syncs))
+				for name, s := range env.bucardo.Syncs {
+					got[name] = *s
+				}
+				if !reflect.DeepEqual(got, tt.wantSync) {
+					t.Errorf("syncs = %+v, want %+v", got, tt.wantSync)
+				}
+			}
+			if tt.wantRels != nil && !reflect.DeepEqual(env.bucardo.Relgroups, tt.wantRels) {
+				t.Errorf("relgroups = %v, want %v", env.bucardo.Relgroups, tt.wantRels)
+			}
+			for _, command := range tt.ran {
+				if !env.bucardo.Ran(command) {
+					t.Errorf("command %q did not run; commands: %q", command, env.bucardo.Commands)
+				}
+			}
+			for _, command := range tt.notRan {
+				if env.bucardo.Ran(command) {
+					t.Errorf("command %q ran, want it not to", command)
+				}
+			}
+			if tt.check != nil {
+				tt.check(t, env)
+			}
+		})
+	}
+}
+
+func TestReconcileRejectsInvalidConfig(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  *domain.BucardoConfig
+		wantErr string
+	}{
+		{
+			name:    "duplicate database ID",
+			config:  &domain.BucardoConfig{Databases: append(databases(1, 2), databases(2)...)},
+			wantErr: "database ID 2 is duplicated",
+		},
+		{
+			name: "duplicate sync name",
+			config: &domain.BucardoConfig{Databases: databases(1, 2), Syncs: []domain.Sync{
+				{Name: "orders", Sources: refs(1), Targets: refs(2), Tables: "public.orders"},
+				{Name: "orders", Sources: refs(2), Targets: refs(1), Tables: "public.orders"},
+			}},
+			wantErr: "sync name 'orders' is duplicated",
+		},
+		{
+			name: "sync without a target",
+			config: &domain.BucardoConfig{Databases: databases(1), Syncs: []domain.Sync{
+				{Name: "orders", Sources: refs(1), Tables: "public.orders"},
+			}},
+			wantErr: "sync 'orders': must have at least one target",
+		},
+		{
+			name: "sync without tables or herd",
+			config: &domain.BucardoConfig{Databases: databases(1, 2), Syncs: []domain.Sync{
+				{Name: "orders", Sources: refs(1), Targets: refs(2)},
+			}},
+			wantErr: "sync 'orders': must define either 'herd' or 'tables'",
+		},
+		{
+			name: "bidirectional sync with an unknown database",
+			config: &domain.BucardoConfig{Databases: databases(1), Syncs: []domain.Sync{
+				{Name: "mesh", Bidirectional: refs(1, 9), Tables: "public.accounts"},
+			}},
+			wantErr: "'bidirectional' database ID 9 is not defined",
+		},
+		{
+			name: "bidirectional sync with a one-way conflict strategy",
+			config: &domain.BucardoConfig{Databases: databases(1, 2), Syncs: []domain.Sync{
+				{Name: "mesh", Bidirectional: refs(1, 2), Tables: "public.accounts", ConflictStrategy: "bucardo_source"},
+			}},
+			wantErr: "invalid conflict_strategy 'bucardo_source' for a bidirectional sync",
+		},
+		{
+			name: "unknown sync status",
+			config: &domain.BucardoConfig{Databases: databases(1, 2), Syncs: []domain.Sync{
+				{Name: "orders", Sources: refs(1), Targets: refs(2), Tables: "public.orders", Status: "paused"},
+			}},
+			wantErr: "invalid status 'paused'",
+		},
+		{
+			name: "invalid database name",
+			config: &domain.BucardoConfig{Databases: []domain.Database{
+				{Name: "Orders-DB", DBName: "app", Host: "pg", User: "replicator", Pass: "s3cret-pass"},
+			}},
+			wantErr: "database name 'Orders-DB' is invalid",
+		},
+		{
+			name:    "database name of the form db<ID>",
+			config:  &domain.BucardoConfig{Databases: named(databases(1, 2), "db2")},
+			wantErr: "database name 'db2' is reserved",
+		},
+		{
+			name:    "duplicate database name",
+			config:  &domain.BucardoConfig{Databases: named(databases(1, 2), "orders", "orders")},
+			wantErr: "database name 'orders' is duplicated",
+		},
+		{
+			name: "sync with an unknown source",
+			config: &domain.BucardoConfig{Databases: databases(1), Syncs: []domain.Sync{
+				{Name: "orders", Sources: refs(4), Targets: refs(1), Tables: "public.orders"},
+			}},
+			wantErr: "sync 'orders': source database ID 4 is not defined",
+		},
+		{
+			name: "sync with an unknown database name",
+			config: &domain.BucardoConfig{Databases: named(databases(1, 2), "orders_primary"), Syncs: []domain.Sync{
+				{Name: "orders", Sources: []domain.DBRef{"orders_primary"}, Targets: []domain.DBRef{"orders_replica"}, Tables: "public.orders"},
+			}},
+			wantErr: "sync 'orders': target database 'orders_replica' is not defined",
+		},
+		{
+			name:    "invalid service log level",
+			config:  &domain.BucardoConfig{ServiceLog: &domain.ServiceLogConfig{Level: "loud"}},
+			wantErr: "service_log: invalid level 'loud'",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env := newTestEnv(t, tt.config)
+
+			if err := env.service.ReloadAndRestart(context.Background()); err == nil {
+				t.Fatal("ReloadAndRestart() succeeded, want a validation error")
+			}
+			if len(env.bucardo.Commands) != 0 {
+				t.Errorf("Bucardo was changed by an invalid configuration: %q", env.bucardo.Commands)
+			}
+			// The same errors reject the configuration through the API, before it is saved.
+			err := env.service.UpdateConfig(context.Background(), tt.config)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("UpdateConfig() error = %v, want %q", err, tt.wantErr)
+			}
+			if revision, _ := env.config.LatestRevision(context.Background()); revision != 0 {
+				t.Errorf("invalid configuration was saved as revision %d", revision)
+			}
+		})
+	}
+}
+
+func TestUpdateSyncKeepsStatus(t *testing.T) {
+	paused := domain.Sync{Name: "orders", Sources: refs(1), Targets: refs(2), Tables: "public.orders", Status: domain.SyncStatusInactive}
+	tests := []struct {
+		name       string
+		status     string
+		wantStatus string
+	}{
+		{name: "omitted status keeps the sync paused", wantStatus: domain.SyncStatusInactive},
+		{name: "explicit status is applied", status: domain.SyncStatusActive, wantStatus: domain.SyncStatusActive},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env := newTestEnv(t, &domain.BucardoConfig{Databases: databases(1, 2), Syncs: []domain.Sync{paused}})
+			ctx := context.Background()
+			updated := domain.Sync{Name: "orders", Sources: refs(1), Targets: refs(2), Tables: "public.orders", Onetimecopy: 2, Status: tt.status}
+			if err := env.service.UpdateSync(ctx, "orders", updated); err != nil {
+				t.Fatal(err)
+			}
+			sync, err := env.service.GetSync(ctx, "orders")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if sync.Status != tt.wantStatus || sync.Onetimecopy != 2 {
+				t.Errorf("sync = %+v, want status %q and the update applied", sync, tt.wantStatus)
+			}
+
+			// The next reconcile keeps Bucardo's sync in the same state.
+			if err := env.service.ReloadAndRestart(ctx); err != nil {
+				t.Fatal(err)
+			}
+			if got := env.bucardo.Syncs["orders"].Status; got != tt.wantStatus {
+				t.Errorf("Bucardo status = %q, want %q", got, tt.wantStatus)
+			}
+		})
+	}
+}
+
+func TestSyncStatusSeverity(t *testing.T) {
+	env := newTestEnv(t, &domain.BucardoConfig{
+		Databases: databases(1, 2),
+		Syncs:     []domain.Sync{{Name: "orders", Sources: refs(1), Targets: refs(2), Tables: "public.orders"}},
+	})
+	ctx := context.Background()
+	if err := env.service.ReloadAndRestart(ctx); err != nil {
+		t.Fatal(err)
+	}
+	env.notifier = fake.NewNotifier()
+	env.service.notifier = env.notifier
+
+	if err := env.service.PauseSync(ctx, "orders"); err != nil {
+		t.Fatal(err)
+	}
+	if err := env.service.ResumeSync(ctx, "orders"); err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, event := range env.notifier.Events() {
+		if event.Type == domain.EventSyncStatusChanged {
+			got = append(got, event.Details["status"].(string)+" "+event.Severity)
+		}
+	}
+	want := []string{"inactive " + domain.SeverityWarning, "active " + domain.SeverityInfo}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("status events = %q, want %q", got, want)
+	}
+}
+
+// mustDbgroup returns the dbgroup name of a sync; a nil config stands for databases
+// referenced by ID.
+func mustDbgroup(config *domain.BucardoConfig, sync domain.Sync) string {
+	if config == nil {
+		config = &domain.BucardoConfig{}
+	}
+	name, _ := syncDbgroup(config, sync)
+	return name
+}
+
+type errBucardo string
+
+func (e errBucardo) Error() string { return string(e) }
@@ -0,0 +1,286 @@
+package orchestrator
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"replication-service/internal/core/domain"
+)
+
+// syncEdge is the replication of a one-way sync from one of its sources to one of its
+// targets, which are identified by their Bucardo names.
+type syncEdge struct {
+	sync     string
+	from, to string
+	tables   map[string]bool // nil for a herd sync, which copies every table of its source.
+}
+
+// tableWriter is a sync that writes tables on a database.
+type tableWriter struct {
+	sync   string
+	tables map[string]bool // nil for a herd sync.
+}
+
+// validateTopology checks the replication graph formed by the syncs. errs make the
+// configuration invalid:
+//   - a database that is both a source and a target of a one-way sync,
+//   - a table written on the same database by more than one sync,
+//   - one-way syncs that replicate a table in a cycle; only bidirectional syncs handle
+//     conflicts.
+//
+// warnings cover what cannot be decided from the configuration alone, because herd syncs
+// copy whatever tables their source has, and databases that no sync uses.
+func validateTopology(config *domain.BucardoConfig) (errs, warnings []error) {
+	// Databases are identified by their Bucardo names, whether syncs reference them by
+	// name or by ID.
+	labels := make(map[string]string)
+	for _, db := range config.Databases {
+		labels[db.BucardoName()] = describeRef(db.Ref())
+	}
+	referenced := make(map[string]bool)
+	writers := make(map[string][]tableWriter)
+	var edges []syncEdge
+
+	for _, sync := range config.Syncs {
+		if sync.Name == "" {
+			continue
+		}
+		tables := syncTableSet(sync)
+		if len(sync.Bidirectional) > 0 {
+			for _, db := range resolveRefs(config, sync.Bidirectional) {
+				referenced[db] = true
+				writers[db] = append(writers[db], tableWriter{sync: sync.Name, tables: tables})
+			}
+			continue
+		}
+
+		// Undefined sources, targets and members are reported by validateConfig.
+		sources, targets := resolveRefs(config, sync.Sources), resolveRefs(config, sync.Targets)
+		isSource := make(map[string]bool)
+		for _, db := range sources {
+			referenced[db] = true
+			isSource[db] = true
+		}
+		for _, db := range targets {
+			referenced[db] = true
+			if isSource[db] {
+				errs = append(errs, fmt.Errorf("sync '%s': %s is both a source and a target", sync.Name, labels[db]))
+				continue
+			}
+			writers[db] = append(writers[db], tableWriter{sync: sync.Name, tables: tables})
+			for _, source := range sources {
+				edges = append(edges, syncEdge{sync: sync.Name, from: source, to: db, tables: tables})
+			}
+		}
+	}
+
+	e, w := checkOverlappingWriters(writers, labels)
+	errs, warnings = append(errs, e...), append(warnings, w...)
+	e, w = checkCycles(edges)
+	errs, warnings = append(errs, e...), append(warnings, w...)
+
+	for _, db := range config.Databases {
+		if !referenced[db.BucardoName()] {
+			warnings = append(warnings, fmt.Errorf("%s is not used by any sync", describeRef(db.Ref())))
+		}
+	}
+	return errs, warnings
+}
+
+// checkOverlappingWriters reports tables that more than one sync writes on the same database.
+func checkOverlappingWriters(writers map[string][]tableWriter, labels map[string]string) (errs, warnings []error) {
+	for _, db := range sortedKeys(writers) {
+		dbWriters := writers[db]
+		for i := 0; i < len(dbWriters); i++ {
+			for j := i + 1; j < len(dbWriters); j++ {
+				a, b := dbWriters[i], dbWriters[j]
+				if a.tables == nil || b.tables == nil {
+					warnings = append(warnings, fmt.Errorf("syncs '%s' and '%s' may write the same tables on %s: a herd sync copies every table of its source", a.sync, b.sync, labels[db]))
+					continue
+				}
+				var shared []string
+				for table := range a.tables {
+					if b.tables[table] {
+						shared = append(shared, table)
+					}
+				}
+				if len(shared) > 0 {
+					sort.Strings(shared)
+					errs = append(errs, fmt.Errorf("syncs '%s' and '%s' both write %s on %s", a.sync, b.sync, strings.Join(shared, ", "), labels[db]))
+				}
+			}
+		}
+	}
+	return errs, warnings
+}
+
+// checkCycles reports one-way syncs that replicate a table back to where it came from.
+// Each table is checked on its own, with herd syncs taking part for every table.
+func checkCycles(edges []syncEdge) (errs, warnings []error) {
+	tableSet := make(map[string]bool)
+	for _, e := range edges {
+		for table := range e.tables {
+			tableSet[table] = true
+		}
+	}
+	tables := make([]string, 0, len(tableSet)+1)
+	for table := range tableSet {
+		tables = append(tables, table)
+	}
+	sort.Strings(tables)
+	tables = append(tables, "") // Herd syncs among themselves.
+
+	reported := make(map[string]bool)
+	for _, table := range tables {
+		var graph []syncEdge
+		for _, e := range edges {
+			if e.tables == nil || (table != "" && e.tables[table]) {
+				graph = append(graph, e)
+			}
+		}
+		cycle := findCycle(graph)
+		if cycle == nil {
+			continue
+		}
+
+		path := []string{cycle[0].from}
+		var syncs []string
+		seen := make(map[string]bool)
+		throughHerd := false
+		for _, e := range cycle {
+			path = append(path, e.to)
+			if !seen[e.sync] {
+				seen[e.sync] = true
+				syncs = append(syncs, fmt.Sprintf("'%s'", e.sync))
+			}
+			throughHerd = throughHerd || e.tables == nil
+		}
+		key := strings.Join(path, ">")
+		if reported[key] {
+			continue
+		}
+		reported[key] = true
+
+		if throughHerd {
+			warnings = append(warnings, fmt.Errorf("syncs %s may replicate tables in a cycle %s: a herd sync copies every table of its source. Use a bidirectional sync if the databases should converge", strings.Join(syncs, ", "), strings.Join(path, " -> ")))
+			continue
+		}
+		errs = append(errs, fmt.Errorf("syncs %s replicate %s in a cycle %s. Use a bidirectional sync with a conflict_strategy instead", strings.Join(syncs, ", "), table, strings.Join(path, " -> ")))
+	}
+	return errs, warnings
+}
+
+// findCycle returns the edges of a cycle in the graph, or nil. Databases and edges are
+// visited in a fixed order, so the same configuration always reports the same cycle.
+func findCycle(edges []syncEdge) []syncEdge {
+	out := make(map[string][]syncEdge)
+	for _, e := range edges {
+		out[e.from] = append(out[e.from], e)
+	}
+	for _, from := range out {
+		sort.SliceStable(from, func(i, j int) bool { return from[i].to < from[j].to })
+	}
+
+	const (
+		unvisited = iota
+		visiting
+		done
+	)
+	state := make(map[string]int)
+	var stack []syncEdge
+	var visit func(db string) []syncEdge
+	visit = func(db string) []syncEdge {
+		state[db] = visiting
+		for _, e := range out[db] {
+			switch state[e.to] {
+			case visiting:
+				// The cycle starts where the stack first left e.to.
+				start := len(stack)
+				for i := len(stack) - 1; i >= 0; i-- {
+					if stack[i].from == e.to {
+						start = i
+						break
+					}
+				}
+				return append(append([]syncEdge{}, stack[start:]...), e)
+			case unvisited:
+				stack = append(stack, e)
+				if cycle := visit(e.to); cycle != nil {
+					return cycle
+				}
+				stack = stack[:len(stack)-1]
+			}
+		}
+		state[db] = done
+		return nil
+	}
+	for _, db := range sortedKeys(out) {
+		if state[db] == unvisited {
+			if cycle := visit(db); cycle != nil {
+				return cycle
+			}
+		}
+	}
+	return nil
+}
+
+// syncTableSet returns the tables of a sync, qualified with "public." when they have no
+// schema as Bucardo does, or nil for a sync without a table list.
+func syncTableSet(sync domain.Sync) map[string]bool {
+	if sync.Tables == "" {
+		return nil
+	}
+	tables := make(map[string]bool)
+	for _, table := range strings.Split(sync.Tables, ",") {
+		table = strings.TrimSpace(table)
+		if table == "" {
+			continue
+		}
+		if !strings.Contains(table, ".") {
+			table = "public." + table
+		}
+		tables[table] = true
+	}
+	return tables
+}
+
+// resolveRefs returns the Bucardo names of the defined databases among refs, without
+// duplicates.
+func resolveRefs(config *domain.BucardoConfig, refs []domain.DBRef) []string {
+	seen := make(map[string]bool, len(refs))
+	names := make([]string, 0, len(refs))
+	for _, ref := range refs {
+		db, ok := config.FindDatabase(ref)
+		if !ok || seen[db.BucardoName()] {
+			continue
+		}
+		seen[db.BucardoName()] = true
+		names = append(names, db.BucardoName())
+	}
+	return names
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// logConfigWarnings logs the topology problems that do not prevent a configuration from
+// being applied: its warnings, and its errors when it was saved before they were checked.
+// Saving it again through the API is rejected until the errors are fixed.
+func (s *Service) logConfigWarnings(ctx context.Context, config *domain.BucardoConfig) {
+	errs, warnings := validateTopology(config)
+	logger := s.logger.WithContext(ctx)
+	for _, e := range errs {
+		logger.Warn("Configuration error, changes are rejected until it is fixed: "+e.Error(), "component", "config")
+	}
+	for _, w := range warnings {
+		logger.Warn("Configuration warning: "+w.Error(), "component", "config")
+	}
+}
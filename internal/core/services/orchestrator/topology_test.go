@@ -0,0 +1,214 @@
+package orchestrator
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"replication-service/internal/adapters/logger"
+	"replication-service/internal/core/domain"
+)
+
+func TestValidateTopology(t *testing.T) {
+	oneWay := func(name string, sources, targets []int, tables string) domain.Sync {
+		return domain.Sync{Name: name, Sources: refs(sources...), Targets: refs(targets...), Tables: tables}
+	}
+	herd := func(name string, source, target int) domain.Sync {
+		return domain.Sync{Name: name, Sources: refs(source), Targets: refs(target), Herd: name}
+	}
+
+	tests := []struct {
+		name         string
+		dbs          []int
+		syncs        []domain.Sync
+		wantErrs     []string
+		wantWarnings []string
+	}{
+		{
+			name: "fan-out and fan-in of different tables",
+			dbs:  []int{1, 2, 3},
+			syncs: []domain.Sync{
+				oneWay("orders", []int{1}, []int{2, 3}, "public.orders"),
+				oneWay("stock", []int{3}, []int{1}, "stock"),
+			},
+		},
+		{
+			name: "two-way replication of different tables is not a cycle",
+			dbs:  []int{1, 2},
+			syncs: []domain.Sync{
+				oneWay("forward", []int{1}, []int{2}, "public.orders"),
+				oneWay("back", []int{2}, []int{1}, "public.refunds"),
+			},
+		},
+		{
+			name: "one-way syncs form a cycle for a table",
+			dbs:  []int{1, 2},
+			syncs: []domain.Sync{
+				oneWay("forward", []int{1}, []int{2}, "public.orders, public.customers"),
+				oneWay("back", []int{2}, []int{1}, "orders"),
+			},
+			wantErrs: []string{"syncs 'forward', 'back' replicate public.orders in a cycle db1 -> db2 -> db1"},
+		},
+		{
+			name: "longer cycle",
+			dbs:  []int{1, 2, 3},
+			syncs: []domain.Sync{
+				oneWay("a", []int{1}, []int{2}, "public.t"),
+				oneWay("b", []int{2}, []int{3}, "public.t"),
+				oneWay("c", []int{3}, []int{1}, "public.t"),
+			},
+			wantErrs: []string{"syncs 'a', 'b', 'c' replicate public.t in a cycle db1 -> db2 -> db3 -> db1"},
+		},
+		{
+			name: "cycle through a herd sync",
+			dbs:  []int{1, 2},
+			syncs: []domain.Sync{
+				herd("everything", 1, 2),
+				oneWay("back", []int{2}, []int{1}, "public.refunds"),
+			},
+			wantWarnings: []string{"syncs 'everything', 'back' may replicate tables in a cycle db1 -> db2 -> db1"},
+		},
+		{
+			name: "bidirectional syncs are not cycles",
+			dbs:  []int{1, 2, 3},
+			syncs: []domain.Sync{
+				{Name: "mesh", Bidirectional: refs(1, 2), Tables: "public.accounts", ConflictStrategy: "bucardo_latest"},
+				oneWay("copy", []int{2}, []int{3}, "public.accounts"),
+			},
+		},
+		{
+			name: "table written twice on a target",
+			dbs:  []int{1, 2, 3},
+			syncs: []domain.Sync{
+				oneWay("from_a", []int{1}, []int{3}, "public.orders, public.items"),
+				oneWay("from_b", []int{2}, []int{3}, "public.items,public.orders"),
+			},
+			wantErrs: []string{"syncs 'from_a' and 'from_b' both write public.items, public.orders on database ID 3"},
+		},
+		{
+			name: "one-way sync writes into a bidirectional group",
+			dbs:  []int{1, 2, 3},
+			syncs: []domain.Sync{
+				{Name: "mesh", Bidirectional: refs(1, 2), Tables: "public.accounts"},
+				oneWay("import", []int{3}, []int{2}, "public.accounts"),
+			},
+			wantErrs: []string{"syncs 'mesh' and 'import' both write public.accounts on database ID 2"},
+		},
+		{
+			name: "herd sync shares a target",
+			dbs:  []int{1, 2, 3},
+			syncs: []domain.Sync{
+				herd("everything", 1, 3),
+				oneWay("orders", []int{2}, []int{3}, "public.orders"),
+			},
+			wantWarnings: []string{"syncs 'everything' and 'orders' may write the same tables on database ID 3"},
+		},
+		{
+			name:     "source is also a target",
+			dbs:      []int{1, 2},
+			syncs:    []domain.Sync{oneWay("orders", []int{1}, []int{2, 1}, "public.orders")},
+			wantErrs: []string{"sync 'orders': database ID 1 is both a source and a target"},
+		},
+		{
+			name:         "unused database",
+			dbs:          []int{1, 2, 9},
+			syncs:        []domain.Sync{oneWay("orders", []int{1}, []int{2}, "public.orders")},
+			wantWarnings: []string{"database ID 9 is not used by any sync"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := &domain.BucardoConfig{Syncs: tt.syncs}
+			for _, id := range tt.dbs {
+				config.Databases = append(config.Databases, domain.Database{ID: id})
+			}
+			errs, warnings := validateTopology(config)
+			checkMessages(t, "errors", errs, tt.wantErrs)
+			checkMessages(t, "warnings", warnings, tt.wantWarnings)
+		})
+	}
+}
+
+func TestValidateTopologyWithNamedDatabases(t *testing.T) {
+	config := &domain.BucardoConfig{
+		Databases: []domain.Database{
+			{ID: 1, Name: "primary"},
+			{Name: "replica"},
+			{Name: "spare"},
+		},
+		Syncs: []domain.Sync{
+			{Name: "forward", Sources: refs(1), Targets: []domain.DBRef{"replica"}, Tables: "public.orders"},
+			{Name: "back", Sources: []domain.DBRef{"replica"}, Targets: []domain.DBRef{"primary"}, Tables: "public.orders"},
+			{Name: "self", Sources: refs(1), Targets: []domain.DBRef{"primary"}, Tables: "public.items"},
+		},
+	}
+	errs, warnings := validateTopology(config)
+	checkMessages(t, "errors", errs, []string{
+		"sync 'self': database 'primary' is both a source and a target",
+		"syncs 'forward', 'back' replicate public.orders in a cycle primary -> replica -> primary",
+	})
+	checkMessages(t, "warnings", warnings, []string{"database 'spare' is not used by any sync"})
+}
+
+func TestTopologyErrorsRejectOnlyChanges(t *testing.T) {
+	config := &domain.BucardoConfig{
+		Databases: databases(1, 2),
+		Syncs: []domain.Sync{
+			{Name: "forward", Sources: refs(1), Targets: refs(2), Tables: "public.orders"},
+			{Name: "back", Sources: refs(2), Targets: refs(1), Tables: "public.orders"},
+		},
+	}
+	env := newTestEnv(t, config)
+	var logs bytes.Buffer
+	env.service.logger = logger.NewSlogAdapter(slog.New(slog.NewTextHandler(&logs, nil)))
+
+	err := env.service.UpdateConfig(context.Background(), config)
+	if !errors.Is(err, ErrInvalidConfig) || !strings.Contains(err.Error(), "in a cycle db1 -> db2 -> db1") {
+		t.Errorf("UpdateConfig() error = %v, want the cycle", err)
+	}
+	// A configuration saved before the topology was checked still starts.
+	if err := env.service.ReloadAndRestart(context.Background()); err != nil {
+		t.Fatalf("ReloadAndRestart() error = %v", err)
+	}
+	if want := "changes are rejected until it is fixed: syncs 'forward', 'back' replicate public.orders in a cycle"; !strings.Contains(logs.String(), want) {
+		t.Errorf("reconcile logged:\n%s\nwant a warning containing %q", logs.String(), want)
+	}
+}
+
+// checkMessages checks that each error contains the corresponding wanted text, in order.
+func checkMessages(t *testing.T, kind string, got []error, want []string) {
+	t.Helper()
+	if len(got) != len(want) {
+		t.Errorf("%s = %v, want %d matching %q", kind, got, len(want), want)
+		return
+	}
+	for i, err := range got {
+		if !strings.Contains(err.Error(), want[i]) {
+			t.Errorf("%s[%d] = %q, want it to contain %q", kind, i, err, want[i])
+		}
+	}
+}
+
+func TestFindCycleIsDeterministic(t *testing.T) {
+	edges := []syncEdge{
+		{sync: "c", from: "db3", to: "db1"},
+		{sync: "b", from: "db2", to: "db3"},
+		{sync: "a", from: "db1", to: "db2"},
+		{sync: "d", from: "db2", to: "db4"},
+	}
+	for i := 0; i < 20; i++ {
+		cycle := findCycle(edges)
+		got := ""
+		for _, e := range cycle {
+			got += fmt.Sprintf("%s>%s ", e.from, e.to)
+		}
+		if got != "db1>db2 db2>db3 db3>db1 " {
+			t.Fatalf("findCycle() = %q, want db1>db2 db2>db3 db3>db1", got)
+		}
+	}
+}
@@ -0,0 +1,54 @@
+package orchestrator
+
+import (
+	"context"
+	"sync"
+
+	"replication-service/internal/core/ports"
+)
+
+// phaseSpans traces the sequential phases of an operation as child spans of its span.
+type phaseSpans struct {
+	mu      sync.Mutex
+	parent  context.Context
+	current ports.Span
+}
+
+type phaseSpansKey struct{}
+
+// startPhases starts the span of an operation made of sequential phases, which nextPhase
+// traces as its children. end finishes the current phase and the operation, marking both
+// failed when err is not nil.
+func (s *Service) startPhases(ctx context.Context, name string, attrs ...any) (_ context.Context, end func(err error)) {
+	ctx, span := s.tracer.Start(ctx, name, attrs...)
+	phases := &phaseSpans{}
+	ctx = context.WithValue(ctx, phaseSpansKey{}, phases)
+	phases.parent = ctx
+	return ctx, func(err error) {
+		phases.mu.Lock()
+		if phases.current != nil {
+			phases.current.End(err)
+			phases.current = nil
+		}
+		phases.mu.Unlock()
+		span.End(err)
+	}
+}
+
+// nextPhase ends the previous phase of the operation started with startPhases
+// successfully, begins a new phase span and a job step of the same name, and returns the
+// context to run the phase in.
+func (s *Service) nextPhase(ctx context.Context, name string) context.Context {
+	s.nextStep(ctx, name)
+	phases, ok := ctx.Value(phaseSpansKey{}).(*phaseSpans)
+	if !ok {
+		return ctx
+	}
+	phases.mu.Lock()
+	defer phases.mu.Unlock()
+	if phases.current != nil {
+		phases.current.End(nil)
+	}
+	ctx, phases.current = s.tracer.Start(phases.parent, name)
+	return ctx
+}
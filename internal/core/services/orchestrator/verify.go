@@ -0,0 +1,410 @@
+package orchestrator
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"replication-service/internal/core/domain"
+)
+
+// ErrVerifyRunNotFound is returned when a validation run ID is unknown.
+var ErrVerifyRunNotFound = errors.New("verification run not found")
+
+const (
+	defaultVerifyParallelism = 4
+	defaultVerifyChunkSize   = 10000
+	maxVerifyRunsKept        = 50
+)
+
+// verifyTracker keeps recent validation runs in memory.
+type verifyTracker struct {
+	mu    sync.Mutex
+	runs  map[string]*domain.VerifyRun
+	order []string
+}
+
+func newVerifyTracker() *verifyTracker {
+	return &verifyTracker{runs: make(map[string]*domain.VerifyRun)}
+}
+
+func (t *verifyTracker) add(run *domain.VerifyRun) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.runs[run.ID] = run
+	t.order = append(t.order, run.ID)
+	if len(t.order) > maxVerifyRunsKept {
+		delete(t.runs, t.order[0])
+		t.order = t.order[1:]
+	}
+}
+
+func (t *verifyTracker) update(id string, fn func(*domain.VerifyRun)) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if run, ok := t.runs[id]; ok {
+		fn(run)
+	}
+}
+
+// get returns a copy of a run that callers can read without holding the lock.
+func (t *verifyTracker) get(id string) (domain.VerifyRun, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	run, ok := t.runs[id]
+	if !ok {
+		return domain.VerifyRun{}, false
+	}
+	snapshot := *run
+	snapshot.Tables = append([]domain.TableVerification(nil), run.Tables...)
+	return snapshot, true
+}
+
+// StartVerification compares row counts and per key range checksums of every table in a
+// sync between its source and each target. The comparison runs in a background job; the
+// returned run can be polled with GetVerification.
+func (s *Service) StartVerification(ctx context.Context, name string, req domain.VerifyRequest) (*domain.VerifyRun, error) {
+	config, err := s.config.LoadConfig(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var sync *domain.Sync
+	for i := range config.Syncs {
+		if config.Syncs[i].Name == name {
+			sync = &config.Syncs[i]
+			break
+		}
+	}
+	if sync == nil {
+		return nil, fmt.Errorf("%w: %s", ErrSyncNotFound, name)
+	}
+
+	source, targets, err := s.verificationConns(config, *sync)
+	if err != nil {
+		return nil, err
+	}
+	tables, err := s.syncTableList(ctx, *sync)
+	if err != nil {
+		return nil, err
+	}
+
+	run := &domain.VerifyRun{
+		ID:          newRunID(),
+		SyncName:    name,
+		State:       domain.VerifyStateRunning,
+		Source:      source.name,
+		Parallelism: req.Parallelism,
+		ChunkSize:   req.ChunkSize,
+		StartedAt:   time.Now(),
+		Tables:      []domain.TableVerification{},
+	}
+	if run.Parallelism <= 0 {
+		run.Parallelism = defaultVerifyParallelism
+	}
+	if run.ChunkSize <= 0 {
+		run.ChunkSize = defaultVerifyChunkSize
+	}
+	for _, target := range targets {
+		run.Targets = append(run.Targets, target.name)
+	}
+	s.jobs.mu.Lock()
+	j := s.jobs.createLocked(domain.JobTypeVerify, name)
+	s.jobs.mu.Unlock()
+	run.JobID = j.info.ID
+	s.verifications.add(run)
+
+	abort := func(err error) any {
+		s.verifications.update(run.ID, func(run *domain.VerifyRun) {
+			now := time.Now()
+			run.FinishedAt = &now
+			run.State = domain.VerifyStateFailed
+			run.Error = fmt.Sprintf("cancelled before it started: %v", err)
+		})
+		result, _ := s.verifications.get(run.ID)
+		return result
+	}
+	s.runJob(ctx, j, s.acquireSyncJob, abort, func(ctx context.Context) (any, error) {
+		err := s.runVerification(ctx, run.ID, name, source, targets, tables, run.Parallelism, run.ChunkSize)
+		result, _ := s.verifications.get(run.ID)
+		return result, err
+	})
+
+	snapshot, _ := s.verifications.get(run.ID)
+	return &snapshot, nil
+}
+
+// GetVerification returns a validation run of a sync.
+func (s *Service) GetVerification(_ context.Context, name, runID string) (*domain.VerifyRun, error) {
+	run, ok := s.verifications.get(runID)
+	if !ok || run.SyncName != name {
+		return nil, fmt.Errorf("%w: %s", ErrVerifyRunNotFound, runID)
+	}
+	return &run, nil
+}
+
+// namedConn pairs a resolved connection with the Bucardo name of the database.
+type namedConn struct {
+	name string
+	conn domain.ConnInfo
+}
+
+// verificationConns resolves the reference database and the databases compared against it.
+// One-way syncs use their first source as the reference; bidirectional syncs use their
+// first member and compare it with every other member.
+func (s *Service) verificationConns(config *domain.BucardoConfig, sync domain.Sync) (namedConn, []namedConn, error) {
+	var sourceRef domain.DBRef
+	var targetRefs []domain.DBRef
+	if len(sync.Bidirectional) > 0 {
+		sourceRef, targetRefs = sync.Bidirectional[0], sync.Bidirectional[1:]
+	} else {
+		if len(sync.Sources) == 0 || len(sync.Targets) == 0 {
+			return namedConn{}, nil, fmt.Errorf("sync %s has no source or target to compare", sync.Name)
+		}
+		sourceRef, targetRefs = sync.Sources[0], sync.Targets
+	}
+
+	source, err := s.databaseConn(config, sourceRef)
+	if err != nil {
+		return namedConn{}, nil, err
+	}
+	targets := make([]namedConn, 0, len(targetRefs))
+	for _, ref := range targetRefs {
+		target, err := s.databaseConn(config, ref)
+		if err != nil {
+			return namedConn{}, nil, err
+		}
+		targets = append(targets, target)
+	}
+	return source, targets, nil
+}
+
+// databaseConn resolves the connection settings and password of a configured database.
+func (s *Service) databaseConn(config *domain.BucardoConfig, ref domain.DBRef) (namedConn, error) {
+	db, ok := config.FindDatabase(ref)
+	if !ok {
+		return namedConn{}, fmt.Errorf("%s is not defined in the 'databases' list", describeRef(ref))
+	}
+	password, err := s.getDbPassword(db)
+	if err != nil {
+		return namedConn{}, fmt.Errorf("error getting password for db %s: %w", db.BucardoName(), err)
+	}
+	port := 5432
+	if db.Port != nil {
+		port = *db.Port
+	}
+	return namedConn{
+		name: db.BucardoName(),
+		conn: domain.ConnInfo{Host: db.Host, Port: port, DBName: db.DBName, User: db.User, Password: password},
+	}, nil
+}
+
+// syncTableList returns the tables replicated by a sync. Herd syncs are resolved through Bucardo.
+func (s *Service) syncTableList(ctx context.Context, sync domain.Sync) ([]string, error) {
+	if sync.Herd != "" {
+		tables, err := s.bucardo.GetSyncTables(ctx, sync.Herd)
+		if err != nil {
+			return nil, fmt.Errorf("could not list tables of herd %s: %w", sync.Herd, err)
+		}
+		return tables, nil
+	}
+	var tables []string
+	for _, t := range strings.Split(sync.Tables, ",") {
+		if t = strings.TrimSpace(t); t != "" {
+			tables = append(tables, t)
+		}
+	}
+	if len(tables) == 0 {
+		return nil, fmt.Errorf("sync %s has no tables", sync.Name)
+	}
+	return tables, nil
+}
+
+// runVerification compares every table and reports an error when a table could not be
+// verified. Differences in data are part of the run's result, not an error.
+func (s *Service) runVerification(ctx context.Context, runID, syncName string, source namedConn, targets []namedConn, tables []string, parallelism, chunkSize int) error {
+	verifyLogger := s.logger.With("component", "verifier", "sync_name", syncName, "run_id", runID)
+	verifyLogger.Info("Starting data verification", "tables", len(tables), "targets", len(targets), "parallelism", parallelism)
+
+	sem := make(chan struct{}, parallelism)
+	var wg sync.WaitGroup
+	for _, table := range tables {
+		wg.Add(1)
+		go func(table string) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			step := s.beginStep(ctx, "Verify "+table)
+			results := s.verifyTable(ctx, table, source, targets, chunkSize)
+			var stepErr error
+			for _, r := range results {
+				if r.Error != "" {
+					stepErr = errors.New(r.Error)
+				}
+			}
+			s.endStep(ctx, step, stepErr)
+			s.verifications.update(runID, func(run *domain.VerifyRun) {
+				for _, r := range results {
+					run.Tables = append(run.Tables, r)
+					run.Mismatches += len(r.Mismatches)
+					if !r.RowCountMatch && r.Error == "" {
+						run.Mismatches++
+					}
+				}
+			})
+		}(table)
+	}
+	wg.Wait()
+
+	var mismatches int
+	var runErr error
+	s.verifications.update(runID, func(run *domain.VerifyRun) {
+		now := time.Now()
+		run.FinishedAt = &now
+		run.State = domain.VerifyStateCompleted
+		for _, t := range run.Tables {
+			if t.Error != "" {
+				run.State = domain.VerifyStateFailed
+				run.Error = "one or more tables could not be verified"
+				runErr = errors.New(run.Error)
+			}
+		}
+		mismatches = run.Mismatches
+	})
+
+	if runErr != nil {
+		verifyLogger.Warn("Data verification incomplete", "mismatches", mismatches, "error", runErr)
+		return runErr
+	}
+	if mismatches > 0 {
+		verifyLogger.Warn("Data verification found differences", "mismatches", mismatches)
+		return nil
+	}
+	verifyLogger.Info("Data verification finished", "mismatches", mismatches)
+	return nil
+}
+
+// verifyTable compares one table between the source and each target. Checksums cover the
+// columns that exist on the source and on every target, as syncs without strict checking
+// allow the column order, and extra columns, to differ.
+func (s *Service) verifyTable(ctx context.Context, table string, source namedConn, targets []namedConn, chunkSize int) []domain.TableVerification {
+	results := make([]domain.TableVerification, len(targets))
+	for i, target := range targets {
+		results[i] = domain.TableVerification{Table: table, Target: target.name}
+	}
+	fail := func(err error) []domain.TableVerification {
+		for i := range results {
+			if results[i].Error == "" {
+				results[i].Error = err.Error()
+			}
+		}
+		return results
+	}
+
+	sourceTable, err := s.inspector.DescribeTable(ctx, source.conn, table)
+	if err != nil {
+		return fail(err)
+	}
+	shared := make(map[string]bool)
+	for _, column := range sourceTable.Columns {
+		shared[column] = true
+	}
+	targetTables := make([]*domain.TableInfo, len(targets))
+	for i, target := range targets {
+		targetTable, err := s.inspector.DescribeTable(ctx, target.conn, table)
+		if err != nil {
+			results[i].Error = err.Error()
+			continue
+		}
+		onTarget := make(map[string]bool)
+		for _, column := range targetTable.Columns {
+			onTarget[column] = true
+		}
+		for column := range shared {
+			if !onTarget[column] {
+				delete(shared, column)
+			}
+		}
+		targetTables[i] = targetTable
+	}
+	var columns []string
+	for _, column := range sourceTable.Columns {
+		if shared[column] {
+			columns = append(columns, column)
+		}
+	}
+	if len(columns) == 0 {
+		return fail(fmt.Errorf("table %s has no columns in common between %s and its targets", table, source.name))
+	}
+	sourceTable.Columns = columns
+
+	sourceRows, err := s.inspector.CountRows(ctx, source.conn, sourceTable)
+	if err != nil {
+		return fail(err)
+	}
+	ranges, err := s.inspector.KeyRanges(ctx, source.conn, sourceTable, chunkSize)
+	if err != nil {
+		return fail(err)
+	}
+
+	type rangeSum struct {
+		count int64
+		sum   string
+	}
+	sourceSums := make([]rangeSum, len(ranges))
+	for i, keyRange := range ranges {
+		count, sum, err := s.inspector.RangeChecksum(ctx, source.conn, sourceTable, keyRange)
+		if err != nil {
+			return fail(err)
+		}
+		sourceSums[i] = rangeSum{count: count, sum: sum}
+	}
+
+	for i, target := range targets {
+		result := &results[i]
+		result.SourceRows = sourceRows
+		targetTable := targetTables[i]
+		if targetTable == nil {
+			continue
+		}
+		// Compare using the source's key definition and columns so both sides are chunked
+		// and hashed identically.
+		targetTable.PrimaryKey = sourceTable.PrimaryKey
+		targetTable.Columns = columns
+
+		if result.TargetRows, err = s.inspector.CountRows(ctx, target.conn, targetTable); err != nil {
+			result.Error = err.Error()
+			continue
+		}
+		result.RowCountMatch = result.SourceRows == result.TargetRows
+
+		for r, keyRange := range ranges {
+			count, sum, err := s.inspector.RangeChecksum(ctx, target.conn, targetTable, keyRange)
+			if err != nil {
+				result.Error = err.Error()
+				break
+			}
+			result.ChunksChecked++
+			if count != sourceSums[r].count || sum != sourceSums[r].sum {
+				result.Mismatches = append(result.Mismatches, domain.KeyRangeMismatch{
+					Range:      keyRange,
+					SourceRows: sourceSums[r].count,
+					TargetRows: count,
+				})
+			}
+		}
+	}
+	return results
+}
+
+// newRunID returns a short random identifier for background runs.
+func newRunID() string {
+	b := make([]byte, 8)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
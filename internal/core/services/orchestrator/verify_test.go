@@ -0,0 +1,173 @@
+package orchestrator
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"testing"
+
+	"replication-service/internal/adapters/fake"
+	"replication-service/internal/core/domain"
+)
+
+// ordersTable has the rows with IDs 1 to rows, in table column order columns.
+func ordersTable(rows int, columns ...string) *fake.Table {
+	if len(columns) == 0 {
+		columns = []string{"id", "customer", "total"}
+	}
+	t := &fake.Table{PrimaryKey: []string{"id"}, Columns: columns}
+	for id := 1; id <= rows; id++ {
+		row := make(map[string]string)
+		for _, column := range columns {
+			row[column] = fmt.Sprintf("%s-%d", column, id)
+		}
+		row["id"] = fmt.Sprint(id)
+		t.Rows = append(t.Rows, row)
+	}
+	return t
+}
+
+// withRows applies fn to the rows of t and returns t.
+func withRows(t *fake.Table, fn func(rows []map[string]string) []map[string]string) *fake.Table {
+	t.Rows = fn(t.Rows)
+	return t
+}
+
+func TestVerifyTable(t *testing.T) {
+	// With 25 rows in chunks of 10, the key ranges are [, 11), [11, 21) and [21, ).
+	first := domain.KeyRange{Upper: []string{"11"}}
+	middle := domain.KeyRange{Lower: []string{"11"}, Upper: []string{"21"}}
+	last := domain.KeyRange{Lower: []string{"21"}}
+
+	tests := []struct {
+		name   string
+		target *fake.Table // nil when the target does not have the table.
+		want   domain.TableVerification
+	}{
+		{
+			name:   "identical",
+			target: ordersTable(25),
+			want:   domain.TableVerification{TargetRows: 25, RowCountMatch: true, ChunksChecked: 3},
+		},
+		{
+			name:   "different column order and an extra column",
+			target: ordersTable(25, "total", "note", "id", "customer"),
+			want:   domain.TableVerification{TargetRows: 25, RowCountMatch: true, ChunksChecked: 3},
+		},
+		{
+			name: "changed row",
+			target: withRows(ordersTable(25), func(rows []map[string]string) []map[string]string {
+				rows[14]["total"] = "changed"
+				return rows
+			}),
+			want: domain.TableVerification{TargetRows: 25, RowCountMatch: true, ChunksChecked: 3, Mismatches: []domain.KeyRangeMismatch{
+				{Range: middle, SourceRows: 10, TargetRows: 10},
+			}},
+		},
+		{
+			name: "missing rows",
+			target: withRows(ordersTable(25), func(rows []map[string]string) []map[string]string {
+				return append(rows[1:21], rows[22:]...)
+			}),
+			want: domain.TableVerification{TargetRows: 23, ChunksChecked: 3, Mismatches: []domain.KeyRangeMismatch{
+				{Range: first, SourceRows: 10, TargetRows: 9},
+				{Range: last, SourceRows: 5, TargetRows: 4},
+			}},
+		},
+		{
+			name: "rows beyond the source's last key",
+			target: withRows(ordersTable(25), func(rows []map[string]string) []map[string]string {
+				return append(rows, ordersTable(100).Rows[99])
+			}),
+			want: domain.TableVerification{TargetRows: 26, ChunksChecked: 3, Mismatches: []domain.KeyRangeMismatch{
+				{Range: last, SourceRows: 5, TargetRows: 6},
+			}},
+		},
+		{
+			name: "missing table",
+			want: domain.TableVerification{Error: `relation "public.orders" does not exist on pg2`},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env := newTestEnv(t, nil)
+			env.inspector.AddTable("pg1", "public.orders", ordersTable(25))
+			env.inspector.AddTable("pg3", "public.orders", ordersTable(25))
+			if tt.target != nil {
+				env.inspector.AddTable("pg2", "public.orders", tt.target)
+			}
+			source := namedConn{name: "db1", conn: domain.ConnInfo{Host: "pg1"}}
+			targets := []namedConn{
+				{name: "db2", conn: domain.ConnInfo{Host: "pg2"}},
+				{name: "db3", conn: domain.ConnInfo{Host: "pg3"}},
+			}
+
+			got := env.service.verifyTable(context.Background(), "public.orders", source, targets, 10)
+			want := tt.want
+			want.Table, want.Target, want.SourceRows = "public.orders", "db2", 25
+			if !reflect.DeepEqual(got[0], want) {
+				t.Errorf("db2 = %+v, want %+v", got[0], want)
+			}
+			// The other target is compared on its own.
+			identical := domain.TableVerification{Table: "public.orders", Target: "db3", SourceRows: 25, TargetRows: 25, RowCountMatch: true, ChunksChecked: 3}
+			if !reflect.DeepEqual(got[1], identical) {
+				t.Errorf("db3 = %+v, want %+v", got[1], identical)
+			}
+		})
+	}
+}
+
+func TestVerifyTableWithoutSharedColumns(t *testing.T) {
+	env := newTestEnv(t, nil)
+	env.inspector.AddTable("pg1", "public.orders", ordersTable(5, "id"))
+	env.inspector.AddTable("pg2", "public.orders", ordersTable(5, "order_id"))
+	source := namedConn{name: "db1", conn: domain.ConnInfo{Host: "pg1"}}
+	targets := []namedConn{{name: "db2", conn: domain.ConnInfo{Host: "pg2"}}}
+
+	got := env.service.verifyTable(context.Background(), "public.orders", source, targets, 10)
+	if want := "table public.orders has no columns in common between db1 and its targets"; got[0].Error != want {
+		t.Errorf("error = %q, want %q", got[0].Error, want)
+	}
+}
+
+func TestVerification(t *testing.T) {
+	dbs := databases(1, 2, 3) // On hosts pg, pgx and pgxx.
+	env := newTestEnv(t, &domain.BucardoConfig{
+		Databases: dbs,
+		Syncs:     []domain.Sync{{Name: "orders", Sources: refs(1), Targets: refs(2, 3), Tables: "public.orders, public.items"}},
+	})
+	env.inspector.AddTable("pg", "public.orders", ordersTable(25))
+	env.inspector.AddTable("pg", "public.items", ordersTable(5))
+	// db2 differs in two key ranges of orders and has one row less.
+	env.inspector.AddTable("pgx", "public.orders", withRows(ordersTable(25), func(rows []map[string]string) []map[string]string {
+		rows[0]["total"] = "changed"
+		return rows[:24]
+	}))
+	env.inspector.AddTable("pgx", "public.items", ordersTable(5))
+	// db3 matches, but has no items table.
+	env.inspector.AddTable("pgxx", "public.orders", ordersTable(25))
+
+	run, err := env.service.StartVerification(context.Background(), "orders", domain.VerifyRequest{ChunkSize: 10})
+	if err != nil {
+		t.Fatal(err)
+	}
+	waitForJob(t, env, run.JobID)
+	got, err := env.service.GetVerification(context.Background(), "orders", run.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Two mismatched ranges and the row count; the table db3 lacks is an error, not a mismatch.
+	if got.Mismatches != 3 {
+		t.Errorf("mismatches = %d, want 3", got.Mismatches)
+	}
+	if got.State != domain.VerifyStateFailed || got.Error != "one or more tables could not be verified" {
+		t.Errorf("state = %q (%q), want failed", got.State, got.Error)
+	}
+	if len(got.Tables) != 4 {
+		t.Errorf("tables = %+v, want each table for each target", got.Tables)
+	}
+	if !reflect.DeepEqual(got.Targets, []string{"db2", "db3"}) || got.Source != "db1" || got.ChunkSize != 10 || got.Parallelism != defaultVerifyParallelism {
+		t.Errorf("run = %+v", got)
+	}
+}